@@ -1,22 +1,34 @@
 package codec
 
 import (
-	"errors"
 	"io"
 )
 
 func init() {
-	registerCodec("ASCII", NewASCIIDecoder, NewASCIIEncoder)
+	registerCodec(Codec{
+		Name: "ASCII",
+		Aliases: []string{
+			// IANA
+			"US-ASCII", "iso-ir-6", "ANSI_X3.4-1968", "ANSI_X3.4-1986",
+			"ISO_646.irv:1991", "ISO646-US", "us", "IBM367", "cp367", "csASCII",
+			// Windows code page
+			"cp20127",
+		},
+		NewDecoder: NewASCIIDecoder,
+		NewEncoder: NewASCIIEncoder,
+		Info:       Info{MaxRune: 0x7f, MinBytes: 1, MaxBytes: 1},
+	})
 }
 
-var _ Decoder = &ASCIIDecoder{}
+var _ BulkDecoder = &ASCIIDecoder{}
 
 // ASCIIDecoder implements Decoder for ASCII.
 type ASCIIDecoder struct {
 }
 
-// NewASCIIDecoder creates a new instance of ASCIIDecoder
-func NewASCIIDecoder() Decoder {
+// NewASCIIDecoder creates a new instance of ASCIIDecoder. ASCII has no byte
+// order mark, so opts are ignored.
+func NewASCIIDecoder(opts ...Options) Decoder {
 	return &ASCIIDecoder{}
 }
 
@@ -27,24 +39,51 @@ func (d *ASCIIDecoder) Decode(r io.Reader) (rune, error) {
 	if err != nil {
 		return 0, err
 	}
+
+	if buf[0] > 127 {
+		return 0, &DecodeError{Encoding: "ASCII", Bytes: buf, Err: ErrInvalidSequence}
+	}
 	return rune(buf[0]), nil
 }
 
-var _ Encoder = &ASCIIEncoder{}
+// BulkDecoder satisfies the BulkDecoder interface for ASCII.
+func (d *ASCIIDecoder) BulkDecoder() BulkDecoder {
+	return d
+}
+
+// DecodeBytes satisfies the BulkDecoder interface for ASCII.
+func (d *ASCIIDecoder) DecodeBytes(dst []rune, src []byte, atEOF bool) (nDst, nSrc int, err error) {
+	for ; nSrc < len(src); nSrc++ {
+		if nDst == len(dst) {
+			return nDst, nSrc, ErrShortDst
+		}
+
+		b := src[nSrc]
+		if b > 127 {
+			return nDst, nSrc, &DecodeError{Encoding: "ASCII", Bytes: []byte{b}, Err: ErrInvalidSequence}
+		}
+		dst[nDst] = rune(b)
+		nDst++
+	}
+	return nDst, nSrc, nil
+}
+
+var _ BulkEncoder = &ASCIIEncoder{}
 
 // ASCIIEncoder implements Encoder for ASCII.
 type ASCIIEncoder struct {
 }
 
-// NewASCIIEncoder creates a new instance of ASCIIEncoder
-func NewASCIIEncoder() Encoder {
+// NewASCIIEncoder creates a new instance of ASCIIEncoder. ASCII has no byte
+// order mark, so opts are ignored.
+func NewASCIIEncoder(opts ...Options) Encoder {
 	return &ASCIIEncoder{}
 }
 
 // Encode satifies the Decoder interface for ASCII.
 func (*ASCIIEncoder) Encode(w io.Writer, r rune) error {
-	if r > 127 {
-		return errors.New("character out of range")
+	if r < 0 || r > 127 {
+		return &EncodeError{Encoding: "ASCII", Rune: r, Err: ErrOutOfRange}
 	}
 
 	buf := make([]byte, 1)
@@ -52,3 +91,24 @@ func (*ASCIIEncoder) Encode(w io.Writer, r rune) error {
 	_, err := w.Write(buf)
 	return err
 }
+
+// BulkEncoder satisfies the BulkEncoder interface for ASCII.
+func (e *ASCIIEncoder) BulkEncoder() BulkEncoder {
+	return e
+}
+
+// EncodeRunes satisfies the BulkEncoder interface for ASCII.
+func (*ASCIIEncoder) EncodeRunes(dst []byte, src []rune) (nDst, nSrc int, err error) {
+	for ; nSrc < len(src); nSrc++ {
+		r := src[nSrc]
+		if r < 0 || r > 127 {
+			return nDst, nSrc, &EncodeError{Encoding: "ASCII", Rune: r, Err: ErrOutOfRange}
+		}
+		if nDst == len(dst) {
+			return nDst, nSrc, ErrShortDst
+		}
+		dst[nDst] = byte(r)
+		nDst++
+	}
+	return nDst, nSrc, nil
+}
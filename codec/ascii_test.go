@@ -2,6 +2,7 @@ package codec
 
 import (
 	"bytes"
+	"errors"
 	"testing"
 )
 
@@ -32,6 +33,20 @@ func TestASCIIDecode(t *testing.T) {
 	}
 }
 
+func TestASCIIDecodeInvalid(t *testing.T) {
+	var de *DecodeError
+	_, err := GetDecoder("ASCII").Decode(bytes.NewReader([]byte{0x80}))
+	if !errors.As(err, &de) || de.Err != ErrInvalidSequence || !bytes.Equal(de.Bytes, []byte{0x80}) {
+		t.Errorf("got %v, want an invalid sequence DecodeError", err)
+	}
+
+	actual := &bytes.Buffer{}
+	err = Recode(bytes.NewReader([]byte{'a', 0xff}), actual, GetDecoder("ASCII"), GetEncoder("UTF-8"))
+	if !errors.As(err, &de) || de.Err != ErrInvalidSequence || de.Position.Offset != 1 {
+		t.Errorf("got %v, want an invalid sequence DecodeError at offset 1", err)
+	}
+}
+
 func TestASCIIEncoder(t *testing.T) {
 	cases := []struct {
 		r        rune
@@ -0,0 +1,261 @@
+package codec
+
+import "io"
+
+//go:generate go run gen_dbcs.go -o big5_tables.go -overrides mappings/OVERRIDES.TXT big5=mappings/OBSOLETE/EASTASIA/OTHER/BIG5.TXT big5HKSCS=mappings/VENDORS/MISC/BIG5-HKSCS.TXT
+
+// Big5 encodes ASCII in single bytes and traditional Chinese characters in two
+// bytes, with a lead byte from 0xA1-0xF9 and a trail byte from 0x40-0x7E or
+// 0xA1-0xFE.
+//
+// Big5-HKSCS adds the Hong Kong Supplementary Character Set, mostly with lead
+// bytes from 0x87-0xA0, 0xC6-0xC8 and 0xFA-0xFE. Many of its characters
+// are outside the Basic Multilingual Plane, and four codes decode to a letter
+// followed by a combining mark. The encoder holds back Ê and ê until it sees
+// the next character, so it can write those codes for the same sequences.
+//
+// A few characters have two codes. Both encoders use the higher one, which is
+// the character's place among the other hanzi or, for the box drawing
+// characters, the ETEN extensions.
+func init() {
+	registerDBCS(&dbcs{name: "Big5", table: &big5, lastWins: true}, []string{
+		// IANA
+		"csBig5",
+		// WHATWG
+		"cn-big5", "x-x-big5",
+	})
+
+	registerCodec(Codec{
+		Name: "Big5-HKSCS",
+		Aliases: []string{
+			// IANA
+			"csBig5HKSCS",
+		},
+		NewDecoder: func(...Options) Decoder {
+			return &Big5HKSCSDecoder{}
+		},
+		NewEncoder: func(...Options) Encoder {
+			return &Big5HKSCSEncoder{}
+		},
+		Info: big5HKSCSSet.info(),
+	})
+}
+
+var big5HKSCSSet = &dbcs{name: "Big5-HKSCS", table: &big5HKSCS, lastWins: true}
+
+// hkscsSequences are the Big5-HKSCS codes that decode to more than one
+// character.
+var hkscsSequences = []struct {
+	code         uint16
+	letter, mark rune
+}{
+	{0x8862, 0xca, 0x304},
+	{0x8864, 0xca, 0x30c},
+	{0x88a3, 0xea, 0x304},
+	{0x88a5, 0xea, 0x30c},
+}
+
+// hkscsCompose returns the code for letter followed by mark, if there is one.
+func hkscsCompose(letter, mark rune) (uint16, bool) {
+	for _, seq := range hkscsSequences {
+		if seq.letter == letter && seq.mark == mark {
+			return seq.code, true
+		}
+	}
+	return 0, false
+}
+
+// decodeHKSCS works like a decodeFunc, except that it also returns the
+// combining mark for the codes that decode to a sequence, or 0.
+func decodeHKSCS(src []byte, atEOF bool) (char, mark rune, n int, err error) {
+	if len(src) >= 2 {
+		code := uint16(src[0])<<8 | uint16(src[1])
+		for _, seq := range hkscsSequences {
+			if seq.code == code {
+				return seq.letter, seq.mark, 2, nil
+			}
+		}
+	}
+
+	char, n, err = big5HKSCSSet.decode(src, atEOF)
+	return char, 0, n, err
+}
+
+var (
+	_ BulkDecoder     = &Big5HKSCSDecoder{}
+	_ SequenceDecoder = &Big5HKSCSDecoder{}
+)
+
+// Big5HKSCSDecoder reads Big5-HKSCS. Use GetDecoder to get one.
+type Big5HKSCSDecoder struct {
+	in input
+
+	// mark is the combining mark of a sequence whose letter Decode has
+	// already returned, or 0.
+	mark rune
+}
+
+// Decode satisfies the Decoder interface for Big5-HKSCS.
+func (d *Big5HKSCSDecoder) Decode(r io.Reader) (rune, error) {
+	if d.mark != 0 {
+		mark := d.mark
+		d.mark = 0
+		return mark, nil
+	}
+
+	seq, err := d.DecodeSequence(r)
+	if err != nil {
+		return 0, err
+	}
+	if len(seq) > 1 {
+		d.mark = seq[1]
+	}
+	return seq[0], nil
+}
+
+// DecodeSequence satisfies the SequenceDecoder interface for Big5-HKSCS.
+func (d *Big5HKSCSDecoder) DecodeSequence(r io.Reader) ([]rune, error) {
+	if d.mark != 0 {
+		mark := d.mark
+		d.mark = 0
+		return []rune{mark}, nil
+	}
+
+	var mark rune
+	char, err := d.in.decodeRune(r, "Big5-HKSCS", func(src []byte, atEOF bool) (rune, int, error) {
+		char, m, n, err := decodeHKSCS(src, atEOF)
+		mark = m
+		return char, n, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if mark != 0 {
+		return []rune{char, mark}, nil
+	}
+	return []rune{char}, nil
+}
+
+// BulkDecoder satisfies the BulkDecoder interface for Big5-HKSCS.
+func (d *Big5HKSCSDecoder) BulkDecoder() BulkDecoder {
+	return d
+}
+
+// DecodeBytes satisfies the BulkDecoder interface for Big5-HKSCS.
+func (d *Big5HKSCSDecoder) DecodeBytes(dst []rune, src []byte, atEOF bool) (nDst, nSrc int, err error) {
+	for nSrc < len(src) {
+		if nDst == len(dst) {
+			return nDst, nSrc, ErrShortDst
+		}
+
+		char, mark, n, err := decodeHKSCS(src[nSrc:], atEOF)
+		if err == ErrShortSrc {
+			return nDst, nSrc, err
+		}
+		if err != nil {
+			return nDst, nSrc, &DecodeError{Encoding: "Big5-HKSCS", Bytes: copyBytes(src[nSrc : nSrc+n]), Err: err}
+		}
+
+		if mark != 0 {
+			if len(dst)-nDst < 2 {
+				return nDst, nSrc, ErrShortDst
+			}
+			dst[nDst] = char
+			nDst++
+			char = mark
+		}
+		dst[nDst] = char
+		nDst++
+		nSrc += n
+	}
+	return nDst, nSrc, nil
+}
+
+// Buffered returns the number of bytes that have been read but not decoded.
+func (d *Big5HKSCSDecoder) Buffered() int {
+	return d.in.Buffered()
+}
+
+var (
+	_ BulkEncoder = &Big5HKSCSEncoder{}
+	_ Flusher     = &Big5HKSCSEncoder{}
+)
+
+// Big5HKSCSEncoder writes Big5-HKSCS. Use GetEncoder to get one.
+type Big5HKSCSEncoder struct {
+	// letter is Ê or ê if it's being held back to see whether a
+	// combining mark follows, or 0.
+	letter rune
+}
+
+// Encode satisfies the Encoder interface for Big5-HKSCS.
+func (e *Big5HKSCSEncoder) Encode(w io.Writer, r rune) error {
+	buf := make([]byte, 4)
+	n, err := e.encode(buf, r)
+	if n > 0 {
+		if _, werr := w.Write(buf[:n]); werr != nil {
+			return werr
+		}
+	}
+	return err
+}
+
+// BulkEncoder satisfies the BulkEncoder interface for Big5-HKSCS.
+func (e *Big5HKSCSEncoder) BulkEncoder() BulkEncoder {
+	return e
+}
+
+// EncodeRunes satisfies the BulkEncoder interface for Big5-HKSCS.
+func (e *Big5HKSCSEncoder) EncodeRunes(dst []byte, src []rune) (nDst, nSrc int, err error) {
+	for ; nSrc < len(src); nSrc++ {
+		if len(dst)-nDst < 4 {
+			return nDst, nSrc, ErrShortDst
+		}
+		n, err := e.encode(dst[nDst:], src[nSrc])
+		nDst += n
+		if err != nil {
+			return nDst, nSrc, err
+		}
+	}
+	return nDst, nSrc, nil
+}
+
+// Flush writes a letter that was held back.
+func (e *Big5HKSCSEncoder) Flush(w io.Writer) error {
+	if e.letter == 0 {
+		return nil
+	}
+
+	buf := make([]byte, 2)
+	code, _ := big5HKSCSSet.lookup(e.letter)
+	e.letter = 0
+	_, err := w.Write(buf[:putDBCS(buf, code)])
+	return err
+}
+
+// encode writes r, after any letter that was held back, to buf, which must
+// have room for 4 bytes. It returns the number of bytes written, which may be
+// more than 0 even if r can't be encoded.
+func (e *Big5HKSCSEncoder) encode(buf []byte, r rune) (int, error) {
+	n := 0
+	if e.letter != 0 {
+		letter := e.letter
+		e.letter = 0
+		if code, ok := hkscsCompose(letter, r); ok {
+			return putDBCS(buf, code), nil
+		}
+		code, _ := big5HKSCSSet.lookup(letter)
+		n = putDBCS(buf, code)
+	}
+
+	if r == 0xca || r == 0xea {
+		e.letter = r
+		return n, nil
+	}
+
+	code, ok := big5HKSCSSet.lookup(r)
+	if !ok {
+		return n, &EncodeError{Encoding: "Big5-HKSCS", Rune: r, Err: ErrOutOfRange}
+	}
+	return n + putDBCS(buf[n:], code), nil
+}
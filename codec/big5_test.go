@@ -0,0 +1,149 @@
+package codec
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestBig5(t *testing.T) {
+	cases := []struct {
+		encoding string
+		text     string
+		encoded  []byte
+	}{
+		{
+			encoding: "Big5",
+			text:     "中文 ok",
+			encoded:  []byte{0xa4, 0xa4, 0xa4, 0xe5, 0x20, 0x6f, 0x6b},
+		},
+		{
+			// Characters with two codes use the higher
+			encoding: "Big5",
+			text:     "十／",
+			encoded:  []byte{0xa4, 0x51, 0xa2, 0x41},
+		},
+		{
+			encoding: "Big5-HKSCS",
+			text:     "中文 ok",
+			encoded:  []byte{0xa4, 0xa4, 0xa4, 0xe5, 0x20, 0x6f, 0x6b},
+		},
+		{
+			// Outside the Basic Multilingual Plane
+			encoding: "Big5-HKSCS",
+			text:     "\U00027267𧘇",
+			encoded:  []byte{0x87, 0x45, 0xc8, 0xa4},
+		},
+		{
+			encoding: "Big5-HKSCS",
+			text:     "╭碁",
+			encoded:  []byte{0xf9, 0xfa, 0xf9, 0xd6},
+		},
+		{
+			encoding: "Big5-HKSCS",
+			text:     "Ê̄ê̌",
+			encoded:  []byte{0x88, 0x62, 0x88, 0xa5},
+		},
+		{
+			// The letters on their own
+			encoding: "Big5-HKSCS",
+			text:     "ÊaÊ",
+			encoded:  []byte{0x88, 0x66, 0x61, 0x88, 0x66},
+		},
+		{
+			encoding: "Big5-HKSCS",
+			text:     "ÊÊ̄",
+			encoded:  []byte{0x88, 0x66, 0x88, 0x62},
+		},
+	}
+
+	for _, c := range cases {
+		actual := &bytes.Buffer{}
+		err := Recode(bytes.NewReader(c.encoded), actual, GetDecoder(c.encoding), NewUTF8Encoder())
+		if err != nil {
+			t.Errorf("%s: decode error: %v", c.encoding, err)
+		} else if actual.String() != c.text {
+			t.Errorf("%s: got %q, want %q", c.encoding, actual.String(), c.text)
+		}
+
+		actual.Reset()
+		err = Recode(strings.NewReader(c.text), actual, NewUTF8Decoder(), GetEncoder(c.encoding))
+		if err != nil {
+			t.Errorf("%s %q: encode error: %v", c.encoding, c.text, err)
+		} else if !bytes.Equal(actual.Bytes(), c.encoded) {
+			t.Errorf("%s %q: got % x, want % x", c.encoding, c.text, actual.Bytes(), c.encoded)
+		}
+	}
+
+	// The HKSCS characters aren't in Big5.
+	_, err := GetDecoder("Big5").Decode(bytes.NewReader([]byte{0x87, 0x45}))
+	if !errors.Is(err, ErrInvalidSequence) {
+		t.Errorf("Big5: 87 45: got %v, want %v", err, ErrInvalidSequence)
+	}
+}
+
+func TestBig5HKSCSSequence(t *testing.T) {
+	in := []byte{0x88, 0x62, 'a'}
+
+	d := GetDecoder("Big5-HKSCS")
+	r := bytes.NewReader(in)
+	for _, expected := range "Ê̄a" {
+		char, err := d.Decode(r)
+		if err != nil || char != expected {
+			t.Errorf("Decode: got %U, %v, want %U", char, err, expected)
+		}
+	}
+
+	sd := GetDecoder("Big5-HKSCS").(SequenceDecoder)
+	r = bytes.NewReader(in)
+	for _, expected := range [][]rune{{0xca, 0x304}, {'a'}} {
+		seq, err := sd.DecodeSequence(r)
+		if err != nil || !reflect.DeepEqual(seq, expected) {
+			t.Errorf("DecodeSequence: got %U, %v, want %U", seq, err, expected)
+		}
+	}
+
+	// DecodeBytes needs room for the whole sequence.
+	bd := GetDecoder("Big5-HKSCS").(BulkDecoder)
+	dst := make([]rune, 2)
+	nDst, nSrc, err := bd.DecodeBytes(dst[:1], in, true)
+	if nDst != 0 || nSrc != 0 || err != ErrShortDst {
+		t.Errorf("DecodeBytes: got %d, %d, %v, want 0, 0, %v", nDst, nSrc, err, ErrShortDst)
+	}
+	nDst, nSrc, err = bd.DecodeBytes(dst, in, true)
+	if nDst != 2 || nSrc != 2 || err != ErrShortDst || string(dst) != "Ê̄" {
+		t.Errorf("DecodeBytes: got %q, %d, %d, %v", string(dst[:nDst]), nDst, nSrc, err)
+	}
+
+	// Both runes have the position of the code they came from.
+	for _, decoder := range []Decoder{GetDecoder("Big5-HKSCS"), decoderOnly(GetDecoder("Big5-HKSCS"))} {
+		var ee *EncodeError
+		err := Recode(bytes.NewReader([]byte{'a', 0x88, 0x62}), &bytes.Buffer{}, decoder, GetEncoder("ISO-8859-1"))
+		if !errors.As(err, &ee) || ee.Rune != 0x304 || ee.Offset != 1 || ee.Index != 2 || ee.Column != 3 {
+			t.Errorf("%T: got %v", decoder, err)
+		}
+	}
+}
+
+func TestBig5HKSCSEncodeErrors(t *testing.T) {
+	// A letter that was held back is written before the error.
+	e := GetEncoder("Big5-HKSCS")
+	actual := &bytes.Buffer{}
+	e.Encode(actual, 'Ê')
+	var ee *EncodeError
+	err := e.Encode(actual, 0x10ffff)
+	if !errors.As(err, &ee) || ee.Err != ErrOutOfRange || ee.Rune != 0x10ffff {
+		t.Errorf("got %v, want %v", err, ErrOutOfRange)
+	}
+	if !bytes.Equal(actual.Bytes(), []byte{0x88, 0x66}) {
+		t.Errorf("got % x, want 88 66", actual.Bytes())
+	}
+
+	// Flushing afterwards writes nothing more.
+	e.(Flusher).Flush(actual)
+	if actual.Len() != 2 {
+		t.Errorf("got % x after flushing", actual.Bytes())
+	}
+}
@@ -0,0 +1,302 @@
+package codec
+
+import "io"
+
+// BOCU-1 (Unicode Technical Note #6) encodes the difference between each
+// character and the one before, or rather the middle of the block it was in,
+// so text in a small script takes about one byte per character and Chinese
+// about two. Unlike SCSU it keeps the binary order of the code points.
+//
+// Control codes and space are written as themselves. A control code other than
+// space resets the state, and so does byte 0xFF, so the text can be picked up
+// at the start of any line.
+func init() {
+	registerCodec(Codec{
+		Name: "BOCU-1",
+		Aliases: []string{
+			// IANA
+			"csBOCU1",
+		},
+		NewDecoder: func(...Options) Decoder {
+			return &BOCU1Decoder{prev: bocu1ASCIIPrev}
+		},
+		NewEncoder: func(...Options) Encoder {
+			return &BOCU1Encoder{prev: bocu1ASCIIPrev}
+		},
+		Info: Info{MaxRune: 0x10ffff, MinBytes: 1, MaxBytes: 4},
+	})
+}
+
+const (
+	// bocu1ASCIIPrev is the state at the start of the text and after a
+	// control code, the middle of the ASCII block.
+	bocu1ASCIIPrev = 0x40
+
+	// Lead bytes are from bocu1Min up, and bocu1Reset resets the state.
+	// Single byte differences are centered on bocu1Middle.
+	bocu1Min    = 0x21
+	bocu1Middle = 0x90
+	bocu1Reset  = 0xff
+
+	// bocu1TrailCount is the number of values a trail byte can have:
+	// 0x21-0xFF and the 20 control codes that don't matter much.
+	bocu1TrailCount = 0xff - bocu1Min + 1 + 20
+
+	// The number of lead bytes for each length.
+	bocu1Single = 64
+	bocu1Lead2  = 43
+	bocu1Lead3  = 3
+
+	// The largest differences each length can hold.
+	bocu1ReachPos1 = bocu1Single - 1
+	bocu1ReachNeg1 = -bocu1Single
+	bocu1ReachPos2 = bocu1ReachPos1 + bocu1Lead2*bocu1TrailCount
+	bocu1ReachNeg2 = bocu1ReachNeg1 - bocu1Lead2*bocu1TrailCount
+	bocu1ReachPos3 = bocu1ReachPos2 + bocu1Lead3*bocu1TrailCount*bocu1TrailCount
+	bocu1ReachNeg3 = bocu1ReachNeg2 - bocu1Lead3*bocu1TrailCount*bocu1TrailCount
+
+	// The first lead byte for each length.
+	bocu1StartPos2 = bocu1Middle + bocu1ReachPos1 + 1
+	bocu1StartPos3 = bocu1StartPos2 + bocu1Lead2
+	bocu1StartPos4 = bocu1StartPos3 + bocu1Lead3
+	bocu1StartNeg2 = bocu1Middle + bocu1ReachNeg1
+	bocu1StartNeg3 = bocu1StartNeg2 - bocu1Lead2
+	bocu1StartNeg4 = bocu1StartNeg3 - bocu1Lead3
+)
+
+// bocu1ControlTrails are the control codes that can be trail bytes, in order
+// of their value. The others, including CR, LF and tab, can't.
+var bocu1ControlTrails = [20]byte{
+	0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x10, 0x11,
+	0x12, 0x13, 0x14, 0x15, 0x16, 0x17, 0x18, 0x19,
+	0x1c, 0x1d, 0x1e, 0x1f,
+}
+
+// bocu1TrailByte returns the trail byte for a value from 0 to
+// bocu1TrailCount-1.
+func bocu1TrailByte(v int32) byte {
+	if v < int32(len(bocu1ControlTrails)) {
+		return bocu1ControlTrails[v]
+	}
+	return byte(v - int32(len(bocu1ControlTrails)) + bocu1Min)
+}
+
+// bocu1TrailValue returns the value of trail byte b, or -1 if b can't be one.
+func bocu1TrailValue(b byte) int32 {
+	if b >= bocu1Min {
+		return int32(b-bocu1Min) + int32(len(bocu1ControlTrails))
+	}
+	for v, c := range bocu1ControlTrails {
+		if c == b {
+			return int32(v)
+		}
+	}
+	return -1
+}
+
+// bocu1Prev returns the state after c, which is normally the middle of its
+// block. Hiragana, the CJK ideographs and Hangul are treated as one block
+// each.
+func bocu1Prev(c rune) rune {
+	switch {
+	case c >= 0x3040 && c <= 0x309f:
+		return 0x3070
+	case c >= 0x4e00 && c <= 0x9fa5:
+		return 0x4e00 - bocu1ReachNeg2
+	case c >= 0xac00 && c <= 0xd7a3:
+		return (0xd7a3 + 0xac00) / 2
+	default:
+		return c&^0x7f + bocu1ASCIIPrev
+	}
+}
+
+var _ BulkDecoder = &BOCU1Decoder{}
+
+// BOCU1Decoder reads BOCU-1. Use GetDecoder to get one.
+type BOCU1Decoder struct {
+	in   input
+	prev rune
+}
+
+// Decode satisfies the Decoder interface for BOCU-1.
+func (d *BOCU1Decoder) Decode(r io.Reader) (rune, error) {
+	return d.in.decodeRune(r, "BOCU-1", d.decode)
+}
+
+// BulkDecoder satisfies the BulkDecoder interface for BOCU-1.
+func (d *BOCU1Decoder) BulkDecoder() BulkDecoder {
+	return d
+}
+
+// DecodeBytes satisfies the BulkDecoder interface for BOCU-1.
+func (d *BOCU1Decoder) DecodeBytes(dst []rune, src []byte, atEOF bool) (nDst, nSrc int, err error) {
+	return decodeBytes(dst, src, atEOF, "BOCU-1", d.decode)
+}
+
+// Buffered returns the number of bytes that have been read but not decoded.
+func (d *BOCU1Decoder) Buffered() int {
+	return d.in.Buffered()
+}
+
+// decode is the decodeFunc for BOCU-1.
+func (d *BOCU1Decoder) decode(src []byte, atEOF bool) (rune, int, error) {
+	b := src[0]
+	switch {
+	case b <= 0x20:
+		if b != 0x20 {
+			d.prev = bocu1ASCIIPrev
+		}
+		return rune(b), 1, nil
+	case b == bocu1Reset:
+		d.prev = bocu1ASCIIPrev
+		return noRune, 1, nil
+	case b >= bocu1StartNeg2 && b < bocu1StartPos2:
+		return d.char(d.prev+rune(b)-bocu1Middle, 1)
+	}
+
+	// The lead byte gives the length and the high part of the
+	// difference, the trail bytes are the rest in base bocu1TrailCount.
+	var diff int32
+	var count int
+	switch {
+	case b >= bocu1StartPos4:
+		diff, count = bocu1ReachPos3+1, 3
+	case b >= bocu1StartPos3:
+		diff, count = (int32(b)-bocu1StartPos3)*bocu1TrailCount*bocu1TrailCount+bocu1ReachPos2+1, 2
+	case b >= bocu1StartPos2:
+		diff, count = (int32(b)-bocu1StartPos2)*bocu1TrailCount+bocu1ReachPos1+1, 1
+	case b >= bocu1StartNeg3:
+		diff, count = (int32(b)-bocu1StartNeg2)*bocu1TrailCount+bocu1ReachNeg1, 1
+	case b >= bocu1StartNeg4:
+		diff, count = (int32(b)-bocu1StartNeg3)*bocu1TrailCount*bocu1TrailCount+bocu1ReachNeg2, 2
+	default:
+		diff, count = -bocu1TrailCount*bocu1TrailCount*bocu1TrailCount+bocu1ReachNeg3, 3
+	}
+
+	var place int32 = 1
+	for i := 1; i < count; i++ {
+		place *= bocu1TrailCount
+	}
+	for i := 1; i <= count; i++ {
+		if i == len(src) {
+			if !atEOF {
+				return 0, 0, ErrShortSrc
+			}
+			return 0, i, ErrTruncated
+		}
+
+		v := bocu1TrailValue(src[i])
+		if v < 0 {
+			return 0, i, ErrInvalidSequence
+		}
+		diff += v * place
+		place /= bocu1TrailCount
+	}
+
+	return d.char(d.prev+rune(diff), count+1)
+}
+
+// char returns c, decoded from n bytes, and updates the state, or returns an
+// error if c isn't a character.
+func (d *BOCU1Decoder) char(c rune, n int) (rune, int, error) {
+	if err := checkRune(c); err != nil {
+		return 0, n, err
+	}
+	d.prev = bocu1Prev(c)
+	return c, n, nil
+}
+
+var _ BulkEncoder = &BOCU1Encoder{}
+
+// BOCU1Encoder writes BOCU-1. Use GetEncoder to get one.
+type BOCU1Encoder struct {
+	prev rune
+}
+
+// Encode satisfies the Encoder interface for BOCU-1.
+func (e *BOCU1Encoder) Encode(w io.Writer, r rune) error {
+	if err := checkRune(r); err != nil {
+		return &EncodeError{Encoding: "BOCU-1", Rune: r, Err: err}
+	}
+
+	buf := make([]byte, 4)
+	n := e.encodeChar(buf, r)
+	_, err := w.Write(buf[:n])
+	return err
+}
+
+// BulkEncoder satisfies the BulkEncoder interface for BOCU-1.
+func (e *BOCU1Encoder) BulkEncoder() BulkEncoder {
+	return e
+}
+
+// EncodeRunes satisfies the BulkEncoder interface for BOCU-1.
+func (e *BOCU1Encoder) EncodeRunes(dst []byte, src []rune) (nDst, nSrc int, err error) {
+	for ; nSrc < len(src); nSrc++ {
+		r := src[nSrc]
+		if err := checkRune(r); err != nil {
+			return nDst, nSrc, &EncodeError{Encoding: "BOCU-1", Rune: r, Err: err}
+		}
+		if len(dst)-nDst < 4 {
+			return nDst, nSrc, ErrShortDst
+		}
+		nDst += e.encodeChar(dst[nDst:], r)
+	}
+	return nDst, nSrc, nil
+}
+
+// encodeChar writes r to buf, which must have room for 4 bytes, and returns the
+// number of bytes written.
+func (e *BOCU1Encoder) encodeChar(buf []byte, r rune) int {
+	if r <= 0x20 {
+		if r != 0x20 {
+			e.prev = bocu1ASCIIPrev
+		}
+		buf[0] = byte(r)
+		return 1
+	}
+
+	diff := int32(r - e.prev)
+	e.prev = bocu1Prev(r)
+
+	var lead int32
+	var count int
+	switch {
+	case diff >= bocu1ReachNeg1 && diff <= bocu1ReachPos1:
+		buf[0] = byte(bocu1Middle + diff)
+		return 1
+	case diff > bocu1ReachPos3:
+		diff -= bocu1ReachPos3 + 1
+		lead, count = bocu1StartPos4, 3
+	case diff > bocu1ReachPos2:
+		diff -= bocu1ReachPos2 + 1
+		lead, count = bocu1StartPos3, 2
+	case diff > bocu1ReachPos1:
+		diff -= bocu1ReachPos1 + 1
+		lead, count = bocu1StartPos2, 1
+	case diff >= bocu1ReachNeg2:
+		diff -= bocu1ReachNeg1
+		lead, count = bocu1StartNeg2, 1
+	case diff >= bocu1ReachNeg3:
+		diff -= bocu1ReachNeg2
+		lead, count = bocu1StartNeg3, 2
+	default:
+		diff -= bocu1ReachNeg3
+		lead, count = bocu1StartNeg4, 3
+	}
+
+	// Write the trail bytes from the last, like the digits of a number,
+	// and the lead byte takes what's left, which is negative for
+	// negative differences.
+	for i := count; i > 0; i-- {
+		m := diff % bocu1TrailCount
+		diff /= bocu1TrailCount
+		if m < 0 {
+			diff--
+			m += bocu1TrailCount
+		}
+		buf[i] = bocu1TrailByte(m)
+	}
+	buf[0] = byte(lead + diff)
+	return count + 1
+}
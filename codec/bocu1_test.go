@@ -0,0 +1,129 @@
+package codec
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestBOCU1(t *testing.T) {
+	cases := []struct {
+		text    string
+		encoded []byte
+	}{
+		{
+			text:    "aé€",
+			encoded: []byte{0xb1, 0xd0, 0x76, 0xf1, 0x66},
+		},
+		{
+			text:    "Москва",
+			encoded: []byte{0xd3, 0xd0, 0x8e, 0x91, 0x8a, 0x82, 0x80},
+		},
+		{
+			// A space doesn't change the state
+			text:    "é é",
+			encoded: []byte{0xd0, 0x76, 0x20, 0xb9},
+		},
+		{
+			// But other control codes reset it
+			text:    "é\né",
+			encoded: []byte{0xd0, 0x76, '\n', 0xd0, 0x76},
+		},
+		{
+			text:    "😀😁",
+			encoded: []byte{0xfc, 0xff, 0x5d, 0x51},
+		},
+	}
+
+	for _, c := range cases {
+		actual := &bytes.Buffer{}
+		err := Recode(bytes.NewReader(c.encoded), actual, GetDecoder("BOCU-1"), NewUTF8Encoder())
+		if err != nil {
+			t.Errorf("% x: decode error: %v", c.encoded, err)
+		} else if actual.String() != c.text {
+			t.Errorf("% x: got %q, want %q", c.encoded, actual.String(), c.text)
+		}
+
+		actual.Reset()
+		err = Recode(strings.NewReader(c.text), actual, NewUTF8Decoder(), GetEncoder("BOCU-1"))
+		if err != nil {
+			t.Errorf("%q: encode error: %v", c.text, err)
+		} else if !bytes.Equal(actual.Bytes(), c.encoded) {
+			t.Errorf("%q: got % x, want % x", c.text, actual.Bytes(), c.encoded)
+		}
+	}
+}
+
+func TestBOCU1Decode(t *testing.T) {
+	cases := []struct {
+		in       []byte
+		expected string
+	}{
+		{
+			// 0xFF resets the state
+			in:       []byte{0xd0, 0x76, 0xff, 0xb1},
+			expected: "éa",
+		},
+		{
+			in:       []byte{0xd0, 0x76, 0xb1},
+			expected: "éá",
+		},
+		{
+			// Not a trail byte
+			in:       []byte{0xd0, '\n', 0xb1},
+			expected: "�\na",
+		},
+		{
+			// Truncated
+			in:       []byte{0xb1, 0xfb, 0xee},
+			expected: "a�",
+		},
+		{
+			// Out of range
+			in:       []byte{0xfe, 0xff, 0xff, 0xff, '\n'},
+			expected: "�\n",
+		},
+		{
+			// Lone surrogate
+			in:       []byte{0xfb, 0xc5, 0x11, '\n'},
+			expected: "�\n",
+		},
+	}
+
+	for _, c := range cases {
+		for _, decoder := range []Decoder{GetDecoder("BOCU-1"), decoderOnly(GetDecoder("BOCU-1"))} {
+			actual := &bytes.Buffer{}
+			err := Recode(bytes.NewReader(c.in), actual, decoder, NewUTF8Encoder(), WithErrorPolicy(Replace))
+			if err != nil {
+				t.Errorf("% x: recode error: %v", c.in, err)
+			} else if actual.String() != c.expected {
+				t.Errorf("% x: got %q, want %q", c.in, actual.String(), c.expected)
+			}
+		}
+	}
+}
+
+func TestBOCU1Encode(t *testing.T) {
+	// The signature from UTN #6. Recode would drop it as a byte order
+	// mark.
+	actual := &bytes.Buffer{}
+	if err := GetEncoder("BOCU-1").Encode(actual, 0xfeff); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(actual.Bytes(), []byte{0xfb, 0xee, 0x28}) {
+		t.Errorf("U+FEFF: got % x, want fb ee 28", actual.Bytes())
+	}
+
+	for _, r := range []rune{0xd800, 0xdc00, 0x110000, -1} {
+		var ee *EncodeError
+		err := GetEncoder("BOCU-1").Encode(&bytes.Buffer{}, r)
+		if !errors.As(err, &ee) || ee.Encoding != "BOCU-1" || ee.Rune != r {
+			t.Errorf("%U: got %v", r, err)
+		}
+	}
+}
+
+func TestBOCU1Samples(t *testing.T) {
+	checkSampleRoundTrip(t, "BOCU-1")
+}
@@ -0,0 +1,238 @@
+package codec
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// BulkDecoder is a Decoder that can also decode a slice of bytes at a time,
+// which avoids the cost of a call and a read for every character. Every
+// built-in decoder implements it, and Recode uses it when both the decoder and
+// the encoder support it.
+//
+// A decoder keeps the same state, such as the byte order, whichever method is
+// used, but it shouldn't be used with both since Decode may read ahead.
+type BulkDecoder interface {
+	Decoder
+
+	// BulkDecoder returns the decoder itself. Recode only uses DecodeBytes
+	// when it does, so a type that embeds a built-in decoder to change
+	// what Decode does isn't decoded with the promoted DecodeBytes.
+	BulkDecoder() BulkDecoder
+
+	// DecodeBytes decodes characters from src into dst. It returns the
+	// number of characters written to dst and the number of bytes of src
+	// that were used.
+	//
+	// If dst fills up before src is used, it returns ErrShortDst. If src
+	// ends part way through a character and atEOF is false, it returns
+	// ErrShortSrc, and the caller should call it again with the rest of
+	// src and more input. If atEOF is true, a partial character is
+	// invalid.
+	//
+	// A character that decodes to more than one rune, as with a
+	// SequenceDecoder, is written to dst all at once. If there isn't room
+	// for all of it, DecodeBytes returns ErrShortDst.
+	//
+	// Invalid input is returned as a *DecodeError. nSrc doesn't include
+	// the invalid bytes, so the caller must skip len(Bytes) bytes to
+	// continue.
+	DecodeBytes(dst []rune, src []byte, atEOF bool) (nDst, nSrc int, err error)
+}
+
+// BulkEncoder is an Encoder that can also encode a slice of characters at a
+// time. Every built-in encoder implements it.
+type BulkEncoder interface {
+	Encoder
+
+	// BulkEncoder returns the encoder itself, for the same reason as
+	// BulkDecoder.BulkDecoder.
+	BulkEncoder() BulkEncoder
+
+	// EncodeRunes encodes characters from src into dst. It returns the
+	// number of bytes written to dst and the number of characters of src
+	// that were used.
+	//
+	// If dst is too small for the next character, it returns ErrShortDst.
+	// A character that can't be encoded is returned as an *EncodeError.
+	// nSrc doesn't include it, so the caller must skip one character to
+	// continue.
+	EncodeRunes(dst []byte, src []rune) (nDst, nSrc int, err error)
+}
+
+// asBulkDecoder returns d as a BulkDecoder, if it is one and the methods
+// aren't promoted from an embedded decoder.
+func asBulkDecoder(d Decoder) (BulkDecoder, bool) {
+	bd, ok := d.(BulkDecoder)
+	if !ok || bd.BulkDecoder() != d {
+		return nil, false
+	}
+	return bd, true
+}
+
+// asBulkEncoder returns e as a BulkEncoder, if it is one and the methods
+// aren't promoted from an embedded encoder.
+func asBulkEncoder(e Encoder) (BulkEncoder, bool) {
+	be, ok := e.(BulkEncoder)
+	if !ok || be.BulkEncoder() != e {
+		return nil, false
+	}
+	return be, true
+}
+
+// bulkSize is the size of the buffers Recode uses with a BulkDecoder and
+// BulkEncoder.
+const bulkSize = 32 << 10
+
+// recodeBulk does the work of Recode for a decoder and encoder that both work
+// on slices.
+//
+// Characters are decoded one at a time, so the offset of each one is known if
+// the encoder rejects it. That's still far cheaper than Decode, since there's
+// no read or allocation per character. They're encoded a buffer at a time.
+func (rc *recoder) recodeBulk(w io.Writer, dec BulkDecoder, enc BulkEncoder) error {
+	src := make([]byte, bulkSize)
+	runes := make([]rune, 0, bulkSize)
+	offsets := make([]int64, 0, bulkSize)
+	out := make([]byte, bulkSize)
+
+	// src[start:end] is the input that hasn't been decoded yet, base is
+	// the offset of src[0] in the input.
+	var start, end int
+	var base int64
+	atEOF := false
+	needInput := true
+
+	for {
+		if needInput && !atEOF {
+			copy(src, src[start:end])
+			base += int64(start)
+			end -= start
+			start = 0
+
+			n, err := rc.r.Read(src[end:])
+			end += n
+			if err == io.EOF {
+				atEOF = true
+			} else if err != nil {
+				return err
+			}
+		}
+		needInput = false
+
+		runes, offsets = runes[:0], offsets[:0]
+		var err error
+		room := 1
+		for len(runes)+room <= cap(runes) {
+			var nDst, nSrc int
+			nDst, nSrc, err = dec.DecodeBytes(runes[len(runes):len(runes)+room], src[start:end], atEOF)
+			for i := 0; i < nDst; i++ {
+				offsets = append(offsets, base+int64(start))
+			}
+			runes = runes[:len(runes)+nDst]
+			start += nSrc
+			if err != ErrShortDst {
+				break
+			}
+
+			// A character that decodes to a sequence of runes
+			// needs more room.
+			if nDst == 0 {
+				room++
+			} else {
+				room = 1
+			}
+		}
+
+		if err := rc.encodeRunes(w, enc, out, runes, offsets); err != nil {
+			return err
+		}
+
+		switch err {
+		case nil, ErrShortSrc:
+			if atEOF {
+				if err == nil && start == end {
+					return nil
+				}
+				return fmt.Errorf("error decoding character: %w", io.ErrUnexpectedEOF)
+			}
+			if start == 0 && end == len(src) {
+				return fmt.Errorf("error decoding character: %w", err)
+			}
+			needInput = true
+			continue
+		case ErrShortDst:
+			continue
+		}
+
+		var de *DecodeError
+		if !errors.As(err, &de) {
+			return fmt.Errorf("error decoding character: %w", err)
+		}
+
+		pos := rc.pos
+		pos.Offset = base + int64(start)
+		de.Position = pos
+		start += len(de.Bytes)
+
+		if err := rc.start(w, 0xfffd); err != nil {
+			return err
+		}
+		rc.advance(0xfffd)
+
+		if err := rc.decodeError(w, de); err != nil {
+			return err
+		}
+	}
+}
+
+// encodeRunes encodes runes to w using out as a buffer. offsets holds the input
+// offset of each character, for errors.
+func (rc *recoder) encodeRunes(w io.Writer, enc BulkEncoder, out []byte, runes []rune, offsets []int64) error {
+	if len(runes) == 0 {
+		return nil
+	}
+
+	if !rc.started && runes[0] == bomRune && rc.opts.bom == BOMNever {
+		rc.started = true
+		rc.advance(bomRune)
+		runes, offsets = runes[1:], offsets[1:]
+	}
+	if len(runes) > 0 {
+		if err := rc.start(w, runes[0]); err != nil {
+			return err
+		}
+	}
+
+	for len(runes) > 0 {
+		nDst, nSrc, err := enc.EncodeRunes(out, runes)
+		if _, werr := w.Write(out[:nDst]); werr != nil {
+			return werr
+		}
+		for _, char := range runes[:nSrc] {
+			rc.advance(char)
+		}
+		runes, offsets = runes[nSrc:], offsets[nSrc:]
+
+		if err == nil || err == ErrShortDst {
+			continue
+		}
+
+		char := runes[0]
+		var ee *EncodeError
+		if !errors.As(err, &ee) {
+			return fmt.Errorf("error encoding character (0x%x): %w", char, err)
+		}
+
+		pos := rc.pos
+		pos.Offset = offsets[0]
+		rc.advance(char)
+		runes, offsets = runes[1:], offsets[1:]
+
+		if err := rc.encodeError(w, ee, char, pos); err != nil {
+			return err
+		}
+	}
+	return nil
+}
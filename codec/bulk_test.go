@@ -0,0 +1,222 @@
+package codec
+
+import (
+	"bytes"
+	"math/rand"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+// decoderOnly and encoderOnly hide the bulk methods, so Recode uses Decode and
+// Encode. The other optional interfaces are kept, so Recode takes the same
+// path it would for a decoder or encoder that isn't a bulk one.
+func decoderOnly(d Decoder) Decoder {
+	b, isBuffered := d.(buffered)
+	sd, isSequence := d.(SequenceDecoder)
+	switch {
+	case isBuffered && isSequence:
+		return struct {
+			SequenceDecoder
+			buffered
+		}{sd, b}
+	case isSequence:
+		return struct{ SequenceDecoder }{sd}
+	case isBuffered:
+		return struct {
+			Decoder
+			buffered
+		}{d, b}
+	}
+	return struct{ Decoder }{d}
+}
+
+func encoderOnly(e Encoder) Encoder {
+	if f, ok := e.(Flusher); ok {
+		return struct {
+			Encoder
+			Flusher
+		}{e, f}
+	}
+	return struct{ Encoder }{e}
+}
+
+const bulkTestText = "\ufeffDown the 🐇 hole, ∠؉₡🌎\nВсе люди рождаются свободными. すべての人間は、生まれながらにして自由であり\r\n"
+
+func TestBulkImplemented(t *testing.T) {
+	for _, name := range Names() {
+		c := Lookup(name)
+		if c.NewDecoder != nil {
+			if _, ok := asBulkDecoder(c.NewDecoder()); !ok {
+				t.Errorf("%s decoder doesn't implement BulkDecoder", name)
+			}
+		}
+		if c.NewEncoder != nil {
+			if _, ok := asBulkEncoder(c.NewEncoder()); !ok {
+				t.Errorf("%s encoder doesn't implement BulkEncoder", name)
+			}
+		}
+	}
+}
+
+// TestBulkDecode checks that DecodeBytes gives the same results as Decode for
+// every codec, for valid text, corrupted text and random bytes.
+func TestBulkDecode(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+
+	for _, name := range Names() {
+		c := Lookup(name)
+		if c.NewDecoder == nil {
+			continue
+		}
+
+		inputs := [][]byte{{}}
+		if c.NewEncoder != nil {
+			encoded := &bytes.Buffer{}
+			err := Recode(strings.NewReader(bulkTestText), encoded, NewUTF8Decoder(Options{BOM: BOMPreserve}), c.NewEncoder(), WithErrorPolicy(Skip))
+			if err != nil {
+				t.Fatalf("%s: recode error: %v", name, err)
+			}
+			valid := encoded.Bytes()
+			inputs = append(inputs, valid)
+
+			for i := 0; i < 20 && len(valid) > 0; i++ {
+				corrupt := append([]byte(nil), valid...)
+				corrupt[rng.Intn(len(corrupt))] = byte(rng.Intn(256))
+				inputs = append(inputs, corrupt[:rng.Intn(len(corrupt)+1)])
+			}
+		}
+		for i := 0; i < 20; i++ {
+			random := make([]byte, rng.Intn(64))
+			rng.Read(random)
+			inputs = append(inputs, random)
+		}
+
+		for _, in := range inputs {
+			for _, policy := range []ErrorPolicy{Strict, Escape} {
+				expected := &bytes.Buffer{}
+				expectedErr := Recode(bytes.NewReader(in), expected, decoderOnly(c.NewDecoder()), NewUTF8Encoder(), WithErrorPolicy(policy))
+
+				// Read one byte at a time to split every
+				// character.
+				actual := &bytes.Buffer{}
+				actualErr := Recode(iotest.OneByteReader(bytes.NewReader(in)), actual, c.NewDecoder(), NewUTF8Encoder(), WithErrorPolicy(policy))
+
+				if errString(actualErr) != errString(expectedErr) {
+					t.Errorf("%s %v % x: got error %v, want %v", name, policy, in, actualErr, expectedErr)
+				}
+				if actual.String() != expected.String() {
+					t.Errorf("%s %v % x: got %q, want %q", name, policy, in, actual.String(), expected.String())
+				}
+			}
+		}
+	}
+}
+
+// TestBulkEncode checks that EncodeRunes gives the same results as Encode for
+// every codec.
+func TestBulkEncode(t *testing.T) {
+	inputs := []string{
+		"",
+		bulkTestText,
+		"\ufeff",
+		"no BOM",
+		"\U0010ffff\x00\x7f\x80\xffĀ￿",
+	}
+
+	for _, name := range Names() {
+		c := Lookup(name)
+		if c.NewEncoder == nil {
+			continue
+		}
+
+		for _, in := range inputs {
+			for _, policy := range []ErrorPolicy{Strict, Escape} {
+				decoder := NewUTF8Decoder(Options{BOM: BOMPreserve})
+				expected := &bytes.Buffer{}
+				expectedErr := Recode(strings.NewReader(in), expected, decoderOnly(decoder), encoderOnly(c.NewEncoder()), WithErrorPolicy(policy))
+
+				decoder = NewUTF8Decoder(Options{BOM: BOMPreserve})
+				actual := &bytes.Buffer{}
+				actualErr := Recode(strings.NewReader(in), actual, decoder, c.NewEncoder(), WithErrorPolicy(policy))
+
+				if errString(actualErr) != errString(expectedErr) {
+					t.Errorf("%s %v %q: got error %v, want %v", name, policy, in, actualErr, expectedErr)
+				}
+				if !bytes.Equal(actual.Bytes(), expected.Bytes()) {
+					t.Errorf("%s %v %q: got % x, want % x", name, policy, in, actual.Bytes(), expected.Bytes())
+				}
+			}
+		}
+	}
+}
+
+func TestBulkShortBuffers(t *testing.T) {
+	d := NewUTF8Decoder().(BulkDecoder)
+	dst := make([]rune, 2)
+
+	nDst, nSrc, err := d.DecodeBytes(dst, []byte("ab€"), false)
+	if nDst != 2 || nSrc != 2 || err != ErrShortDst {
+		t.Errorf("got %d, %d, %v, want 2, 2, %v", nDst, nSrc, err, ErrShortDst)
+	}
+
+	nDst, nSrc, err = d.DecodeBytes(dst, []byte("\xe2\x82"), false)
+	if nDst != 0 || nSrc != 0 || err != ErrShortSrc {
+		t.Errorf("got %d, %d, %v, want 0, 0, %v", nDst, nSrc, err, ErrShortSrc)
+	}
+
+	e := NewUTF8Encoder().(BulkEncoder)
+	out := make([]byte, 8)
+	nDst, nSrc, err = e.EncodeRunes(out, []rune("a€€"))
+	if nDst != 4 || nSrc != 2 || err != ErrShortDst {
+		t.Errorf("got %d, %d, %v, want 4, 2, %v", nDst, nSrc, err, ErrShortDst)
+	}
+}
+
+func errString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+// benchmarkText is about 1 MiB of mixed text.
+var benchmarkText = []byte(strings.Repeat(bulkTestText+strings.Repeat("plain ASCII text. ", 8), 3000))
+
+func BenchmarkRecode(b *testing.B) {
+	pairs := []struct {
+		from, to string
+	}{
+		{"UTF-8", "UTF-8"},
+		{"UTF-8", "UTF-16LE"},
+		{"UTF-16LE", "UTF-32BE"},
+	}
+
+	for _, p := range pairs {
+		in := &bytes.Buffer{}
+		err := Recode(bytes.NewReader(benchmarkText), in, NewUTF8Decoder(), GetEncoder(p.from))
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		b.Run(p.from+" to "+p.to+"/per-rune", func(b *testing.B) {
+			b.SetBytes(int64(in.Len()))
+			for i := 0; i < b.N; i++ {
+				err := Recode(bytes.NewReader(in.Bytes()), &bytes.Buffer{}, decoderOnly(GetDecoder(p.from)), encoderOnly(GetEncoder(p.to)))
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+
+		b.Run(p.from+" to "+p.to+"/bulk", func(b *testing.B) {
+			b.SetBytes(int64(in.Len()))
+			for i := 0; i < b.N; i++ {
+				err := Recode(bytes.NewReader(in.Bytes()), &bytes.Buffer{}, GetDecoder(p.from), GetEncoder(p.to))
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
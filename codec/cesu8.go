@@ -0,0 +1,173 @@
+package codec
+
+import "io"
+
+// CESU-8 is UTF-8 applied to UTF-16 rather than to code points, as written by
+// Oracle databases and other software that works in UTF-16. Characters above
+// U+FFFF are a surrogate pair, and each surrogate is a three byte sequence,
+// so they take six bytes rather than four.
+//
+// MUTF-8 is Java's Modified UTF-8, used in class files, JNI and DataOutput.
+// It's CESU-8 except that NUL is the overlong sequence C0 80, so the encoded
+// text never contains a zero byte. The decoder accepts a zero byte too.
+func init() {
+	registerCodec(Codec{
+		Name: "CESU-8",
+		Aliases: []string{
+			// IANA
+			"csCESU8",
+		},
+		NewDecoder: func(...Options) Decoder {
+			return &CESU8Decoder{name: "CESU-8"}
+		},
+		NewEncoder: func(...Options) Encoder {
+			return &CESU8Encoder{name: "CESU-8"}
+		},
+		Info: Info{MaxRune: 0x10ffff, MinBytes: 1, MaxBytes: 6},
+	})
+	registerCodec(Codec{
+		Name: "MUTF-8",
+		Aliases: []string{
+			"Modified-UTF-8",
+		},
+		NewDecoder: func(...Options) Decoder {
+			return &CESU8Decoder{name: "MUTF-8", modified: true}
+		},
+		NewEncoder: func(...Options) Encoder {
+			return &CESU8Encoder{name: "MUTF-8", modified: true}
+		},
+		Info: Info{MaxRune: 0x10ffff, MinBytes: 1, MaxBytes: 6},
+	})
+}
+
+var _ BulkDecoder = &CESU8Decoder{}
+
+// CESU8Decoder reads CESU-8 or MUTF-8. Use GetDecoder to get one.
+type CESU8Decoder struct {
+	name string
+	in   input
+
+	// modified is set for MUTF-8.
+	modified bool
+}
+
+// Decode satisfies the Decoder interface for CESU-8.
+func (d *CESU8Decoder) Decode(r io.Reader) (rune, error) {
+	return d.in.decodeRune(r, d.name, d.decode)
+}
+
+// BulkDecoder satisfies the BulkDecoder interface for CESU-8.
+func (d *CESU8Decoder) BulkDecoder() BulkDecoder {
+	return d
+}
+
+// DecodeBytes satisfies the BulkDecoder interface for CESU-8.
+func (d *CESU8Decoder) DecodeBytes(dst []rune, src []byte, atEOF bool) (nDst, nSrc int, err error) {
+	return decodeBytes(dst, src, atEOF, d.name, d.decode)
+}
+
+// Buffered returns the number of bytes that have been read but not decoded.
+func (d *CESU8Decoder) Buffered() int {
+	return d.in.Buffered()
+}
+
+// decode is the decodeFunc for CESU-8.
+func (d *CESU8Decoder) decode(src []byte, atEOF bool) (rune, int, error) {
+	if d.modified && src[0] == 0xc0 && (len(src) == 1 || src[1] == 0x80) {
+		if len(src) == 1 {
+			if !atEOF {
+				return 0, 0, ErrShortSrc
+			}
+			return 0, 1, ErrTruncated
+		}
+		return 0, 2, nil
+	}
+
+	high, n, err := utf8Char(src, atEOF)
+	switch {
+	case err != nil:
+		return 0, n, err
+	case n == 4:
+		// Characters above U+FFFF have to be surrogate pairs.
+		return 0, n, ErrInvalidSequence
+	case high&utf16SurrogateMask == utf16LowSurrogate:
+		return 0, n, ErrLoneSurrogate
+	case high&utf16SurrogateMask != utf16HighSurrogate:
+		return high, n, nil
+	}
+
+	if len(src) == n {
+		if !atEOF {
+			return 0, 0, ErrShortSrc
+		}
+		return 0, n, ErrLoneSurrogate
+	}
+	low, m, err := utf8Char(src[n:], atEOF)
+	switch {
+	case err == ErrShortSrc:
+		return 0, 0, err
+	case err != nil || low < utf16LowSurrogate || low > 0xdfff:
+		// Whatever follows is left for the next call.
+		return 0, n, ErrLoneSurrogate
+	}
+	return 0x10000 + (high&0x3ff)<<10 | low&0x3ff, n + m, nil
+}
+
+var _ BulkEncoder = &CESU8Encoder{}
+
+// CESU8Encoder writes CESU-8 or MUTF-8. Use GetEncoder to get one.
+type CESU8Encoder struct {
+	name string
+
+	// modified is set for MUTF-8.
+	modified bool
+}
+
+// Encode satisfies the Encoder interface for CESU-8.
+func (e *CESU8Encoder) Encode(w io.Writer, r rune) error {
+	if err := checkRune(r); err != nil {
+		return &EncodeError{Encoding: e.name, Rune: r, Err: err}
+	}
+
+	buf := make([]byte, 6)
+	n := e.encodeChar(buf, r)
+	_, err := w.Write(buf[:n])
+	return err
+}
+
+// BulkEncoder satisfies the BulkEncoder interface for CESU-8.
+func (e *CESU8Encoder) BulkEncoder() BulkEncoder {
+	return e
+}
+
+// EncodeRunes satisfies the BulkEncoder interface for CESU-8.
+func (e *CESU8Encoder) EncodeRunes(dst []byte, src []rune) (nDst, nSrc int, err error) {
+	for ; nSrc < len(src); nSrc++ {
+		r := src[nSrc]
+		if err := checkRune(r); err != nil {
+			return nDst, nSrc, &EncodeError{Encoding: e.name, Rune: r, Err: err}
+		}
+		if len(dst)-nDst < 6 {
+			return nDst, nSrc, ErrShortDst
+		}
+		nDst += e.encodeChar(dst[nDst:], r)
+	}
+	return nDst, nSrc, nil
+}
+
+// encodeChar writes r to buf, which must have room for 6 bytes, and returns
+// the number of bytes written.
+func (e *CESU8Encoder) encodeChar(buf []byte, r rune) int {
+	switch {
+	case r == 0 && e.modified:
+		buf[0] = 0xc0
+		buf[1] = 0x80
+		return 2
+	case r < 0x10000:
+		return putUTF8(buf, r)
+	}
+
+	r -= 0x10000
+	n := putUTF8(buf, utf16HighSurrogate|r>>10)
+	return n + putUTF8(buf[n:], utf16LowSurrogate|r&0x3ff)
+}
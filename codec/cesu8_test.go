@@ -0,0 +1,145 @@
+package codec
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCESU8(t *testing.T) {
+	cases := []struct {
+		encoding string
+		text     string
+		encoded  []byte
+	}{
+		{
+			encoding: "CESU-8",
+			text:     "a\x00é€",
+			encoded:  []byte{'a', 0, 0xc3, 0xa9, 0xe2, 0x82, 0xac},
+		},
+		{
+			encoding: "CESU-8",
+			text:     "😀",
+			encoded:  []byte{0xed, 0xa0, 0xbd, 0xed, 0xb8, 0x80},
+		},
+		{
+			encoding: "CESU-8",
+			text:     "\U00020000",
+			encoded:  []byte{0xed, 0xa1, 0x80, 0xed, 0xb0, 0x80},
+		},
+		{
+			encoding: "MUTF-8",
+			text:     "a\x00é€",
+			encoded:  []byte{'a', 0xc0, 0x80, 0xc3, 0xa9, 0xe2, 0x82, 0xac},
+		},
+		{
+			encoding: "MUTF-8",
+			text:     "😀𝄞",
+			encoded:  []byte{0xed, 0xa0, 0xbd, 0xed, 0xb8, 0x80, 0xed, 0xa0, 0xb4, 0xed, 0xb4, 0x9e},
+		},
+	}
+
+	for _, c := range cases {
+		actual := &bytes.Buffer{}
+		err := Recode(bytes.NewReader(c.encoded), actual, GetDecoder(c.encoding), NewUTF8Encoder())
+		if err != nil {
+			t.Errorf("%s: decode error: %v", c.encoding, err)
+		} else if actual.String() != c.text {
+			t.Errorf("%s: got %q, want %q", c.encoding, actual.String(), c.text)
+		}
+
+		actual.Reset()
+		err = Recode(strings.NewReader(c.text), actual, NewUTF8Decoder(), GetEncoder(c.encoding))
+		if err != nil {
+			t.Errorf("%s %q: encode error: %v", c.encoding, c.text, err)
+		} else if !bytes.Equal(actual.Bytes(), c.encoded) {
+			t.Errorf("%s %q: got % x, want % x", c.encoding, c.text, actual.Bytes(), c.encoded)
+		}
+	}
+
+	// The UTF-8 decoder rejects the six byte form.
+	_, err := NewUTF8Decoder().Decode(bytes.NewReader([]byte{0xed, 0xa0, 0xbd, 0xed, 0xb8, 0x80}))
+	if !errors.Is(err, ErrLoneSurrogate) {
+		t.Errorf("UTF-8: got %v, want %v", err, ErrLoneSurrogate)
+	}
+}
+
+func TestCESU8Decode(t *testing.T) {
+	cases := []struct {
+		encoding string
+		in       []byte
+		expected string
+	}{
+		{
+			// Four byte sequences aren't allowed
+			encoding: "CESU-8",
+			in:       []byte{0xf0, 0x9f, 0x98, 0x80, 'a'},
+			expected: "�a",
+		},
+		{
+			encoding: "CESU-8",
+			in:       []byte{0xc0, 0x80},
+			expected: "�",
+		},
+		{
+			// A zero byte is accepted
+			encoding: "MUTF-8",
+			in:       []byte{0, 0xc0, 0x80, 'a'},
+			expected: "\x00\x00a",
+		},
+		{
+			encoding: "MUTF-8",
+			in:       []byte{'a', 0xc0},
+			expected: "a�",
+		},
+		{
+			// Lone surrogates
+			encoding: "CESU-8",
+			in:       []byte{0xed, 0xa0, 0xbd, 'a', 0xed, 0xb8, 0x80, 'b'},
+			expected: "�a�b",
+		},
+		{
+			encoding: "CESU-8",
+			in:       []byte{0xed, 0xa0, 0xbd, 0xed, 0xa0, 0xbd, 0xed, 0xb8, 0x80},
+			expected: "�😀",
+		},
+		{
+			encoding: "CESU-8",
+			in:       []byte{0xed, 0xa0, 0xbd, 0xed, 0xb8},
+			expected: "��",
+		},
+		{
+			encoding: "CESU-8",
+			in:       []byte{0xed, 0xa0, 0xbd},
+			expected: "�",
+		},
+		{
+			// U+1DC00 isn't a low surrogate, even though its low
+			// bits look like one
+			encoding: "CESU-8",
+			in:       []byte{0xed, 0xa0, 0xbd, 0xf0, 0x9d, 0xb0, 0x80},
+			expected: "��",
+		},
+	}
+
+	for _, c := range cases {
+		actual := &bytes.Buffer{}
+		err := Recode(bytes.NewReader(c.in), actual, GetDecoder(c.encoding), NewUTF8Encoder(), WithErrorPolicy(Replace))
+		if err != nil {
+			t.Errorf("%s % x: recode error: %v", c.encoding, c.in, err)
+		} else if actual.String() != c.expected {
+			t.Errorf("%s % x: got %q, want %q", c.encoding, c.in, actual.String(), c.expected)
+		}
+	}
+}
+
+func TestCESU8Encode(t *testing.T) {
+	for _, r := range []rune{0xd800, 0xdc00, 0x110000} {
+		var ee *EncodeError
+		err := GetEncoder("CESU-8").Encode(&bytes.Buffer{}, r)
+		if !errors.As(err, &ee) || ee.Encoding != "CESU-8" || ee.Rune != r {
+			t.Errorf("%U: got %v", r, err)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package codec
+
+import (
+	"fmt"
+	"io"
+	"sync"
+)
+
+// charmap is a single-byte encoding, where every character is one byte.
+type charmap struct {
+	name string
+
+	// decode holds the character for each byte, or -1 if the byte is
+	// undefined.
+	decode *[256]rune
+
+	// encode is the reverse of decode. It's built the first time it's
+	// needed, since most programs only encode to a few encodings.
+	encodeOnce sync.Once
+	encode     map[rune]byte
+}
+
+// registerCharmap registers a single-byte codec that decodes with table.
+func registerCharmap(name string, aliases []string, table *[256]rune) {
+	cm := &charmap{name: name, decode: table}
+	registerCodec(Codec{
+		Name:    name,
+		Aliases: aliases,
+		NewDecoder: func(...Options) Decoder {
+			return &CharmapDecoder{charmap: cm}
+		},
+		NewEncoder: func(...Options) Encoder {
+			return &CharmapEncoder{charmap: cm}
+		},
+		Info: cm.info(),
+	})
+}
+
+func (cm *charmap) info() Info {
+	var maxRune rune
+	count := 0
+	for _, r := range cm.decode {
+		if r < 0 {
+			continue
+		}
+		count++
+		if r > maxRune {
+			maxRune = r
+		}
+	}
+
+	info := Info{MaxRune: maxRune, MinBytes: 1, MaxBytes: 1}
+	if rune(count) != maxRune+1 {
+		info.Repertoire = fmt.Sprintf("%d characters", count)
+	}
+	return info
+}
+
+// lookup returns the byte for r.
+func (cm *charmap) lookup(r rune) (byte, bool) {
+	cm.encodeOnce.Do(func() {
+		cm.encode = make(map[rune]byte, 256)
+		for b, r := range cm.decode {
+			// If two bytes decode to the same character, use the
+			// lower one.
+			if _, ok := cm.encode[r]; r >= 0 && !ok {
+				cm.encode[r] = byte(b)
+			}
+		}
+	})
+
+	b, ok := cm.encode[r]
+	return b, ok
+}
+
+var _ BulkDecoder = &CharmapDecoder{}
+
+// CharmapDecoder implements Decoder for single-byte encodings such as
+// ISO-8859-2, where every character is one byte. Use GetDecoder to get one
+// for a particular encoding.
+type CharmapDecoder struct {
+	charmap *charmap
+}
+
+// Decode satisfies the Decoder interface for single-byte encodings.
+func (d *CharmapDecoder) Decode(r io.Reader) (rune, error) {
+	buf := make([]byte, 1)
+	_, err := io.ReadFull(r, buf)
+	if err != nil {
+		return 0, err
+	}
+
+	char := d.charmap.decode[buf[0]]
+	if char < 0 {
+		return 0, &DecodeError{Encoding: d.charmap.name, Bytes: buf, Err: ErrInvalidSequence}
+	}
+	return char, nil
+}
+
+// BulkDecoder satisfies the BulkDecoder interface for single-byte encodings.
+func (d *CharmapDecoder) BulkDecoder() BulkDecoder {
+	return d
+}
+
+// DecodeBytes satisfies the BulkDecoder interface for single-byte encodings.
+func (d *CharmapDecoder) DecodeBytes(dst []rune, src []byte, atEOF bool) (nDst, nSrc int, err error) {
+	for ; nSrc < len(src); nSrc++ {
+		if nDst == len(dst) {
+			return nDst, nSrc, ErrShortDst
+		}
+
+		char := d.charmap.decode[src[nSrc]]
+		if char < 0 {
+			return nDst, nSrc, &DecodeError{Encoding: d.charmap.name, Bytes: []byte{src[nSrc]}, Err: ErrInvalidSequence}
+		}
+		dst[nDst] = char
+		nDst++
+	}
+	return nDst, nSrc, nil
+}
+
+var _ BulkEncoder = &CharmapEncoder{}
+
+// CharmapEncoder implements Encoder for single-byte encodings such as
+// ISO-8859-2, where every character is one byte. Use GetEncoder to get one
+// for a particular encoding.
+type CharmapEncoder struct {
+	charmap *charmap
+}
+
+// Encode satisfies the Encoder interface for single-byte encodings.
+func (e *CharmapEncoder) Encode(w io.Writer, r rune) error {
+	b, ok := e.charmap.lookup(r)
+	if !ok {
+		return &EncodeError{Encoding: e.charmap.name, Rune: r, Err: ErrOutOfRange}
+	}
+
+	_, err := w.Write([]byte{b})
+	return err
+}
+
+// BulkEncoder satisfies the BulkEncoder interface for single-byte encodings.
+func (e *CharmapEncoder) BulkEncoder() BulkEncoder {
+	return e
+}
+
+// EncodeRunes satisfies the BulkEncoder interface for single-byte encodings.
+func (e *CharmapEncoder) EncodeRunes(dst []byte, src []rune) (nDst, nSrc int, err error) {
+	for ; nSrc < len(src); nSrc++ {
+		b, ok := e.charmap.lookup(src[nSrc])
+		if !ok {
+			return nDst, nSrc, &EncodeError{Encoding: e.charmap.name, Rune: src[nSrc], Err: ErrOutOfRange}
+		}
+		if nDst == len(dst) {
+			return nDst, nSrc, ErrShortDst
+		}
+		dst[nDst] = b
+		nDst++
+	}
+	return nDst, nSrc, nil
+}
@@ -0,0 +1,364 @@
+package codec
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// mappingSource is a generated table and the mapping file it was generated
+// from, relative to the mappings directory.
+type mappingSource struct {
+	table string
+	file  string
+}
+
+// charmapMappings lists the table and mapping file each single-byte codec was
+// generated from.
+var charmapMappings = map[string]mappingSource{
+	"ISO-8859-1":      {"iso8859_1", "ISO8859/8859-1.TXT"},
+	"ISO-8859-2":      {"iso8859_2", "ISO8859/8859-2.TXT"},
+	"ISO-8859-3":      {"iso8859_3", "ISO8859/8859-3.TXT"},
+	"ISO-8859-4":      {"iso8859_4", "ISO8859/8859-4.TXT"},
+	"ISO-8859-5":      {"iso8859_5", "ISO8859/8859-5.TXT"},
+	"ISO-8859-6":      {"iso8859_6", "ISO8859/8859-6.TXT"},
+	"ISO-8859-7":      {"iso8859_7", "ISO8859/8859-7.TXT"},
+	"ISO-8859-8":      {"iso8859_8", "ISO8859/8859-8.TXT"},
+	"ISO-8859-9":      {"iso8859_9", "ISO8859/8859-9.TXT"},
+	"ISO-8859-10":     {"iso8859_10", "ISO8859/8859-10.TXT"},
+	"ISO-8859-11":     {"iso8859_11", "ISO8859/8859-11.TXT"},
+	"ISO-8859-13":     {"iso8859_13", "ISO8859/8859-13.TXT"},
+	"ISO-8859-14":     {"iso8859_14", "ISO8859/8859-14.TXT"},
+	"ISO-8859-15":     {"iso8859_15", "ISO8859/8859-15.TXT"},
+	"ISO-8859-16":     {"iso8859_16", "ISO8859/8859-16.TXT"},
+	"KOI8-R":          {"koi8r", "VENDORS/MISC/KOI8-R.TXT"},
+	"KOI8-U":          {"koi8u", "VENDORS/MISC/KOI8-U.TXT"},
+	"KOI8-RU":         {"koi8ru", "VENDORS/MISC/KOI8-U.TXT"},
+	"macintosh":       {"macRoman", "VENDORS/APPLE/ROMAN.TXT"},
+	"x-mac-ce":        {"macCentralEurRoman", "VENDORS/APPLE/CENTEURO.TXT"},
+	"x-mac-greek":     {"macGreek", "VENDORS/APPLE/GREEK.TXT"},
+	"x-mac-icelandic": {"macIcelandic", "VENDORS/APPLE/ICELAND.TXT"},
+	"x-mac-turkish":   {"macTurkish", "VENDORS/APPLE/TURKISH.TXT"},
+	"x-mac-symbol":    {"macSymbol", "VENDORS/APPLE/SYMBOL.TXT"},
+	"x-mac-cyrillic":  {"macCyrillic", "VENDORS/APPLE/CYRILLIC.TXT"},
+	"IBM037":          {"ibm037", "VENDORS/MICSFT/EBCDIC/CP037.TXT"},
+	"IBM273":          {"ibm273", "VENDORS/IBM/CP273.TXT"},
+	"IBM500":          {"ibm500", "VENDORS/MICSFT/EBCDIC/CP500.TXT"},
+	"IBM875":          {"ibm875", "VENDORS/MICSFT/EBCDIC/CP875.TXT"},
+	"IBM1026":         {"ibm1026", "VENDORS/MICSFT/EBCDIC/CP1026.TXT"},
+	"IBM1047":         {"ibm1047", "VENDORS/MICSFT/EBCDIC/CP037.TXT"},
+	"IBM01140":        {"ibm1140", "VENDORS/IBM/CP1140.TXT"},
+	"IBM01141":        {"ibm1141", "VENDORS/IBM/CP273.TXT"},
+	"IBM01148":        {"ibm1148", "VENDORS/MICSFT/EBCDIC/CP500.TXT"},
+	"windows-874":     {"windows874", "VENDORS/MICSFT/WINDOWS/CP874.TXT"},
+	"windows-1250":    {"windows1250", "VENDORS/MICSFT/WINDOWS/CP1250.TXT"},
+	"windows-1251":    {"windows1251", "VENDORS/MICSFT/WINDOWS/CP1251.TXT"},
+	"windows-1252":    {"windows1252", "VENDORS/MICSFT/WINDOWS/CP1252.TXT"},
+	"windows-1253":    {"windows1253", "VENDORS/MICSFT/WINDOWS/CP1253.TXT"},
+	"windows-1254":    {"windows1254", "VENDORS/MICSFT/WINDOWS/CP1254.TXT"},
+	"windows-1255":    {"windows1255", "VENDORS/MICSFT/WINDOWS/CP1255.TXT"},
+	"windows-1256":    {"windows1256", "VENDORS/MICSFT/WINDOWS/CP1256.TXT"},
+	"windows-1257":    {"windows1257", "VENDORS/MICSFT/WINDOWS/CP1257.TXT"},
+	"windows-1258":    {"windows1258", "VENDORS/MICSFT/WINDOWS/CP1258.TXT"},
+	"IBM437":          {"ibm437", "VENDORS/MICSFT/PC/CP437.TXT"},
+	"IBM737":          {"ibm737", "VENDORS/MICSFT/PC/CP737.TXT"},
+	"IBM775":          {"ibm775", "VENDORS/MICSFT/PC/CP775.TXT"},
+	"IBM850":          {"ibm850", "VENDORS/MICSFT/PC/CP850.TXT"},
+	"IBM852":          {"ibm852", "VENDORS/MICSFT/PC/CP852.TXT"},
+	"IBM855":          {"ibm855", "VENDORS/MICSFT/PC/CP855.TXT"},
+	"IBM857":          {"ibm857", "VENDORS/MICSFT/PC/CP857.TXT"},
+	"IBM00858":        {"ibm858", "VENDORS/MICSFT/PC/CP858.TXT"},
+	"IBM860":          {"ibm860", "VENDORS/MICSFT/PC/CP860.TXT"},
+	"IBM861":          {"ibm861", "VENDORS/MICSFT/PC/CP861.TXT"},
+	"IBM862":          {"ibm862", "VENDORS/MICSFT/PC/CP862.TXT"},
+	"IBM863":          {"ibm863", "VENDORS/MICSFT/PC/CP863.TXT"},
+	"IBM864":          {"ibm864", "VENDORS/MICSFT/PC/CP864.TXT"},
+	"IBM865":          {"ibm865", "VENDORS/MICSFT/PC/CP865.TXT"},
+	"IBM866":          {"ibm866", "VENDORS/MICSFT/PC/CP866.TXT"},
+	"IBM869":          {"ibm869", "VENDORS/MICSFT/PC/CP869.TXT"},
+}
+
+// TestCharmapMappings checks each single-byte codec against its mapping file.
+// Every listed byte must decode to the listed character and back, and bytes
+// that aren't listed must be decode errors. Characters listed more than once
+// encode to the first byte.
+func TestCharmapMappings(t *testing.T) {
+	for name, src := range charmapMappings {
+		mapping, err := readMapping(src)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+
+		c := Lookup(name)
+		if c == nil {
+			t.Errorf("%s is not registered", name)
+			continue
+		}
+		decoder, encoder := c.NewDecoder(), c.NewEncoder()
+
+		first := map[rune]byte{}
+		for i := 255; i >= 0; i-- {
+			if r, ok := mapping[byte(i)]; ok {
+				first[r] = byte(i)
+			}
+		}
+
+		for i := 0; i < 256; i++ {
+			expected, ok := mapping[byte(i)]
+			char, err := decoder.Decode(bytes.NewReader([]byte{byte(i)}))
+			if !ok {
+				if !errors.Is(err, ErrInvalidSequence) {
+					t.Errorf("%s: 0x%02x: got %U, %v, want %v", name, i, char, err, ErrInvalidSequence)
+				}
+				continue
+			}
+			if err != nil || char != expected {
+				t.Errorf("%s: 0x%02x: got %U, %v, want %U", name, i, char, err, expected)
+				continue
+			}
+
+			actual := &bytes.Buffer{}
+			err = encoder.Encode(actual, char)
+			if err != nil || !bytes.Equal(actual.Bytes(), []byte{first[char]}) {
+				t.Errorf("%s: %U: got % x, %v, want %02x", name, char, actual.Bytes(), err, first[char])
+			}
+		}
+	}
+}
+
+// knownCode is a character and its encoding, taken from the standard or the
+// vendor's published table rather than from the mapping files.
+type knownCode struct {
+	encoding string
+	encoded  []byte
+	char     rune
+}
+
+// checkKnownCodes checks that each code decodes to its character and the
+// character encodes back to the code. The mapping tests only compare the
+// tables with the files they were generated from, so this catches a mapping
+// file that is wrong.
+func checkKnownCodes(t *testing.T, cases []knownCode) {
+	t.Helper()
+
+	for _, c := range cases {
+		in := bytes.NewReader(c.encoded)
+		char, err := GetDecoder(c.encoding).Decode(in)
+		if err != nil || char != c.char || in.Len() != 0 {
+			t.Errorf("%s: % x: got %U, %v, want %U", c.encoding, c.encoded, char, err, c.char)
+		}
+
+		encoder := GetEncoder(c.encoding)
+		actual := &bytes.Buffer{}
+		err = encoder.Encode(actual, c.char)
+		if f, ok := encoder.(Flusher); ok && err == nil {
+			err = f.Flush(actual)
+		}
+		if err != nil || !bytes.Equal(actual.Bytes(), c.encoded) {
+			t.Errorf("%s: %U: got % x, %v, want % x", c.encoding, c.char, actual.Bytes(), err, c.encoded)
+		}
+	}
+}
+
+func TestCharmapKnownCodes(t *testing.T) {
+	checkKnownCodes(t, []knownCode{
+		{"ISO-8859-1", []byte{0xe9}, 'é'},
+		{"ISO-8859-2", []byte{0xa1}, 'Ą'},
+		{"ISO-8859-5", []byte{0xb0}, 'А'},
+		{"ISO-8859-7", []byte{0xc1}, 'Α'},
+		{"ISO-8859-15", []byte{0xa4}, '€'},
+		{"ISO-8859-16", []byte{0xa4}, '€'},
+		{"KOI8-R", []byte{0xc1}, 'а'},
+		{"KOI8-R", []byte{0xe1}, 'А'},
+		{"KOI8-R", []byte{0xa3}, 'ё'},
+		{"KOI8-U", []byte{0xa4}, 'є'},
+		{"KOI8-RU", []byte{0xae}, 'ў'},
+		{"macintosh", []byte{0x80}, 'Ä'},
+		{"macintosh", []byte{0xdb}, '€'},
+		{"IBM037", []byte{0xc1}, 'A'},
+		{"IBM037", []byte{0x4a}, '¢'},
+		{"IBM500", []byte{0x4a}, '['},
+		{"IBM1047", []byte{0xad}, '['},
+		{"IBM01140", []byte{0x9f}, '€'},
+		{"IBM437", []byte{0x80}, 'Ç'},
+		{"IBM437", []byte{0xb0}, '░'},
+		{"IBM866", []byte{0x80}, 'А'},
+		{"windows-874", []byte{0xa1}, 'ก'},
+		{"windows-1250", []byte{0x8a}, 'Š'},
+		{"windows-1250", []byte{0xb9}, 'ą'},
+		{"windows-1251", []byte{0x88}, '€'},
+		{"windows-1251", []byte{0xa8}, 'Ё'},
+		{"windows-1251", []byte{0xc0}, 'А'},
+		{"windows-1252", []byte{0x80}, '€'},
+		{"windows-1252", []byte{0x9f}, 'Ÿ'},
+		{"windows-1253", []byte{0xc1}, 'Α'},
+		{"windows-1254", []byte{0xd0}, 'Ğ'},
+		{"windows-1255", []byte{0xe0}, 'א'},
+		{"windows-1256", []byte{0xc7}, 'ا'},
+		{"windows-1257", []byte{0xc0}, 'Ą'},
+		{"windows-1258", []byte{0xd5}, 'Ơ'},
+	})
+}
+
+// readMapping reads a mapping file in the format gen_charmap.go uses, with the
+// overrides for the table applied.
+func readMapping(src mappingSource) (map[byte]rune, error) {
+	f, err := os.Open(filepath.Join("mappings", src.file))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	mapping := map[byte]rune{}
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		fields := strings.Fields(strings.SplitN(s.Text(), "#", 2)[0])
+		if len(fields) != 2 {
+			continue
+		}
+		b, err := strconv.ParseUint(fields[0], 0, 8)
+		if err != nil {
+			return nil, err
+		}
+		r, err := strconv.ParseUint(fields[1], 0, 32)
+		if err != nil {
+			return nil, err
+		}
+		mapping[byte(b)] = rune(r)
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+
+	overrides, err := readOverrides(src.table)
+	if err != nil {
+		return nil, err
+	}
+	for code, r := range overrides {
+		if r == -1 {
+			delete(mapping, code[0])
+		} else {
+			mapping[code[0]] = r
+		}
+	}
+	return mapping, nil
+}
+
+// readOverrides reads the lines of mappings/OVERRIDES.TXT for a table. The keys
+// are the encoded bytes, and undefined codes map to -1.
+func readOverrides(table string) (map[string]rune, error) {
+	f, err := os.Open(filepath.Join("mappings", "OVERRIDES.TXT"))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	overrides := map[string]rune{}
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		fields := strings.Fields(strings.SplitN(s.Text(), "#", 2)[0])
+		if len(fields) < 2 || fields[0] != table {
+			continue
+		}
+		code, err := mappingCode(fields[1])
+		if err != nil {
+			return nil, err
+		}
+		overrides[code] = -1
+		if len(fields) == 3 {
+			r, err := strconv.ParseUint(fields[2], 0, 32)
+			if err != nil {
+				return nil, err
+			}
+			overrides[code] = rune(r)
+		}
+	}
+	return overrides, s.Err()
+}
+
+// mappingCode returns the bytes for a code in a mapping file. The number of
+// digits gives the length of the code.
+func mappingCode(field string) (string, error) {
+	code, err := strconv.ParseUint(field, 0, 16)
+	if err != nil {
+		return "", err
+	}
+	if len(field) <= 4 {
+		return string([]byte{byte(code)}), nil
+	}
+	return string([]byte{byte(code >> 8), byte(code)}), nil
+}
+
+// TestCharmapRoundTrip decodes every byte with every single-byte codec and
+// checks the character encodes back to the same byte.
+func TestCharmapRoundTrip(t *testing.T) {
+	for _, name := range Names() {
+		c := Lookup(name)
+		decoder, ok := c.NewDecoder().(*CharmapDecoder)
+		if !ok {
+			continue
+		}
+		encoder := c.NewEncoder()
+
+		decoded := map[rune]byte{}
+		for i := 0; i < 256; i++ {
+			in := []byte{byte(i)}
+			char, err := decoder.Decode(bytes.NewReader(in))
+			if err != nil {
+				if !errors.Is(err, ErrInvalidSequence) {
+					t.Errorf("%s: 0x%02x: got error %v, want %v", name, i, err, ErrInvalidSequence)
+				}
+				continue
+			}
+
+			expected := in
+			if b, ok := decoded[char]; ok {
+				// Encoders use the first byte for characters
+				// that have more than one.
+				expected = []byte{b}
+			} else {
+				decoded[char] = byte(i)
+			}
+
+			actual := &bytes.Buffer{}
+			err = encoder.Encode(actual, char)
+			if err != nil {
+				t.Errorf("%s: %U: encode error: %v", name, char, err)
+				continue
+			}
+			if !bytes.Equal(actual.Bytes(), expected) {
+				t.Errorf("%s: %U: got % x, want % x", name, char, actual.Bytes(), expected)
+			}
+		}
+
+		info := c.Info
+		if info.MinBytes != 1 || info.MaxBytes != 1 {
+			t.Errorf("%s: got %d-%d bytes, want 1", name, info.MinBytes, info.MaxBytes)
+		}
+	}
+}
+
+func TestCharmapErrors(t *testing.T) {
+	var de *DecodeError
+	_, err := GetDecoder("ISO-8859-3").Decode(bytes.NewReader([]byte{0xa5}))
+	if !errors.As(err, &de) || de.Err != ErrInvalidSequence || de.Encoding != "ISO-8859-3" || !bytes.Equal(de.Bytes, []byte{0xa5}) {
+		t.Errorf("got %v, want an invalid sequence DecodeError", err)
+	}
+
+	var ee *EncodeError
+	err = GetEncoder("ISO-8859-1").Encode(&bytes.Buffer{}, '€')
+	if !errors.As(err, &ee) || ee.Err != ErrOutOfRange || ee.Encoding != "ISO-8859-1" || ee.Rune != '€' {
+		t.Errorf("got %v, want an out of range EncodeError", err)
+	}
+
+	err = GetEncoder("ISO-8859-1").Encode(&bytes.Buffer{}, -1)
+	if !errors.As(err, &ee) || ee.Err != ErrOutOfRange {
+		t.Errorf("got %v, want an out of range EncodeError", err)
+	}
+}
@@ -0,0 +1,69 @@
+package codec
+
+import "fmt"
+
+//go:generate go run gen_dbcs.go -o charsets_tables.go -overrides mappings/OVERRIDES.TXT jis0208=mappings/OBSOLETE/EASTASIA/JIS/JIS0208.TXT jis0212=mappings/OBSOLETE/EASTASIA/JIS/JIS0212.TXT gb2312=mappings/OBSOLETE/EASTASIA/GB/GB2312.TXT ksc5601=mappings/OBSOLETE/EASTASIA/KSC/KSC5601.TXT
+
+// The East Asian national character sets are arranged in 94 rows of 94
+// characters, numbered from 0x21 to 0x7E. ISO-2022 encodings write a
+// character as its row and column bytes, EUC encodings set the high bit of
+// both. The tables are indexed by the 7-bit form, and lookup returns it.
+var (
+	jis0208Set = &dbcs{name: "JIS X 0208", table: &jis0208}
+	jis0212Set = &dbcs{name: "JIS X 0212", table: &jis0212}
+	gb2312Set  = &dbcs{name: "GB 2312", table: &gb2312}
+	ksc5601Set = &dbcs{name: "KS X 1001", table: &ksc5601}
+)
+
+// charsetInfo returns Info for an encoding of ASCII, extra and the characters
+// in sets, where a character takes up to maxBytes bytes.
+func charsetInfo(maxBytes int, extra []rune, sets ...*dbcs) Info {
+	chars := map[rune]bool{}
+	for r := rune(0); r < 0x80; r++ {
+		chars[r] = true
+	}
+	for _, r := range extra {
+		chars[r] = true
+	}
+	for _, set := range sets {
+		for _, r := range set.table.double {
+			if r != 0 {
+				chars[r] = true
+			}
+		}
+	}
+
+	var maxRune rune
+	for r := range chars {
+		if r > maxRune {
+			maxRune = r
+		}
+	}
+
+	return Info{
+		MaxRune:    maxRune,
+		Repertoire: fmt.Sprintf("%d characters", len(chars)),
+		MinBytes:   1,
+		MaxBytes:   maxBytes,
+	}
+}
+
+// eucTable returns the table for the EUC form of a 94x94 set, which has ASCII
+// in the single bytes and sets the high bit of both bytes of the set's codes.
+// It shares the set's characters.
+func eucTable(set *dbcsTable) *dbcsTable {
+	t := &dbcsTable{
+		leadMin:  set.leadMin | 0x80,
+		leadMax:  set.leadMax | 0x80,
+		trailMin: set.trailMin | 0x80,
+		trailMax: set.trailMax | 0x80,
+		double:   set.double,
+	}
+	for i := range t.single {
+		t.single[i] = -1
+		if i < 0x80 {
+			t.single[i] = rune(i)
+		}
+	}
+	return t
+}
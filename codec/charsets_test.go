@@ -0,0 +1,62 @@
+package codec
+
+import "testing"
+
+// TestCharsetMappings checks each 94x94 character set against its mapping
+// file. Every listed code must give the listed character, and the character
+// must look up to a code that gives it back.
+func TestCharsetMappings(t *testing.T) {
+	cases := []struct {
+		set *dbcs
+		src mappingSource
+	}{
+		{jis0208Set, mappingSource{"jis0208", "OBSOLETE/EASTASIA/JIS/JIS0208.TXT"}},
+		{jis0212Set, mappingSource{"jis0212", "OBSOLETE/EASTASIA/JIS/JIS0212.TXT"}},
+		{gb2312Set, mappingSource{"gb2312", "OBSOLETE/EASTASIA/GB/GB2312.TXT"}},
+		{ksc5601Set, mappingSource{"ksc5601", "OBSOLETE/EASTASIA/KSC/KSC5601.TXT"}},
+	}
+
+	for _, c := range cases {
+		mapping, err := readDBCSMapping(c.src)
+		if err != nil {
+			t.Errorf("%s: %v", c.set.name, err)
+			continue
+		}
+
+		for code, expected := range mapping {
+			if len(code) != 2 || code[0] < 0x21 || code[0] > 0x7e || code[1] < 0x21 || code[1] > 0x7e {
+				t.Errorf("%s: % x isn't a 94x94 code", c.set.name, code)
+				continue
+			}
+
+			char := c.set.table.char(code[0], code[1])
+			if char != expected {
+				t.Errorf("%s: % x: got %U, want %U", c.set.name, code, char, expected)
+				continue
+			}
+
+			actual, ok := c.set.lookup(char)
+			if !ok || mapping[string([]byte{byte(actual >> 8), byte(actual)})] != char {
+				t.Errorf("%s: %U: got %04x, %v", c.set.name, char, actual, ok)
+			}
+		}
+
+		// Codes outside the table are undefined.
+		for _, code := range [][2]byte{{0x20, 0x21}, {0x21, 0x7f}, {0x7f, 0x7f}} {
+			if char := c.set.table.char(code[0], code[1]); char != 0 {
+				t.Errorf("%s: % x: got %U, want nothing", c.set.name, code, char)
+			}
+		}
+	}
+}
+
+func TestCharsetKnownCodes(t *testing.T) {
+	checkKnownCodes(t, []knownCode{
+		{"EUC-JP", []byte{0xa4, 0xa2}, 'あ'},
+		{"EUC-JP", []byte{0x8e, 0xb1}, 'ｱ'},
+		{"EUC-JP", []byte{0x8f, 0xb0, 0xa1}, '丂'},
+		{"GB2312", []byte{0xb0, 0xa1}, '啊'},
+		{"EUC-KR", []byte{0xb0, 0xa1}, '가'},
+		{"EUC-KR", []byte{0xa4, 0xd4}, '\u3164'},
+	})
+}
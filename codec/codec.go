@@ -2,8 +2,11 @@ package codec
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
 	"io"
+	"sort"
+	"strings"
 	"sync"
 )
 
@@ -12,80 +15,718 @@ type Decoder interface {
 	Decode(r io.Reader) (rune, error)
 }
 
+// SequenceDecoder is implemented by decoders where some input decodes to more
+// than one rune, such as the Big5-HKSCS codes for a letter with a combining
+// mark.
+//
+// DecodeSequence returns all the runes for the next character, at least one.
+// Decode returns the first of them, then the rest on the following calls.
+// Recode, Reader and Writer use DecodeSequence if it's available, so an error
+// in any of the runes is reported at the position of the input they came
+// from.
+type SequenceDecoder interface {
+	Decoder
+	DecodeSequence(r io.Reader) ([]rune, error)
+}
+
 // Encoder writes an encoded Unicode code point to the writer.
 type Encoder interface {
 	Encode(io.Writer, rune) error
 }
 
-type registeredCodec struct {
-	initDecoder func() Decoder
-	initEncoder func() Encoder
+// Flusher is implemented by encoders that keep state between characters and
+// have to write something after the last one, such as the escape sequence that
+// returns ISO-2022-JP to ASCII.
+//
+// Recode, Reader and Writer call Flush when the input ends without an error.
+// Code that calls Encode directly must call Flush itself. Afterwards the
+// encoder starts again from its initial state.
+type Flusher interface {
+	Flush(io.Writer) error
+}
+
+// Codec describes a registered character encoding.
+type Codec struct {
+	// Name is the canonical name of the encoding.
+	Name string
+
+	// Aliases are other names the encoding can be looked up by.
+	Aliases []string
+
+	// NewDecoder returns a new Decoder for the encoding, or is nil if the
+	// encoding can't be decoded.
+	NewDecoder func(...Options) Decoder
+
+	// NewEncoder returns a new Encoder for the encoding, or is nil if the
+	// encoding can't be encoded.
+	NewEncoder func(...Options) Encoder
+
+	// Info describes the encoding. It's the zero value if the codec was
+	// registered without it.
+	Info Info
+}
+
+// Info describes what an encoding can represent and how.
+type Info struct {
+	// MaxRune is the highest code point the encoding can represent.
+	MaxRune rune
+
+	// Repertoire describes the characters the encoding can represent if
+	// it's not every code point up to MaxRune.
+	Repertoire string
+
+	// MinBytes and MaxBytes are the fewest and most bytes used to encode
+	// a character.
+	MinBytes int
+	MaxBytes int
+
+	// ReadsBOM is true if the decoder removes a byte order mark from the
+	// start of the input by default, using it to determine the byte order
+	// if needed.
+	ReadsBOM bool
+
+	// WritesBOM is true if the encoder writes a byte order mark before the
+	// first character by default.
+	WritesBOM bool
+}
+
+// BOMPolicy determines how a byte order mark (U+FEFF) at the start of the
+// text is handled.
+type BOMPolicy int
+
+const (
+	// BOMAuto does what's usual for the encoding. Unicode decoders remove
+	// a byte order mark. The UTF-16BE, UTF-16LE, UCS-2BE and UCS-2LE
+	// encoders write one, other encoders don't. The WTF-16 encodings
+	// use BOMPreserve instead.
+	BOMAuto BOMPolicy = iota
+
+	// BOMAlways makes encoders write a byte order mark. Decoders remove
+	// one, the same as BOMAuto.
+	BOMAlways
+
+	// BOMNever makes encoders leave out the byte order mark, even if the
+	// text starts with U+FEFF. Decoders don't look for one, so U+FEFF at
+	// the start of the input is decoded as a character and the byte
+	// order is guessed if it isn't fixed.
+	BOMNever
+
+	// BOMPreserve keeps the byte order mark in the text. Decoders use it
+	// to determine the byte order, but return it as U+FEFF. Encoders
+	// don't add one, but encode U+FEFF at the start of the text as usual.
+	BOMPreserve
+)
+
+// bomRune is the byte order mark, also known as ZERO WIDTH NO-BREAK SPACE.
+const bomRune = 0xfeff
+
+// EBCDICNewline sets which byte EBCDIC encodings use for line breaks.
+type EBCDICNewline int
+
+const (
+	// EBCDICLineFeed follows the IBM code page tables: LF (0x25) decodes
+	// to U+000A and NL (0x15) decodes to NEXT LINE (U+0085).
+	EBCDICLineFeed EBCDICNewline = iota
+
+	// EBCDICNextLine swaps the two, so NL (0x15) decodes to U+000A and LF
+	// (0x25) to U+0085, and encoders do the reverse. z/OS UNIX System
+	// Services files usually end lines with NL.
+	EBCDICNextLine
+)
+
+// Options configures a Decoder or Encoder. Every constructor accepts them,
+// though encodings without a byte order mark ignore BOM, and each of the other
+// fields only applies to the encodings listed with it.
+type Options struct {
+	// BOM sets how a byte order mark is handled.
+	BOM BOMPolicy
+
+	// Newline sets the line terminator of the EBCDIC encodings: IBM037,
+	// IBM273, IBM500, IBM875, IBM1026, IBM1047, IBM01140, IBM01141 and
+	// IBM01148. Every other encoding ignores it.
+	Newline EBCDICNewline
+
+	// LoneSurrogates makes the UTF-16, UTF-16LE and UTF-16BE decoders
+	// return a surrogate that isn't part of a pair as a character rather
+	// than an error, and their encoders write one. That's sometimes called
+	// WTF-16, and it's what Windows file names and JavaScript strings can
+	// contain. The WTF-16 encodings always allow them, WTF-8 holds them in
+	// UTF-8, and every other encoding ignores this.
+	LoneSurrogates bool
+}
+
+// mergeOptions combines opts, later fields that aren't the zero value
+// override earlier ones.
+func mergeOptions(opts []Options) Options {
+	var o Options
+	for _, opt := range opts {
+		if opt.BOM != BOMAuto {
+			o.BOM = opt.BOM
+		}
+		if opt.Newline != EBCDICLineFeed {
+			o.Newline = opt.Newline
+		}
+		if opt.LoneSurrogates {
+			o.LoneSurrogates = true
+		}
+	}
+	return o
 }
 
+// ErrAlreadyRegistered is returned by Register when a name is already in use.
+var ErrAlreadyRegistered = errors.New("codec already registered")
+
 var (
 	codecRegistryMu sync.RWMutex
-	codecRegistry   = map[string]registeredCodec{}
+
+	// codecRegistry holds every codec under its name and each of its
+	// aliases, normalized with normalizeName.
+	codecRegistry = map[string]*Codec{}
 )
 
-func registerCodec(name string, initDecoder func() Decoder, initEncoder func() Encoder) {
+// Register adds a codec without any Info to the registry. It's a shorthand for
+// RegisterCodec, for codecs that don't take Options; any passed to GetDecoder
+// or GetEncoder are ignored. Use RegisterCodec for codecs that do.
+func Register(name string, aliases []string, newDecoder func() Decoder, newEncoder func() Encoder) error {
+	c := Codec{
+		Name:    name,
+		Aliases: aliases,
+	}
+	if newDecoder != nil {
+		c.NewDecoder = func(...Options) Decoder { return newDecoder() }
+	}
+	if newEncoder != nil {
+		c.NewEncoder = func(...Options) Encoder { return newEncoder() }
+	}
+	return RegisterCodec(c)
+}
+
+// RegisterCodec adds a codec to the registry, so it can be found by GetDecoder,
+// GetEncoder and Lookup under its name or any of its aliases. Names are
+// matched the same way as Lookup, so aliases that only differ in case or
+// punctuation aren't needed, and any that are given are dropped from Aliases.
+// Either NewDecoder or NewEncoder may be nil, but not both.
+//
+// Packages that provide encodings should call Register or RegisterCodec from an
+// init function. An error wrapping ErrAlreadyRegistered is returned if the name
+// or any of the aliases is already registered, in which case nothing is
+// registered.
+func RegisterCodec(c Codec) error {
+	if c.Name == "" {
+		return errors.New("codec name is empty")
+	}
+	if c.NewDecoder == nil && c.NewEncoder == nil {
+		return fmt.Errorf("codec %q has no decoder or encoder", c.Name)
+	}
+
 	codecRegistryMu.Lock()
 	defer codecRegistryMu.Unlock()
 
-	codecRegistry[name] = registeredCodec{
-		initDecoder: initDecoder,
-		initEncoder: initEncoder,
+	keys := map[string]bool{}
+	var aliases []string
+	for i, n := range append([]string{c.Name}, c.Aliases...) {
+		key := normalizeName(n)
+		if key == "" {
+			return fmt.Errorf("codec %q has an empty alias", c.Name)
+		}
+		if _, ok := codecRegistry[key]; ok {
+			return fmt.Errorf("%q: %w", n, ErrAlreadyRegistered)
+		}
+		if keys[key] {
+			continue
+		}
+		keys[key] = true
+		if i > 0 {
+			aliases = append(aliases, n)
+		}
+	}
+
+	c.Aliases = aliases
+	for key := range keys {
+		codecRegistry[key] = &c
+	}
+	return nil
+}
+
+// registerCodec registers one of the built-in codecs.
+func registerCodec(c Codec) {
+	err := RegisterCodec(c)
+	if err != nil {
+		panic(err)
 	}
 }
 
-// GetDecoder looks up a decoder by name. Returns nil if no decoder is found
-// with the given name.
-func GetDecoder(name string) Decoder {
+// Lookup returns the codec registered with the given name or alias. Returns
+// nil if no codec is found.
+//
+// Names are compared without regard to case or punctuation, so "UTF-8",
+// "utf8" and "Utf_8" all find the same codec.
+//
+// The built-in codecs are registered under their IANA names and aliases, the
+// WHATWG Encoding Standard labels and common platform names such as Windows
+// code page numbers. Where WHATWG deliberately maps a label to a different
+// encoding, such as "us-ascii" to windows-1252, the IANA meaning is used.
+func Lookup(name string) *Codec {
 	codecRegistryMu.RLock()
 	defer codecRegistryMu.RUnlock()
 
-	cr, ok := codecRegistry[name]
+	c, ok := codecRegistry[normalizeName(name)]
 	if !ok {
 		return nil
 	}
-	return cr.initDecoder()
+
+	cp := *c
+	cp.Aliases = append([]string(nil), c.Aliases...)
+	return &cp
 }
 
-// GetEncoder looks up an encoder by name. Returns nil if no encoder is found
-// with the given name.
-func GetEncoder(name string) Encoder {
+// Names returns the canonical name of every registered codec in sorted order.
+func Names() []string {
 	codecRegistryMu.RLock()
 	defer codecRegistryMu.RUnlock()
 
-	cr, ok := codecRegistry[name]
-	if !ok {
+	names := make([]string, 0, len(codecRegistry))
+	for key, c := range codecRegistry {
+		if key == normalizeName(c.Name) {
+			names = append(names, c.Name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// normalizeName folds name to lower case and drops everything but letters and
+// digits.
+func normalizeName(name string) string {
+	var sb strings.Builder
+	for _, c := range name {
+		switch {
+		case c >= 'a' && c <= 'z', c >= '0' && c <= '9':
+			sb.WriteRune(c)
+		case c >= 'A' && c <= 'Z':
+			sb.WriteRune(c + 'a' - 'A')
+		}
+	}
+	return sb.String()
+}
+
+// GetDecoder looks up a decoder by name or alias, the same as Lookup, and
+// creates it with opts. Returns nil if no decoder is found with the given name.
+func GetDecoder(name string, opts ...Options) Decoder {
+	c := Lookup(name)
+	if c == nil || c.NewDecoder == nil {
+		return nil
+	}
+	return c.NewDecoder(opts...)
+}
+
+// GetEncoder looks up an encoder by name or alias, the same as Lookup, and
+// creates it with opts. Returns nil if no encoder is found with the given name.
+func GetEncoder(name string, opts ...Options) Encoder {
+	c := Lookup(name)
+	if c == nil || c.NewEncoder == nil {
 		return nil
 	}
-	return cr.initEncoder()
+	return c.NewEncoder(opts...)
+}
+
+// ErrorPolicy determines what Recode does with input that can't be decoded and
+// characters that can't be encoded.
+type ErrorPolicy int
+
+const (
+	// Strict stops at the first error and returns it.
+	Strict ErrorPolicy = iota
+
+	// Replace writes U+FFFD in place of invalid input and a substitute
+	// character, '?' by default, in place of characters the encoder
+	// can't represent.
+	Replace
+
+	// Skip drops invalid input and characters the encoder can't
+	// represent.
+	Skip
+
+	// Escape writes each invalid input byte as \xNN and each character the
+	// encoder can't represent as \uXXXX, or \UXXXXXXXX above U+FFFF.
+	Escape
+
+	// EscapeXML is like Escape, except characters the encoder can't
+	// represent are written as XML character references (&#NNNN;).
+	EscapeXML
+)
+
+var errorPolicyNames = map[ErrorPolicy]string{
+	Strict:    "strict",
+	Replace:   "replace",
+	Skip:      "skip",
+	Escape:    "escape",
+	EscapeXML: "xml",
+}
+
+func (p ErrorPolicy) String() string {
+	name, ok := errorPolicyNames[p]
+	if !ok {
+		return fmt.Sprintf("ErrorPolicy(%d)", int(p))
+	}
+	return name
+}
+
+// ParseErrorPolicy returns the ErrorPolicy with the given name: "strict",
+// "replace", "skip" (or "ignore"), "escape" or "xml".
+func ParseErrorPolicy(name string) (ErrorPolicy, error) {
+	if name == "ignore" {
+		return Skip, nil
+	}
+
+	for p, n := range errorPolicyNames {
+		if n == name {
+			return p, nil
+		}
+	}
+	return Strict, fmt.Errorf("unknown error policy %q", name)
+}
+
+type recodeOptions struct {
+	decodePolicy ErrorPolicy
+	encodePolicy ErrorPolicy
+	substitute   rune
+	bom          BOMPolicy
+}
+
+// RecodeOption changes how Recode handles errors and byte order marks.
+type RecodeOption func(*recodeOptions)
+
+// WithErrorPolicy sets the policy for both decoding and encoding errors.
+func WithErrorPolicy(p ErrorPolicy) RecodeOption {
+	return func(o *recodeOptions) {
+		o.decodePolicy = p
+		o.encodePolicy = p
+	}
+}
+
+// WithDecodeErrorPolicy sets the policy for input that can't be decoded.
+func WithDecodeErrorPolicy(p ErrorPolicy) RecodeOption {
+	return func(o *recodeOptions) {
+		o.decodePolicy = p
+	}
+}
+
+// WithEncodeErrorPolicy sets the policy for characters that can't be encoded.
+func WithEncodeErrorPolicy(p ErrorPolicy) RecodeOption {
+	return func(o *recodeOptions) {
+		o.encodePolicy = p
+	}
+}
+
+// WithSubstitute sets the character written in place of characters that can't
+// be encoded under the Replace policy. The default is '?'.
+func WithSubstitute(r rune) RecodeOption {
+	return func(o *recodeOptions) {
+		o.substitute = r
+	}
+}
+
+// WithBOMPolicy sets how Recode treats U+FEFF at the start of the decoded
+// text. BOMAlways adds it if it's missing, BOMNever removes it, and BOMAuto and
+// BOMPreserve leave it alone. If the encoder can't represent U+FEFF, BOMAlways
+// adds nothing.
+//
+// This works on characters, after the decoder and before the encoder. Whether
+// the decoder removes a byte order mark from the input and whether the encoder
+// writes its own are set by the Options they're created with. An encoder that
+// writes a byte order mark doesn't write a second one for U+FEFF at the start
+// of the text.
+func WithBOMPolicy(p BOMPolicy) RecodeOption {
+	return func(o *recodeOptions) {
+		o.bom = p
+	}
 }
 
 // Recode decodes data from the reader with decoder, then writes it back out to
 // w with the encoder.
-func Recode(r io.Reader, w io.Writer, decoder Decoder, encoder Encoder) error {
+//
+// By default Recode stops at the first error. Use WithErrorPolicy to handle
+// errors in other ways. Invalid input is reported as a *DecodeError and
+// characters the encoder can't represent as an *EncodeError, both with their
+// position in the input filled in.
+func Recode(r io.Reader, w io.Writer, decoder Decoder, encoder Encoder, opts ...RecodeOption) error {
 	bw, ok := w.(*bufio.Writer)
 	if !ok {
 		bw = bufio.NewWriter(w)
 		defer bw.Flush()
 	}
 
-	for {
-		char, err := decoder.Decode(r)
+	rc := newRecoder(r, decoder, encoder, opts)
+
+	bulkDecoder, ok1 := asBulkDecoder(decoder)
+	bulkEncoder, ok2 := asBulkEncoder(encoder)
+	if ok1 && ok2 {
+		err := rc.recodeBulk(bw, bulkDecoder, bulkEncoder)
 		if err != nil {
-			if err != io.EOF {
-				return fmt.Errorf("error decoding character: %w", err)
+			return err
+		}
+	} else {
+		for {
+			err := rc.next(bw)
+			if err != nil {
+				if err != io.EOF {
+					return err
+				}
+				break
+			}
+		}
+	}
+
+	if err := rc.flush(bw); err != nil {
+		return err
+	}
+
+	if !ok {
+		return bw.Flush()
+	}
+	return nil
+}
+
+// buffered is implemented by decoders that read ahead of the character they
+// return. Buffered reports the number of bytes read but not yet decoded.
+type buffered interface {
+	Buffered() int
+}
+
+// countingReader counts the bytes read through it.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (cr *countingReader) Read(p []byte) (int, error) {
+	n, err := cr.r.Read(p)
+	cr.n += int64(n)
+	return n, err
+}
+
+// recoder moves characters from a Decoder to an Encoder one at a time,
+// applying the error policies and keeping track of the position in the input
+// for errors.
+type recoder struct {
+	r       *countingReader
+	decoder Decoder
+	encoder Encoder
+	opts    recodeOptions
+	pos     Position
+	started bool
+
+	// char holds the rune decode returns, to save allocating a slice.
+	char [1]rune
+}
+
+func newRecoder(r io.Reader, decoder Decoder, encoder Encoder, opts []RecodeOption) *recoder {
+	rc := &recoder{
+		r:       &countingReader{r: r},
+		decoder: decoder,
+		encoder: encoder,
+		opts: recodeOptions{
+			substitute: '?',
+		},
+		pos: Position{
+			Line:   1,
+			Column: 1,
+		},
+	}
+	for _, opt := range opts {
+		opt(&rc.opts)
+	}
+	return rc
+}
+
+// offset returns the number of bytes the decoder has consumed.
+func (rc *recoder) offset() int64 {
+	n := rc.r.n
+	if b, ok := rc.decoder.(buffered); ok {
+		n -= int64(b.Buffered())
+	}
+	return n
+}
+
+// advance moves the position past char.
+func (rc *recoder) advance(char rune) {
+	rc.pos.Index++
+	if char == '\n' {
+		rc.pos.Line++
+		rc.pos.Column = 1
+	} else {
+		rc.pos.Column++
+	}
+}
+
+// next recodes one character to w. It returns io.EOF at the end of the input.
+func (rc *recoder) next(w io.Writer) error {
+	pos := rc.pos
+	pos.Offset = rc.offset()
+
+	chars, err := rc.decode()
+	if err != nil {
+		if err == io.EOF {
+			return err
+		}
+
+		var de *DecodeError
+		if !errors.As(err, &de) {
+			return fmt.Errorf("error decoding character: %w", err)
+		}
+
+		// The decoder may have skipped a byte order mark before it
+		// found the error, so work back from the end of the bytes it
+		// consumed.
+		de.Position = pos
+		de.Offset = rc.offset() - int64(len(de.Bytes))
+		if err := rc.start(w, 0xfffd); err != nil {
+			return err
+		}
+		rc.advance(0xfffd)
+		return rc.decodeError(w, de)
+	}
+
+	for _, char := range chars {
+		if !rc.started && char == bomRune && rc.opts.bom == BOMNever {
+			rc.started = true
+			rc.advance(char)
+			continue
+		}
+		if err := rc.start(w, char); err != nil {
+			return err
+		}
+
+		// Every rune in a sequence has the offset of the input it
+		// came from.
+		charPos := rc.pos
+		charPos.Offset = pos.Offset
+		rc.advance(char)
+		if err := rc.encode(w, char, charPos); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decode reads the next character from the decoder, which is more than one
+// rune if the decoder is a SequenceDecoder.
+func (rc *recoder) decode() ([]rune, error) {
+	if sd, ok := rc.decoder.(SequenceDecoder); ok {
+		return sd.DecodeSequence(rc.r)
+	}
+
+	char, err := rc.decoder.Decode(rc.r)
+	if err != nil {
+		return nil, err
+	}
+	rc.char[0] = char
+	return rc.char[:], nil
+}
+
+// flush calls the encoder's Flush method, if it has one, at the end of the
+// input.
+func (rc *recoder) flush(w io.Writer) error {
+	f, ok := rc.encoder.(Flusher)
+	if !ok {
+		return nil
+	}
+	return f.Flush(w)
+}
+
+// start applies the BOMAlways policy before the first character, char, is
+// encoded.
+func (rc *recoder) start(w io.Writer, char rune) error {
+	if rc.started {
+		return nil
+	}
+	rc.started = true
+
+	if rc.opts.bom != BOMAlways || char == bomRune {
+		return nil
+	}
+
+	err := rc.encoder.Encode(w, bomRune)
+	var ee *EncodeError
+	if errors.As(err, &ee) {
+		return nil
+	}
+	return err
+}
+
+// decodeError applies the decoding error policy to de, which has its position
+// filled in.
+func (rc *recoder) decodeError(w io.Writer, de *DecodeError) error {
+	switch rc.opts.decodePolicy {
+	case Replace:
+		return rc.encode(w, 0xfffd, de.Position)
+	case Skip:
+		return nil
+	case Escape, EscapeXML:
+		// There's no XML syntax for a raw byte, so both escape the
+		// same way.
+		for _, b := range de.Bytes {
+			err := rc.encodeString(w, fmt.Sprintf(`\x%02x`, b), de.Position)
+			if err != nil {
+				return err
 			}
-			break
 		}
+		return nil
+	default:
+		return de
+	}
+}
+
+// encode writes char to w, applying the encoding error policy if the encoder
+// can't represent it. pos is used for errors.
+func (rc *recoder) encode(w io.Writer, char rune, pos Position) error {
+	err := rc.encoder.Encode(w, char)
+	if err == nil {
+		return nil
+	}
+
+	var ee *EncodeError
+	if !errors.As(err, &ee) {
+		return fmt.Errorf("error encoding character (0x%x): %w", char, err)
+	}
+	return rc.encodeError(w, ee, char, pos)
+}
+
+// encodeError applies the encoding error policy to ee, which was returned for
+// char at pos.
+func (rc *recoder) encodeError(w io.Writer, ee *EncodeError, char rune, pos Position) error {
+	ee.Position = pos
+
+	switch rc.opts.encodePolicy {
+	case Replace:
+		return rc.encodeString(w, string(rc.opts.substitute), pos)
+	case Skip:
+		return nil
+	case Escape:
+		if char > 0xffff {
+			return rc.encodeString(w, fmt.Sprintf(`\U%08x`, char), pos)
+		}
+		return rc.encodeString(w, fmt.Sprintf(`\u%04x`, char), pos)
+	case EscapeXML:
+		return rc.encodeString(w, fmt.Sprintf("&#%d;", char), pos)
+	default:
+		return ee
+	}
+}
 
-		err = encoder.Encode(bw, char)
+// encodeString writes s to w without applying any error policy.
+func (rc *recoder) encodeString(w io.Writer, s string, pos Position) error {
+	for _, char := range s {
+		err := rc.encoder.Encode(w, char)
 		if err != nil {
+			var ee *EncodeError
+			if errors.As(err, &ee) {
+				ee.Position = pos
+				return ee
+			}
 			return fmt.Errorf("error encoding character (0x%x): %w", char, err)
 		}
 	}
-
 	return nil
 }
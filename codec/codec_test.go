@@ -0,0 +1,621 @@
+package codec
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+)
+
+func TestRecodeErrorPolicy(t *testing.T) {
+	cases := []struct {
+		policy   ErrorPolicy
+		in       []byte
+		expected string
+	}{
+		{
+			policy:   Replace,
+			in:       []byte("a\xe2\x82b\xffc€"),
+			expected: "a�b�c€",
+		},
+		{
+			policy:   Skip,
+			in:       []byte("a\xe2\x82b\xffc€"),
+			expected: "abc€",
+		},
+		{
+			policy:   Escape,
+			in:       []byte("a\xe2\x82b\xffc€"),
+			expected: `a\xe2\x82b\xffc€`,
+		},
+	}
+
+	for _, c := range cases {
+		actual := &bytes.Buffer{}
+		err := Recode(bytes.NewReader(c.in), actual, NewUTF8Decoder(), NewUTF8Encoder(), WithErrorPolicy(c.policy))
+		if err != nil {
+			t.Errorf("%v: recode error: %v", c.policy, err)
+			continue
+		}
+
+		if actual.String() != c.expected {
+			t.Errorf("%v: got %q, want %q", c.policy, actual.String(), c.expected)
+		}
+	}
+}
+
+func TestRecodeEncodeErrorPolicy(t *testing.T) {
+	cases := []struct {
+		opts     []RecodeOption
+		expected string
+	}{
+		{
+			opts:     []RecodeOption{WithErrorPolicy(Replace)},
+			expected: "caf? ?",
+		},
+		{
+			opts:     []RecodeOption{WithErrorPolicy(Replace), WithSubstitute('*')},
+			expected: "caf* *",
+		},
+		{
+			opts:     []RecodeOption{WithEncodeErrorPolicy(Skip)},
+			expected: "caf ",
+		},
+		{
+			opts:     []RecodeOption{WithErrorPolicy(Escape)},
+			expected: `caf\u00e9 \U0001f30e`,
+		},
+		{
+			opts:     []RecodeOption{WithErrorPolicy(EscapeXML)},
+			expected: "caf&#233; &#127758;",
+		},
+	}
+
+	for _, c := range cases {
+		actual := &bytes.Buffer{}
+		err := Recode(bytes.NewReader([]byte("café 🌎")), actual, NewUTF8Decoder(), NewASCIIEncoder(), c.opts...)
+		if err != nil {
+			t.Errorf("recode error: %v", err)
+			continue
+		}
+
+		if actual.String() != c.expected {
+			t.Errorf("got %q, want %q", actual.String(), c.expected)
+		}
+	}
+}
+
+func TestRecodeStrict(t *testing.T) {
+	actual := &bytes.Buffer{}
+	err := Recode(bytes.NewReader([]byte("ab\xffc")), actual, NewUTF8Decoder(), NewUTF8Encoder())
+	if err == nil {
+		t.Fatalf("got nil error, want decode error")
+	}
+
+	if actual.String() != "ab" {
+		t.Errorf("got %q, want %q", actual.String(), "ab")
+	}
+}
+
+func TestParseErrorPolicy(t *testing.T) {
+	for _, p := range []ErrorPolicy{Strict, Replace, Skip, Escape, EscapeXML} {
+		actual, err := ParseErrorPolicy(p.String())
+		if err != nil {
+			t.Errorf("%v: %v", p, err)
+			continue
+		}
+
+		if actual != p {
+			t.Errorf("got %v, want %v", actual, p)
+		}
+	}
+
+	_, err := ParseErrorPolicy("bogus")
+	if err == nil {
+		t.Errorf("got nil error for unknown policy")
+	}
+}
+
+func TestRecodeDecodeErrorPosition(t *testing.T) {
+	cases := []struct {
+		decoder  Decoder
+		in       []byte
+		expected DecodeError
+	}{
+		{
+			decoder: NewUTF8Decoder(),
+			in:      []byte("ab\ncd\xffe"),
+			expected: DecodeError{
+				Position: Position{Offset: 5, Index: 5, Line: 2, Column: 3},
+				Encoding: "UTF-8",
+				Bytes:    []byte{0xff},
+				Err:      ErrInvalidSequence,
+			},
+		},
+		{
+			decoder: NewUTF8Decoder(),
+			in:      []byte("€\n\xed\xa0\x80"),
+			expected: DecodeError{
+				Position: Position{Offset: 4, Index: 2, Line: 2, Column: 1},
+				Encoding: "UTF-8",
+				Bytes:    []byte{0xed, 0xa0, 0x80},
+				Err:      ErrLoneSurrogate,
+			},
+		},
+		{
+			// The offset includes the byte order mark
+			decoder: NewUTF16Decoder(),
+			in:      []byte{0xff, 0xfe, 0x48, 0x00, 0x3d, 0xd8},
+			expected: DecodeError{
+				Position: Position{Offset: 4, Index: 1, Line: 1, Column: 2},
+				Encoding: "UTF-16",
+				Bytes:    []byte{0x3d, 0xd8},
+				Err:      ErrTruncated,
+			},
+		},
+		{
+			decoder: NewUTF32BEDecoder(),
+			in:      []byte{0x00, 0x00, 0x00, 0x48, 0x00, 0x11, 0x00, 0x00},
+			expected: DecodeError{
+				Position: Position{Offset: 4, Index: 1, Line: 1, Column: 2},
+				Encoding: "UTF-32BE",
+				Bytes:    []byte{0x00, 0x11, 0x00, 0x00},
+				Err:      ErrOutOfRange,
+			},
+		},
+		{
+			decoder: NewUTF16BEDecoder(),
+			in:      []byte{0x00, 0x48, 0xdc, 0x00},
+			expected: DecodeError{
+				Position: Position{Offset: 2, Index: 1, Line: 1, Column: 2},
+				Encoding: "UTF-16BE",
+				Bytes:    []byte{0xdc, 0x00},
+				Err:      ErrLoneSurrogate,
+			},
+		},
+		{
+			decoder: NewUCS2LEDecoder(),
+			in:      []byte{0x48, 0x00, 0x49},
+			expected: DecodeError{
+				Position: Position{Offset: 2, Index: 1, Line: 1, Column: 2},
+				Encoding: "UCS-2LE",
+				Bytes:    []byte{0x49},
+				Err:      ErrTruncated,
+			},
+		},
+	}
+
+	for _, c := range cases {
+		err := Recode(bytes.NewReader(c.in), &bytes.Buffer{}, c.decoder, NewUTF8Encoder())
+
+		var de *DecodeError
+		if !errors.As(err, &de) {
+			t.Errorf("got %v, want *DecodeError", err)
+			continue
+		}
+
+		if !errors.Is(err, c.expected.Err) {
+			t.Errorf("got %v, want %v", de.Err, c.expected.Err)
+		}
+
+		if de.Position != c.expected.Position {
+			t.Errorf("got %+v, want %+v", de.Position, c.expected.Position)
+		}
+
+		if de.Encoding != c.expected.Encoding {
+			t.Errorf("got encoding %q, want %q", de.Encoding, c.expected.Encoding)
+		}
+
+		if !bytes.Equal(de.Bytes, c.expected.Bytes) {
+			t.Errorf("got bytes %v, want %v", de.Bytes, c.expected.Bytes)
+		}
+	}
+}
+
+func TestRecodeEncodeErrorPosition(t *testing.T) {
+	err := Recode(bytes.NewReader([]byte("one\ntwo\nthrée")), &bytes.Buffer{}, NewUTF8Decoder(), NewASCIIEncoder())
+
+	var ee *EncodeError
+	if !errors.As(err, &ee) {
+		t.Fatalf("got %v, want *EncodeError", err)
+	}
+
+	if !errors.Is(err, ErrOutOfRange) {
+		t.Errorf("got %v, want %v", ee.Err, ErrOutOfRange)
+	}
+
+	expected := Position{Offset: 11, Index: 11, Line: 3, Column: 4}
+	if ee.Position != expected {
+		t.Errorf("got %+v, want %+v", ee.Position, expected)
+	}
+
+	if ee.Rune != 'é' || ee.Encoding != "ASCII" {
+		t.Errorf("got %q in %s, want %q in ASCII", ee.Rune, ee.Encoding, 'é')
+	}
+
+	msg := "ASCII: character out of range (U+00E9) at offset 11 (line 3, column 4)"
+	if err.Error() != msg {
+		t.Errorf("got %q, want %q", err.Error(), msg)
+	}
+}
+
+func TestEncodeErrorEncoding(t *testing.T) {
+	cases := []struct {
+		encoder  Encoder
+		r        rune
+		expected string
+	}{
+		{NewUCS2Encoder(), 0x10000, "UCS-2"},
+		{NewUCS2BEEncoder(), 0x10000, "UCS-2BE"},
+		{NewUTF16LEEncoder(), 0xd800, "UTF-16LE"},
+		{NewUTF16BEEncoder(), 0x110000, "UTF-16BE"},
+		{NewUTF32Encoder(), 0x110000, "UTF-32"},
+		{NewUTF32LEEncoder(), 0xdfff, "UTF-32LE"},
+	}
+
+	for _, c := range cases {
+		err := c.encoder.Encode(&bytes.Buffer{}, c.r)
+
+		var ee *EncodeError
+		if !errors.As(err, &ee) {
+			t.Errorf("%s: got %v, want *EncodeError", c.expected, err)
+			continue
+		}
+
+		if ee.Encoding != c.expected {
+			t.Errorf("got encoding %q, want %q", ee.Encoding, c.expected)
+		}
+	}
+}
+
+// rot13Codec is a toy codec used to test registration.
+type rot13Codec struct {
+	ASCIIDecoder
+	ASCIIEncoder
+}
+
+func (c *rot13Codec) Decode(r io.Reader) (rune, error) {
+	char, err := c.ASCIIDecoder.Decode(r)
+	return rot13(char), err
+}
+
+func (c *rot13Codec) Encode(w io.Writer, r rune) error {
+	return c.ASCIIEncoder.Encode(w, rot13(r))
+}
+
+func rot13(r rune) rune {
+	switch {
+	case r >= 'a' && r <= 'z':
+		return 'a' + (r-'a'+13)%26
+	case r >= 'A' && r <= 'Z':
+		return 'A' + (r-'A'+13)%26
+	default:
+		return r
+	}
+}
+
+func TestRegister(t *testing.T) {
+	err := Register("X-ROT13", []string{"X-ROT-13"},
+		func() Decoder { return &rot13Codec{} },
+		func() Encoder { return &rot13Codec{} },
+	)
+	if err != nil {
+		t.Fatalf("register error: %v", err)
+	}
+	defer func() {
+		codecRegistryMu.Lock()
+		delete(codecRegistry, normalizeName("X-ROT13"))
+		codecRegistryMu.Unlock()
+	}()
+
+	c := Lookup("X-ROT-13")
+	if c == nil {
+		t.Fatalf("codec not found by alias")
+	}
+	if c.Name != "X-ROT13" {
+		t.Errorf("got name %q, want %q", c.Name, "X-ROT13")
+	}
+
+	actual := &bytes.Buffer{}
+	err = Recode(bytes.NewReader([]byte("Uryyb")), actual, GetDecoder("X-ROT13"), GetEncoder("UTF-8"))
+	if err != nil {
+		t.Fatalf("recode error: %v", err)
+	}
+	if actual.String() != "Hello" {
+		t.Errorf("got %q, want %q", actual.String(), "Hello")
+	}
+
+	found := false
+	for _, n := range Names() {
+		if n == "X-ROT-13" {
+			t.Errorf("Names() includes alias %q", n)
+		}
+		if n == "X-ROT13" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Names() doesn't include %q", "X-ROT13")
+	}
+}
+
+func TestRegisterDuplicate(t *testing.T) {
+	cases := []struct {
+		name    string
+		aliases []string
+	}{
+		{name: "UTF-8"},
+		{name: "X-NEW", aliases: []string{"UCS-4"}},
+	}
+
+	for _, c := range cases {
+		err := RegisterCodec(Codec{
+			Name:       c.name,
+			Aliases:    c.aliases,
+			NewDecoder: NewASCIIDecoder,
+			NewEncoder: NewASCIIEncoder,
+		})
+		if !errors.Is(err, ErrAlreadyRegistered) {
+			t.Errorf("%s: got %v, want %v", c.name, err, ErrAlreadyRegistered)
+		}
+	}
+
+	if Lookup("X-NEW") != nil {
+		t.Errorf("failed registration left X-NEW in the registry")
+	}
+}
+
+func TestRegisterDuplicateAliases(t *testing.T) {
+	err := RegisterCodec(Codec{
+		Name:       "X-DUP",
+		Aliases:    []string{"x_dup", "X-Alias", "x alias", "X-Other"},
+		NewDecoder: NewASCIIDecoder,
+		NewEncoder: NewASCIIEncoder,
+	})
+	if err != nil {
+		t.Fatalf("register error: %v", err)
+	}
+	defer func() {
+		codecRegistryMu.Lock()
+		for _, n := range []string{"xdup", "xalias", "xother"} {
+			delete(codecRegistry, n)
+		}
+		codecRegistryMu.Unlock()
+	}()
+
+	expected := []string{"X-Alias", "X-Other"}
+	actual := Lookup("x alias").Aliases
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("got aliases %q, want %q", actual, expected)
+	}
+
+	for _, name := range Names() {
+		seen := map[string]bool{normalizeName(name): true}
+		for _, alias := range Lookup(name).Aliases {
+			if seen[normalizeName(alias)] {
+				t.Errorf("%s: duplicate alias %q", name, alias)
+			}
+			seen[normalizeName(alias)] = true
+		}
+	}
+}
+
+func TestLookup(t *testing.T) {
+	cases := []struct {
+		name     string
+		expected string
+	}{
+		{"UTF-8", "UTF-8"},
+		{"utf8", "UTF-8"},
+		{"csUTF8", "UTF-8"},
+		{"cp65001", "UTF-8"},
+		{"unicode-1-1-utf-8", "UTF-8"},
+		{" Utf_8 ", "UTF-8"},
+		{"utf-16le", "UTF-16LE"},
+		{"unicodeFFFE", "UTF-16BE"},
+		{"ISO-10646-UCS-2", "UCS-2"},
+		{"ucs2", "UCS-2"},
+		{"UCS-4", "UTF-32"},
+		{"ucs-4le", "UTF-32LE"},
+		{"ANSI_X3.4-1968", "ASCII"},
+		{"us-ascii", "ASCII"},
+		{"x-no-such-encoding", ""},
+	}
+
+	for _, c := range cases {
+		codec := Lookup(c.name)
+		if codec == nil {
+			if c.expected != "" {
+				t.Errorf("%s: not found, want %s", c.name, c.expected)
+			}
+			continue
+		}
+
+		if codec.Name != c.expected {
+			t.Errorf("%s: got %s, want %s", c.name, codec.Name, c.expected)
+		}
+	}
+
+	if GetDecoder("utf-16le") == nil || GetEncoder("UTF16BE") == nil {
+		t.Errorf("GetDecoder/GetEncoder didn't normalize names")
+	}
+}
+
+func TestInfo(t *testing.T) {
+	for _, name := range Names() {
+		info := Lookup(name).Info
+		if info.MaxRune == 0 || info.MinBytes == 0 || info.MaxBytes < info.MinBytes {
+			t.Errorf("%s: incomplete info %+v", name, info)
+		}
+	}
+
+	info := Lookup("UCS-2").Info
+	if info.MaxRune != 0xffff || !info.ReadsBOM || info.WritesBOM {
+		t.Errorf("UCS-2: got %+v", info)
+	}
+}
+
+func TestBOMOptions(t *testing.T) {
+	cases := []struct {
+		decoder  Decoder
+		encoder  Encoder
+		in       []byte
+		expected []byte
+	}{
+		{
+			decoder:  NewUTF8Decoder(),
+			encoder:  NewUTF8Encoder(),
+			in:       []byte("\xef\xbb\xbfhi"),
+			expected: []byte("hi"),
+		},
+		{
+			decoder:  NewUTF8Decoder(Options{BOM: BOMPreserve}),
+			encoder:  NewUTF8Encoder(),
+			in:       []byte("\xef\xbb\xbfhi"),
+			expected: []byte("\xef\xbb\xbfhi"),
+		},
+		{
+			decoder:  NewUTF8Decoder(Options{BOM: BOMPreserve}),
+			encoder:  NewUTF8Encoder(Options{BOM: BOMNever}),
+			in:       []byte("\xef\xbb\xbfhi"),
+			expected: []byte("hi"),
+		},
+		{
+			// Only a leading U+FEFF is a byte order mark.
+			decoder:  NewUTF8Decoder(),
+			encoder:  NewUTF8Encoder(),
+			in:       []byte("\xef\xbb\xbf\xef\xbb\xbfhi"),
+			expected: []byte("\xef\xbb\xbfhi"),
+		},
+		{
+			decoder:  NewUTF8Decoder(),
+			encoder:  NewUTF8Encoder(Options{BOM: BOMAlways}),
+			in:       []byte("hi"),
+			expected: []byte("\xef\xbb\xbfhi"),
+		},
+		{
+			// Don't write a second byte order mark.
+			decoder:  NewUTF8Decoder(Options{BOM: BOMPreserve}),
+			encoder:  NewUTF16LEEncoder(),
+			in:       []byte("\xef\xbb\xbfhi"),
+			expected: []byte{0xff, 0xfe, 'h', 0, 'i', 0},
+		},
+		{
+			decoder:  NewUTF8Decoder(),
+			encoder:  NewUTF16BEEncoder(Options{BOM: BOMNever}),
+			in:       []byte("hi"),
+			expected: []byte{0, 'h', 0, 'i'},
+		},
+		{
+			decoder:  NewUTF8Decoder(),
+			encoder:  NewUTF32BEEncoder(Options{BOM: BOMAlways}),
+			in:       []byte("hi"),
+			expected: []byte{0, 0, 0xfe, 0xff, 0, 0, 0, 'h', 0, 0, 0, 'i'},
+		},
+		{
+			decoder:  NewUTF16LEDecoder(),
+			encoder:  NewUTF8Encoder(),
+			in:       []byte{0xff, 0xfe, 'h', 0, 'i', 0},
+			expected: []byte("hi"),
+		},
+		{
+			decoder:  NewUTF16LEDecoder(Options{BOM: BOMNever}),
+			encoder:  NewUTF8Encoder(),
+			in:       []byte{0xff, 0xfe, 'h', 0, 'i', 0},
+			expected: []byte("\xef\xbb\xbfhi"),
+		},
+		{
+			decoder:  NewUTF16Decoder(Options{BOM: BOMPreserve}),
+			encoder:  NewUTF8Encoder(),
+			in:       []byte{0xfe, 0xff, 0, 'h', 0, 'i'},
+			expected: []byte("\xef\xbb\xbfhi"),
+		},
+		{
+			decoder:  NewUCS2BEDecoder(),
+			encoder:  NewUTF8Encoder(),
+			in:       []byte{0xfe, 0xff, 0, 'h', 0, 'i'},
+			expected: []byte("hi"),
+		},
+		{
+			decoder:  NewUTF32BEDecoder(),
+			encoder:  NewUTF8Encoder(),
+			in:       []byte{0, 0, 0xfe, 0xff, 0, 0, 0, 'h'},
+			expected: []byte("h"),
+		},
+		{
+			decoder:  NewUTF32Decoder(Options{BOM: BOMPreserve}),
+			encoder:  NewUTF8Encoder(),
+			in:       []byte{0xff, 0xfe, 0, 0, 'h', 0, 0, 0},
+			expected: []byte("\xef\xbb\xbfh"),
+		},
+	}
+
+	for i, c := range cases {
+		actual := &bytes.Buffer{}
+		err := Recode(bytes.NewReader(c.in), actual, c.decoder, c.encoder)
+		if err != nil {
+			t.Errorf("%d: recode error: %v", i, err)
+			continue
+		}
+
+		if !bytes.Equal(actual.Bytes(), c.expected) {
+			t.Errorf("%d: got % x, want % x", i, actual.Bytes(), c.expected)
+		}
+	}
+}
+
+func TestRecodeBOMPolicy(t *testing.T) {
+	cases := []struct {
+		policy   BOMPolicy
+		encoder  Encoder
+		in       string
+		expected string
+	}{
+		{
+			policy:   BOMAlways,
+			encoder:  NewUTF8Encoder(),
+			in:       "hi",
+			expected: "\ufeffhi",
+		},
+		{
+			policy:   BOMAlways,
+			encoder:  NewUTF8Encoder(),
+			in:       "\ufeffhi",
+			expected: "\ufeffhi",
+		},
+		{
+			// ASCII has no byte order mark.
+			policy:   BOMAlways,
+			encoder:  NewASCIIEncoder(),
+			in:       "hi",
+			expected: "hi",
+		},
+		{
+			policy:   BOMNever,
+			encoder:  NewUTF8Encoder(),
+			in:       "\ufeffhi\ufeff",
+			expected: "hi\ufeff",
+		},
+		{
+			policy:   BOMPreserve,
+			encoder:  NewUTF8Encoder(),
+			in:       "\ufeffhi",
+			expected: "\ufeffhi",
+		},
+	}
+
+	for _, c := range cases {
+		actual := &bytes.Buffer{}
+		decoder := NewUTF8Decoder(Options{BOM: BOMPreserve})
+		err := Recode(bytes.NewReader([]byte(c.in)), actual, decoder, c.encoder, WithBOMPolicy(c.policy))
+		if err != nil {
+			t.Errorf("%q: recode error: %v", c.in, err)
+			continue
+		}
+
+		if actual.String() != c.expected {
+			t.Errorf("%q: got %q, want %q", c.in, actual.String(), c.expected)
+		}
+	}
+}
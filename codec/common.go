@@ -1,5 +1,7 @@
 package codec
 
+import "io"
+
 type byteOrder int
 
 const (
@@ -7,3 +9,177 @@ const (
 	littleEndian
 	bigEndian
 )
+
+// input holds bytes a decoder has read but not used yet. When a decoder finds
+// an invalid sequence it pushes back the bytes that revealed the problem, so
+// the next call to Decode starts with them instead of losing them.
+type input struct {
+	pending []byte
+}
+
+// readFull works like io.ReadFull, except that pending bytes are used before
+// reading from r.
+func (in *input) readFull(r io.Reader, buf []byte) (int, error) {
+	n := copy(buf, in.pending)
+	in.pending = in.pending[n:]
+	if n == len(buf) {
+		return n, nil
+	}
+
+	m, err := io.ReadFull(r, buf[n:])
+	n += m
+	if err == io.EOF && n > 0 {
+		err = io.ErrUnexpectedEOF
+	}
+	return n, err
+}
+
+// unread pushes buf back so it will be returned by the next read.
+func (in *input) unread(buf []byte) {
+	pending := make([]byte, 0, len(buf)+len(in.pending))
+	pending = append(pending, buf...)
+	in.pending = append(pending, in.pending...)
+}
+
+// Buffered returns the number of bytes that have been read but not decoded.
+func (in *input) Buffered() int {
+	return len(in.pending)
+}
+
+// noRune is returned by a decodeFunc for input that doesn't produce a
+// character, such as an escape sequence that only changes the decoder's state.
+const noRune = -1
+
+// decodeFunc decodes the start of src, which isn't empty. It returns the
+// character and the number of bytes it used. If src ends part way through a
+// character and atEOF is false, it returns ErrShortSrc and must not change any
+// state. Invalid input is returned as ErrInvalidSequence, ErrOutOfRange or
+// ErrTruncated along with the number of bytes to skip.
+//
+// Multi-byte decoders implement a decodeFunc, then use decodeRune and
+// decodeBytes for Decode and DecodeBytes so the two always agree.
+type decodeFunc func(src []byte, atEOF bool) (rune, int, error)
+
+// decodeRune reads from r one byte at a time until decode returns a character
+// or an error. Bytes that decode didn't use are pushed back for the next call.
+func (in *input) decodeRune(r io.Reader, encoding string, decode decodeFunc) (rune, error) {
+	var buf []byte
+	for {
+		var b [1]byte
+		_, err := in.readFull(r, b[:])
+		atEOF := false
+		switch {
+		case err == io.EOF && len(buf) == 0:
+			return 0, io.EOF
+		case err == io.EOF:
+			atEOF = true
+		case err != nil:
+			return 0, err
+		default:
+			buf = append(buf, b[0])
+		}
+
+		char, n, err := decode(buf, atEOF)
+		if err == ErrShortSrc {
+			continue
+		}
+		if n < len(buf) {
+			in.unread(buf[n:])
+		}
+		if err != nil {
+			return 0, &DecodeError{Encoding: encoding, Bytes: copyBytes(buf[:n]), Err: err}
+		}
+		if char == noRune {
+			buf = nil
+			continue
+		}
+		return char, nil
+	}
+}
+
+// decodeBytes implements DecodeBytes by calling decode on src until it's used
+// up or dst is full.
+func decodeBytes(dst []rune, src []byte, atEOF bool, encoding string, decode decodeFunc) (nDst, nSrc int, err error) {
+	for nSrc < len(src) {
+		if nDst == len(dst) {
+			return nDst, nSrc, ErrShortDst
+		}
+
+		char, n, err := decode(src[nSrc:], atEOF)
+		if err == ErrShortSrc {
+			return nDst, nSrc, err
+		}
+		if err != nil {
+			return nDst, nSrc, &DecodeError{Encoding: encoding, Bytes: copyBytes(src[nSrc : nSrc+n]), Err: err}
+		}
+		nSrc += n
+		if char == noRune {
+			continue
+		}
+		dst[nDst] = char
+		nDst++
+	}
+	return nDst, nSrc, nil
+}
+
+const (
+	// Mask the six high bits of a 16 bit number
+	utf16SurrogateMask = 0x3f << 10
+	utf16HighSurrogate = 0xd800
+	utf16LowSurrogate  = 0xdc00
+)
+
+// checkRune reports whether r is a Unicode scalar value, meaning it's in range
+// and isn't a UTF-16 surrogate. Only scalar values can be encoded in UTF-8 and
+// UTF-32.
+func checkRune(r rune) error {
+	switch {
+	case r < 0 || r > 0x10ffff:
+		return ErrOutOfRange
+	case r >= utf16HighSurrogate && r <= 0xdfff:
+		return ErrLoneSurrogate
+	default:
+		return nil
+	}
+}
+
+// copyBytes returns a copy of b, so that a DecodeError doesn't hold on to a
+// caller's buffer.
+func copyBytes(b []byte) []byte {
+	return append([]byte(nil), b...)
+}
+
+// bomEncoder decides whether an encoder writes a byte order mark.
+type bomEncoder struct {
+	policy  BOMPolicy
+	write   bool
+	started bool
+}
+
+// newBOMEncoder returns a bomEncoder for policy. byDefault is whether the
+// encoder writes a byte order mark under BOMAuto.
+func newBOMEncoder(policy BOMPolicy, byDefault bool) bomEncoder {
+	return bomEncoder{
+		policy: policy,
+		write:  policy == BOMAlways || (policy == BOMAuto && byDefault),
+	}
+}
+
+// start is called with each character before it's encoded. It reports whether
+// to write a byte order mark first, and whether to drop the character because
+// it's a byte order mark that isn't wanted or was already written.
+func (b *bomEncoder) start(r rune) (writeBOM, skip bool) {
+	if b.started {
+		return false, false
+	}
+	b.started = true
+
+	switch {
+	case b.write:
+		return true, r == bomRune
+	case b.policy == BOMNever:
+		return false, r == bomRune
+	default:
+		return false, false
+	}
+}
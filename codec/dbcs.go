@@ -0,0 +1,270 @@
+package codec
+
+import (
+	"fmt"
+	"io"
+	"sync"
+)
+
+// dbcsTable holds the characters of a double-byte encoding, where each
+// character is one or two bytes. The tables are generated by gen_dbcs.go.
+type dbcsTable struct {
+	// single holds the character for each byte on its own, or -1 if the
+	// byte isn't a character by itself.
+	single [256]rune
+
+	// Bytes from leadMin to leadMax that aren't characters by themselves
+	// start a two byte code. The second byte is from trailMin to
+	// trailMax.
+	leadMin, leadMax   byte
+	trailMin, trailMax byte
+
+	// double holds the character for each two byte code, one row of
+	// trail bytes for each lead byte, or 0 if the code is undefined.
+	double []rune
+}
+
+// char returns the character for a two byte code, or 0 if it's undefined.
+func (t *dbcsTable) char(lead, trail byte) rune {
+	if lead < t.leadMin || lead > t.leadMax || trail < t.trailMin || trail > t.trailMax {
+		return 0
+	}
+	width := int(t.trailMax-t.trailMin) + 1
+	return t.double[int(lead-t.leadMin)*width+int(trail-t.trailMin)]
+}
+
+// dbcs is a double-byte encoding.
+type dbcs struct {
+	name  string
+	table *dbcsTable
+
+	// Codes with a lead byte from fallbackMin to fallbackMax are only
+	// used to encode characters that have no other code. Zero means there
+	// are none.
+	fallbackMin, fallbackMax byte
+
+	// If lastWins is set, a character with more than one two byte code
+	// is encoded with the highest, rather than the lowest.
+	lastWins bool
+
+	// encode is the reverse of table, built the first time it's needed.
+	// Single byte codes are stored as themselves and two byte codes as
+	// lead<<8 | trail.
+	encodeOnce sync.Once
+	encode     map[rune]uint16
+}
+
+// registerDBCS registers a double-byte codec.
+func registerDBCS(cs *dbcs, aliases []string) {
+	registerCodec(Codec{
+		Name:    cs.name,
+		Aliases: aliases,
+		NewDecoder: func(...Options) Decoder {
+			return &DBCSDecoder{dbcs: cs}
+		},
+		NewEncoder: func(...Options) Encoder {
+			return &DBCSEncoder{dbcs: cs}
+		},
+		Info: cs.info(),
+	})
+}
+
+func (cs *dbcs) info() Info {
+	var maxRune rune
+	chars := map[rune]bool{}
+	add := func(r rune) {
+		chars[r] = true
+		if r > maxRune {
+			maxRune = r
+		}
+	}
+	for _, r := range cs.table.single {
+		if r >= 0 {
+			add(r)
+		}
+	}
+	for _, r := range cs.table.double {
+		if r != 0 {
+			add(r)
+		}
+	}
+
+	return Info{
+		MaxRune:    maxRune,
+		Repertoire: fmt.Sprintf("%d characters", len(chars)),
+		MinBytes:   1,
+		MaxBytes:   2,
+	}
+}
+
+// isLead reports whether b starts a two byte code.
+func (cs *dbcs) isLead(b byte) bool {
+	t := cs.table
+	return t.single[b] < 0 && b >= t.leadMin && b <= t.leadMax
+}
+
+// decode decodes the character at the start of src, which must not be empty.
+// It returns the character and the number of bytes it used. If the bytes are
+// invalid it returns the error and the number of bytes to skip. If src ends
+// after a lead byte it returns ErrShortSrc, unless atEOF is set.
+//
+// When a lead byte is followed by an ASCII byte that doesn't complete a
+// character, only the lead byte is skipped. That way a stray lead byte doesn't
+// swallow the ASCII character after it.
+func (cs *dbcs) decode(src []byte, atEOF bool) (rune, int, error) {
+	t := cs.table
+	b := src[0]
+	if r := t.single[b]; r >= 0 {
+		return r, 1, nil
+	}
+	if !cs.isLead(b) {
+		return 0, 1, ErrInvalidSequence
+	}
+
+	if len(src) < 2 {
+		if !atEOF {
+			return 0, 0, ErrShortSrc
+		}
+		return 0, 1, ErrTruncated
+	}
+
+	trail := src[1]
+	if r := t.char(b, trail); r != 0 {
+		return r, 2, nil
+	}
+
+	// An ASCII byte can't be a trail byte, so it starts the next character.
+	if trail < 0x80 {
+		return 0, 1, ErrInvalidSequence
+	}
+	return 0, 2, ErrInvalidSequence
+}
+
+// lookup returns the code for r.
+func (cs *dbcs) lookup(r rune) (uint16, bool) {
+	cs.encodeOnce.Do(cs.buildEncode)
+
+	code, ok := cs.encode[r]
+	return code, ok
+}
+
+func (cs *dbcs) buildEncode() {
+	t := cs.table
+	cs.encode = make(map[rune]uint16, len(t.double))
+
+	// If a character has more than one code use the lowest, or the
+	// highest with lastWins, except that single bytes come first and
+	// fallback codes come last.
+	for b, r := range t.single {
+		if _, ok := cs.encode[r]; r >= 0 && !ok {
+			cs.encode[r] = uint16(b)
+		}
+	}
+
+	width := int(t.trailMax-t.trailMin) + 1
+	add := func(fallback bool) {
+		for j := range t.double {
+			i := j
+			if cs.lastWins {
+				i = len(t.double) - 1 - j
+			}
+			r := t.double[i]
+			if r == 0 {
+				continue
+			}
+			lead := t.leadMin + byte(i/width)
+			isFallback := cs.fallbackMin != 0 && lead >= cs.fallbackMin && lead <= cs.fallbackMax
+			if isFallback != fallback {
+				continue
+			}
+			if _, ok := cs.encode[r]; !ok {
+				cs.encode[r] = uint16(lead)<<8 | uint16(t.trailMin+byte(i%width))
+			}
+		}
+	}
+	add(false)
+	add(true)
+}
+
+var _ BulkDecoder = &DBCSDecoder{}
+
+// DBCSDecoder implements Decoder for double-byte encodings such as Shift_JIS,
+// where each character is one or two bytes. Use GetDecoder to get one for a
+// particular encoding.
+type DBCSDecoder struct {
+	dbcs *dbcs
+	in   input
+}
+
+// Decode satisfies the Decoder interface for double-byte encodings.
+func (d *DBCSDecoder) Decode(r io.Reader) (rune, error) {
+	return d.in.decodeRune(r, d.dbcs.name, d.dbcs.decode)
+}
+
+// BulkDecoder satisfies the BulkDecoder interface for double-byte encodings.
+func (d *DBCSDecoder) BulkDecoder() BulkDecoder {
+	return d
+}
+
+// DecodeBytes satisfies the BulkDecoder interface for double-byte encodings.
+func (d *DBCSDecoder) DecodeBytes(dst []rune, src []byte, atEOF bool) (nDst, nSrc int, err error) {
+	return decodeBytes(dst, src, atEOF, d.dbcs.name, d.dbcs.decode)
+}
+
+// Buffered returns the number of bytes that have been read but not decoded.
+func (d *DBCSDecoder) Buffered() int {
+	return d.in.Buffered()
+}
+
+var _ BulkEncoder = &DBCSEncoder{}
+
+// DBCSEncoder implements Encoder for double-byte encodings such as Shift_JIS,
+// where each character is one or two bytes. Use GetEncoder to get one for a
+// particular encoding.
+type DBCSEncoder struct {
+	dbcs *dbcs
+}
+
+// Encode satisfies the Encoder interface for double-byte encodings.
+func (e *DBCSEncoder) Encode(w io.Writer, r rune) error {
+	code, ok := e.dbcs.lookup(r)
+	if !ok {
+		return &EncodeError{Encoding: e.dbcs.name, Rune: r, Err: ErrOutOfRange}
+	}
+
+	buf := make([]byte, 2)
+	n := putDBCS(buf, code)
+	_, err := w.Write(buf[:n])
+	return err
+}
+
+// BulkEncoder satisfies the BulkEncoder interface for double-byte encodings.
+func (e *DBCSEncoder) BulkEncoder() BulkEncoder {
+	return e
+}
+
+// EncodeRunes satisfies the BulkEncoder interface for double-byte encodings.
+func (e *DBCSEncoder) EncodeRunes(dst []byte, src []rune) (nDst, nSrc int, err error) {
+	for ; nSrc < len(src); nSrc++ {
+		code, ok := e.dbcs.lookup(src[nSrc])
+		if !ok {
+			return nDst, nSrc, &EncodeError{Encoding: e.dbcs.name, Rune: src[nSrc], Err: ErrOutOfRange}
+		}
+		if len(dst)-nDst < 2 {
+			return nDst, nSrc, ErrShortDst
+		}
+		nDst += putDBCS(dst[nDst:], code)
+	}
+	return nDst, nSrc, nil
+}
+
+// putDBCS writes a code from dbcs.lookup to buf and returns its length.
+func putDBCS(buf []byte, code uint16) int {
+	if code < 0x100 {
+		buf[0] = byte(code)
+		return 1
+	}
+	buf[0] = byte(code >> 8)
+	buf[1] = byte(code)
+	return 2
+}
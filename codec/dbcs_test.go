@@ -0,0 +1,235 @@
+package codec
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// dbcsMappings lists the table and mapping file each double-byte codec was
+// generated from.
+var dbcsMappings = map[string]mappingSource{
+	"Shift_JIS":   {"shiftJIS", "OBSOLETE/EASTASIA/JIS/SHIFTJIS.TXT"},
+	"windows-31j": {"cp932", "VENDORS/MICSFT/WINDOWS/CP932.TXT"},
+	"GBK":         {"cp936", "VENDORS/MICSFT/WINDOWS/CP936.TXT"},
+	"GB18030":     {"gb18030", "VENDORS/MISC/GB18030.TXT"},
+	"Big5":        {"big5", "OBSOLETE/EASTASIA/OTHER/BIG5.TXT"},
+	"Big5-HKSCS":  {"big5HKSCS", "VENDORS/MISC/BIG5-HKSCS.TXT"},
+	"UHC":         {"cp949", "VENDORS/MICSFT/WINDOWS/CP949.TXT"},
+}
+
+// TestDBCSMappings checks each double-byte codec against its mapping file.
+// Every listed code must decode to the listed character, and encoding the
+// character must give back a code for it. Characters listed once must encode
+// to that code.
+func TestDBCSMappings(t *testing.T) {
+	for name, src := range dbcsMappings {
+		mapping, err := readDBCSMapping(src)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+
+		c := Lookup(name)
+		if c == nil {
+			t.Errorf("%s is not registered", name)
+			continue
+		}
+
+		codes := map[rune]int{}
+		for _, r := range mapping {
+			codes[r]++
+		}
+
+		for code, expected := range mapping {
+			decoder, encoder := c.NewDecoder(), c.NewEncoder()
+
+			char, err := decoder.Decode(bytes.NewReader([]byte(code)))
+			if err != nil || char != expected {
+				t.Errorf("%s: % x: got %U, %v, want %U", name, code, char, err, expected)
+				continue
+			}
+
+			actual := &bytes.Buffer{}
+			err = encoder.Encode(actual, char)
+			if f, ok := encoder.(Flusher); ok && err == nil {
+				err = f.Flush(actual)
+			}
+			switch {
+			case err != nil:
+				t.Errorf("%s: %U: encode error: %v", name, char, err)
+			case codes[char] == 1 && actual.String() != code:
+				t.Errorf("%s: %U: got % x, want % x", name, char, actual.Bytes(), code)
+			case mapping[actual.String()] != char:
+				t.Errorf("%s: %U: got % x, which is %U", name, char, actual.Bytes(), mapping[actual.String()])
+			}
+		}
+	}
+}
+
+// readDBCSMapping reads a mapping file in the format gen_dbcs.go uses, with the
+// overrides for the table applied. The keys are the encoded bytes.
+func readDBCSMapping(src mappingSource) (map[string]rune, error) {
+	f, err := os.Open(filepath.Join("mappings", src.file))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	mapping := map[string]rune{}
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		fields := strings.Fields(strings.SplitN(s.Text(), "#", 2)[0])
+		if len(fields) != 2 {
+			continue
+		}
+		code, err := mappingCode(fields[0])
+		if err != nil {
+			return nil, err
+		}
+		r, err := strconv.ParseUint(fields[1], 0, 32)
+		if err != nil {
+			return nil, err
+		}
+		mapping[code] = rune(r)
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+
+	overrides, err := readOverrides(src.table)
+	if err != nil {
+		return nil, err
+	}
+	for code, r := range overrides {
+		if r == -1 {
+			delete(mapping, code)
+		} else {
+			mapping[code] = r
+		}
+	}
+	return mapping, nil
+}
+
+func TestDBCSKnownCodes(t *testing.T) {
+	checkKnownCodes(t, []knownCode{
+		{"Shift_JIS", []byte{0x81, 0x40}, '\u3000'},
+		{"Shift_JIS", []byte{0x82, 0xa0}, 'あ'},
+		{"Shift_JIS", []byte{0x88, 0x9f}, '亜'},
+		{"windows-31j", []byte{0x81, 0x5f}, '＼'},
+		{"windows-31j", []byte{0x87, 0x40}, '①'},
+		{"GBK", []byte{0x80}, '€'},
+		{"GBK", []byte{0x81, 0x40}, '丂'},
+		{"GBK", []byte{0xb0, 0xa1}, '啊'},
+		{"GB18030", []byte{0xb0, 0xa1}, '啊'},
+		{"GB18030", []byte{0xa8, 0xbc}, 'ḿ'},
+		{"GB18030", []byte{0x81, 0x30, 0x81, 0x30}, '\u0080'},
+		{"Big5", []byte{0xa1, 0x40}, '\u3000'},
+		{"Big5", []byte{0xa4, 0x40}, '一'},
+		{"Big5-HKSCS", []byte{0xa4, 0x40}, '一'},
+		{"UHC", []byte{0x81, 0x41}, '갂'},
+		{"UHC", []byte{0xb0, 0xa1}, '가'},
+		{"UHC", []byte{0xa4, 0xd4}, '\u3164'},
+	})
+}
+
+func TestDBCSDecodeErrors(t *testing.T) {
+	cases := []struct {
+		encoding string
+		in       []byte
+		bytes    []byte
+		err      error
+		next     rune
+	}{
+		{
+			// Never a lead byte
+			encoding: "Shift_JIS",
+			in:       []byte{0x80, 'A'},
+			bytes:    []byte{0x80},
+			err:      ErrInvalidSequence,
+			next:     'A',
+		},
+		{
+			// Past the last lead byte in Shift_JIS
+			encoding: "Shift_JIS",
+			in:       []byte{0xf0, 0x40},
+			bytes:    []byte{0xf0},
+			err:      ErrInvalidSequence,
+			next:     '@',
+		},
+		{
+			// An ASCII byte after a lead byte is left alone
+			encoding: "Shift_JIS",
+			in:       []byte{0x88, 'A'},
+			bytes:    []byte{0x88},
+			err:      ErrInvalidSequence,
+			next:     'A',
+		},
+		{
+			// A trail byte out of range is skipped with the lead
+			encoding: "windows-31j",
+			in:       []byte{0x88, 0xfd, 'A'},
+			bytes:    []byte{0x88, 0xfd},
+			err:      ErrInvalidSequence,
+			next:     'A',
+		},
+		{
+			// Valid bytes, but no character
+			encoding: "Shift_JIS",
+			in:       []byte{0x85, 0x9f, 'A'},
+			bytes:    []byte{0x85, 0x9f},
+			err:      ErrInvalidSequence,
+			next:     'A',
+		},
+		{
+			// Lead byte at the end of the input
+			encoding: "windows-31j",
+			in:       []byte{0x93},
+			bytes:    []byte{0x93},
+			err:      ErrTruncated,
+			next:     -1,
+		},
+	}
+
+	for _, c := range cases {
+		decoder := GetDecoder(c.encoding)
+		in := bytes.NewReader(c.in)
+
+		var de *DecodeError
+		char, err := decoder.Decode(in)
+		if !errors.As(err, &de) || de.Err != c.err || !bytes.Equal(de.Bytes, c.bytes) || de.Encoding != c.encoding {
+			t.Errorf("%s % x: got %U, %v, want %v (% x)", c.encoding, c.in, char, err, c.err, c.bytes)
+			continue
+		}
+
+		char, err = decoder.Decode(in)
+		if c.next < 0 {
+			if err == nil {
+				t.Errorf("%s % x: got %U after the error, want EOF", c.encoding, c.in, char)
+			}
+		} else if err != nil || char != c.next {
+			t.Errorf("%s % x: got %U, %v after the error, want %U", c.encoding, c.in, char, err, c.next)
+		}
+	}
+}
+
+func TestDBCSShortSrc(t *testing.T) {
+	d := GetDecoder("Shift_JIS").(BulkDecoder)
+	dst := make([]rune, 8)
+
+	nDst, nSrc, err := d.DecodeBytes(dst, []byte{'a', 0x93}, false)
+	if nDst != 1 || nSrc != 1 || err != ErrShortSrc {
+		t.Errorf("got %d, %d, %v, want 1, 1, %v", nDst, nSrc, err, ErrShortSrc)
+	}
+
+	var de *DecodeError
+	nDst, nSrc, err = d.DecodeBytes(dst, []byte{'a', 0x93}, true)
+	if nDst != 1 || nSrc != 1 || !errors.As(err, &de) || de.Err != ErrTruncated {
+		t.Errorf("got %d, %d, %v, want 1, 1, %v", nDst, nSrc, err, ErrTruncated)
+	}
+}
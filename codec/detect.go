@@ -0,0 +1,686 @@
+package codec
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math"
+	"sync"
+	"unicode"
+)
+
+// detectSampleSize is the most input Detect will read.
+const detectSampleSize = 64 << 10
+
+// singleByteCandidates are the single-byte encodings Detect considers, most
+// common first, with the languages it expects to find in each. Earlier
+// encodings win ties, which matters for encodings that only differ in a few
+// rarely used characters.
+var singleByteCandidates = []struct {
+	encoding  string
+	languages []string
+}{
+	{"windows-1252", []string{"fr", "de", "es", "pt", "it", "sv", "da"}},
+	{"windows-1250", []string{"cs", "sk", "pl", "hu", "hr"}},
+	{"ISO-8859-2", []string{"cs", "sk", "pl", "hu", "hr"}},
+	{"windows-1251", []string{"ru", "uk", "bg"}},
+	{"KOI8-R", []string{"ru", "bg"}},
+	{"ISO-8859-5", []string{"ru", "uk", "bg"}},
+	{"IBM866", []string{"ru", "bg"}},
+	{"windows-1253", []string{"el"}},
+	{"ISO-8859-7", []string{"el"}},
+	{"windows-1254", []string{"tr"}},
+	{"windows-1255", []string{"he"}},
+	{"ISO-8859-8", []string{"he"}},
+	{"windows-1256", []string{"ar"}},
+	{"windows-1257", []string{"lt", "lv", "et"}},
+	{"windows-874", []string{"th"}},
+}
+
+// multiByteCandidates are the multi-byte encodings Detect considers, with the
+// language whose frequentCharacters it expects to find.
+var multiByteCandidates = []struct {
+	encoding string
+	language string
+}{
+	{"Shift_JIS", "ja"},
+	{"EUC-JP", "ja"},
+	{"GB18030", "zh"},
+	{"Big5", "zh"},
+	{"EUC-KR", "ko"},
+}
+
+// Detect reads up to 64 KiB from r and guesses which registered encoding it's
+// in. It returns the name of the codec and a confidence between 0 and 1. It
+// never reads more than that, so a caller that needs the input afterwards can
+// keep what Detect read with io.TeeReader.
+//
+// A byte order mark is always trusted. Without one, Detect checks whether the
+// input is plausible as UTF-32 or UTF-16 in either byte order and whether it's
+// valid UTF-8. ISO-2022 encodings are recognised by their escape sequences.
+// Other legacy encodings are scored by how well the bytes of the input match
+// the frequency of characters in the languages written with the encoding.
+//
+// Input that's entirely ASCII is reported as UTF-8, since UTF-8 is a superset
+// of it. If no encoding is plausible Detect returns UTF-8 with a confidence of
+// 0.
+func Detect(r io.Reader) (string, float64, error) {
+	sample := make([]byte, detectSampleSize)
+	n, err := io.ReadFull(r, sample)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", 0, err
+	}
+	sample = sample[:n]
+
+	// If the sample filled the buffer it may end part way through a
+	// character.
+	truncated := n == detectSampleSize
+
+	if name, ok := detectBOM(sample); ok {
+		return name, 1, nil
+	}
+
+	best, bestScore := "UTF-8", 0.0
+	try := func(name string, score float64) {
+		if score <= bestScore {
+			return
+		}
+		c := Lookup(name)
+		if c == nil || c.NewDecoder == nil {
+			return
+		}
+		best, bestScore = c.Name, score
+	}
+
+	try(detectUTF32(sample, truncated))
+	try(detectUTF16(sample, truncated))
+	try("UTF-8", scoreUTF8(sample, truncated))
+	try(detectISO2022(sample, truncated))
+
+	// Text in a legacy encoding has no reason to contain control
+	// characters, but UTF-16 and UTF-32 are full of them.
+	var counts [256]int
+	var controls int
+	for _, b := range sample {
+		counts[b]++
+		if b < 0x20 && !plausibleRune(rune(b)) {
+			controls++
+		}
+	}
+	if controls > len(sample)/1000 {
+		return best, bestScore, nil
+	}
+
+	loadDetectModels()
+	for i, c := range singleByteCandidates {
+		for _, m := range singleByteModels[i] {
+			try(c.encoding, m.score(&counts))
+		}
+	}
+	for i, c := range multiByteCandidates {
+		try(c.encoding, scoreMultiByte(c.encoding, multiByteTables[i], sample, truncated))
+	}
+
+	return best, bestScore, nil
+}
+
+// detectBOM returns the encoding indicated by a byte order mark at the start
+// of sample.
+func detectBOM(sample []byte) (string, bool) {
+	switch {
+	// Check UTF-32 first, the little-endian BOM starts with the UTF-16
+	// little-endian BOM.
+	case bytes.HasPrefix(sample, []byte{0x00, 0x00, 0xfe, 0xff}),
+		bytes.HasPrefix(sample, []byte{0xff, 0xfe, 0x00, 0x00}):
+		return "UTF-32", true
+	case bytes.HasPrefix(sample, []byte{0xfe, 0xff}),
+		bytes.HasPrefix(sample, []byte{0xff, 0xfe}):
+		return "UTF-16", true
+	case bytes.HasPrefix(sample, []byte{0xef, 0xbb, 0xbf}):
+		return "UTF-8", true
+	default:
+		return "", false
+	}
+}
+
+// detectUTF32 checks if sample is valid UTF-32 in either byte order. Since the
+// high byte of every code point is zero and the next byte is at most 0x10, text
+// in any other encoding is almost never valid.
+func detectUTF32(sample []byte, truncated bool) (string, float64) {
+	if len(sample) < 4 || (len(sample)%4 != 0 && !truncated) {
+		return "", 0
+	}
+
+	beValid, leValid := true, true
+	for i := 0; i+4 <= len(sample) && (beValid || leValid); i += 4 {
+		u := sample[i : i+4]
+		be := rune(u[0])<<24 | rune(u[1])<<16 | rune(u[2])<<8 | rune(u[3])
+		le := rune(u[3])<<24 | rune(u[2])<<16 | rune(u[1])<<8 | rune(u[0])
+		beValid = beValid && checkRune(be) == nil && plausibleRune(be)
+		leValid = leValid && checkRune(le) == nil && plausibleRune(le)
+	}
+
+	switch {
+	case beValid && !leValid:
+		return "UTF-32BE", 0.99
+	case leValid && !beValid:
+		return "UTF-32LE", 0.99
+	default:
+		return "", 0
+	}
+}
+
+// detectUTF16 scores sample as UTF-16 in both byte orders and returns the more
+// likely one.
+//
+// Most text sticks to one or two scripts, and each script occupies a small
+// range of code points. So in UTF-16 the high byte of each code unit takes
+// far fewer distinct values than the low byte. For text in a single-byte
+// encoding both halves of each pair of bytes look alike.
+func detectUTF16(sample []byte, truncated bool) (string, float64) {
+	if len(sample) < 2 || (len(sample)%2 != 0 && !truncated) {
+		return "", 0
+	}
+
+	beScore := scoreUTF16(sample, bigEndian, truncated)
+	leScore := scoreUTF16(sample, littleEndian, truncated)
+	if beScore > leScore {
+		return "UTF-16BE", beScore
+	}
+	return "UTF-16LE", leScore
+}
+
+// byteOrderWindow is how far UTF-16 and UCS-2 decoders read ahead to guess the
+// byte order of input without a byte order mark.
+const byteOrderWindow = 512
+
+// guessUTF16ByteOrder guesses the byte order of UTF-16 or UCS-2 text without a
+// byte order mark. Surrogates that only pair up in one byte order settle it,
+// unless loneSurrogates allows unpaired ones, and then the order with more
+// pairs wins. Otherwise the order with the most zero high bytes wins, since most text
+// contains ASCII characters. Text with little or no ASCII is scored the same
+// way Detect does it. If nothing points either way it's little-endian.
+func guessUTF16ByteOrder(sample []byte, truncated, loneSurrogates bool) byteOrder {
+	bePairs, beValid := surrogatePairs(sample, bigEndian, truncated)
+	lePairs, leValid := surrogatePairs(sample, littleEndian, truncated)
+	switch {
+	case beValid != leValid && !loneSurrogates:
+		if beValid {
+			return bigEndian
+		}
+		return littleEndian
+	case bePairs > lePairs:
+		return bigEndian
+	case lePairs > bePairs:
+		return littleEndian
+	}
+
+	var beASCII, leASCII int
+	for i := 0; i+2 <= len(sample); i += 2 {
+		switch {
+		case sample[i] == 0 && sample[i+1] != 0:
+			beASCII++
+			if plausibleRune(rune(sample[i+1])) && sample[i+1] < 0x7f {
+				beASCII++
+			}
+		case sample[i+1] == 0 && sample[i] != 0:
+			leASCII++
+			if plausibleRune(rune(sample[i])) && sample[i] < 0x7f {
+				leASCII++
+			}
+		}
+	}
+	switch {
+	case beASCII > leASCII:
+		return bigEndian
+	case leASCII > beASCII:
+		return littleEndian
+	}
+
+	if scoreUTF16(sample, bigEndian, truncated) > scoreUTF16(sample, littleEndian, truncated) {
+		return bigEndian
+	}
+	return littleEndian
+}
+
+// surrogatePairs counts the surrogate pairs in sample when it's read in the
+// given byte order. It also reports whether every surrogate is part of a pair.
+func surrogatePairs(sample []byte, order byteOrder, truncated bool) (int, bool) {
+	var pairs int
+	valid, high := true, false
+	for i := 0; i+2 <= len(sample); i += 2 {
+		u := rune(sample[i])<<8 | rune(sample[i+1])
+		if order == littleEndian {
+			u = rune(sample[i+1])<<8 | rune(sample[i])
+		}
+
+		switch u & utf16SurrogateMask {
+		case utf16HighSurrogate:
+			if high {
+				valid = false
+			}
+			high = true
+		case utf16LowSurrogate:
+			if high {
+				pairs++
+			} else {
+				valid = false
+			}
+			high = false
+		default:
+			if high {
+				valid = false
+			}
+			high = false
+		}
+	}
+	return pairs, valid && (!high || truncated)
+}
+
+func scoreUTF16(sample []byte, order byteOrder, truncated bool) float64 {
+	var high, low [256]bool
+	var highCount, lowCount int
+	text := make([]rune, 0, len(sample)/2)
+
+	for i := 0; i+2 <= len(sample); i += 2 {
+		hi, lo := sample[i], sample[i+1]
+		if order == littleEndian {
+			hi, lo = lo, hi
+		}
+		u := rune(hi)<<8 | rune(lo)
+
+		if !high[hi] {
+			high[hi] = true
+			highCount++
+		}
+		if !low[lo] {
+			low[lo] = true
+			lowCount++
+		}
+
+		switch u & utf16SurrogateMask {
+		case utf16HighSurrogate:
+			// A high surrogate must be followed by a low surrogate,
+			// unless it's the last unit in a truncated sample.
+			if i+4 > len(sample) {
+				if !truncated {
+					return 0
+				}
+				continue
+			}
+			next := rune(sample[i+2])<<8 | rune(sample[i+3])
+			if order == littleEndian {
+				next = rune(sample[i+3])<<8 | rune(sample[i+2])
+			}
+			if next&utf16SurrogateMask != utf16LowSurrogate {
+				return 0
+			}
+			text = append(text, 0x10000+(u&0x3ff)<<10|next&0x3ff)
+			i += 2
+		case utf16LowSurrogate:
+			return 0
+		default:
+			text = append(text, u)
+		}
+	}
+
+	if len(text) == 0 || lowCount == 0 {
+		return 0
+	}
+
+	spread := float64(highCount) / float64(lowCount)
+	if spread >= 1 {
+		return 0
+	}
+
+	// Reading the wrong byte order, or something that isn't UTF-16 at
+	// all, turns up unassigned code points and private use characters.
+	var bad int
+	for _, r := range text {
+		if !plausibleRune(r) || unicode.Is(unicode.Co, r) || (!unicode.IsPrint(r) && !unicode.IsSpace(r)) {
+			bad++
+		}
+	}
+	if bad > len(text)/100 {
+		return 0
+	}
+
+	return (1 - spread) * certainty(len(text))
+}
+
+// scoreUTF8 returns a confidence that sample is UTF-8. Multi-byte sequences
+// are unlikely to be valid by accident, so each one makes UTF-8 more likely.
+func scoreUTF8(sample []byte, truncated bool) float64 {
+	d := NewUTF8Decoder()
+	r := bytes.NewReader(sample)
+
+	var chars, multiByte int
+	for {
+		before := r.Len()
+		char, err := d.Decode(r)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			if truncated && errors.Is(err, ErrTruncated) {
+				break
+			}
+			return 0
+		}
+		if !plausibleRune(char) {
+			return 0
+		}
+
+		chars++
+		if before-r.Len() > 1 {
+			multiByte++
+		}
+	}
+
+	if chars == 0 {
+		return 0
+	}
+	if multiByte == 0 {
+		// ASCII. Any legacy encoding that scores at all has found
+		// non-ASCII characters, so this is only a fallback.
+		return 0.5
+	}
+
+	return 1 - 1/float64(multiByte+2)/4
+}
+
+// certainty grows towards 1 as more evidence is found.
+func certainty(n int) float64 {
+	return 1 - 1/float64(n+1)
+}
+
+// plausibleRune reports whether r is likely to appear in text. Control
+// characters other than whitespace and noncharacters are not.
+func plausibleRune(r rune) bool {
+	switch {
+	case r == '\t', r == '\n', r == '\r', r == '\f':
+		return true
+	case r < 0x20, r >= 0x7f && r < 0xa0:
+		return false
+	case r == 0xfffe, r == 0xffff:
+		return false
+	default:
+		return true
+	}
+}
+
+// iso2022Escapes are the escape sequences that select a character set in each
+// ISO-2022 encoding Detect knows. Sequences shared by several encodings are
+// listed under the simplest one.
+var iso2022Escapes = []struct {
+	encoding string
+	escapes  []string
+}{
+	{"ISO-2022-JP", []string{"\x1b(B", "\x1b(J", "\x1b$@", "\x1b$B"}},
+	{"ISO-2022-JP-2", []string{"\x1b$A", "\x1b$(C", "\x1b$(D", "\x1b.A", "\x1b.F", "\x1bN"}},
+	{"ISO-2022-KR", []string{"\x1b$)C"}},
+}
+
+// detectISO2022 recognises the 7-bit ISO-2022 encodings by their escape
+// sequences, which don't turn up in text by accident.
+func detectISO2022(sample []byte, truncated bool) (string, float64) {
+	name := ""
+	for i, b := range sample {
+		if b >= 0x80 {
+			return "", 0
+		}
+		if b != 0x1b {
+			continue
+		}
+		for _, set := range iso2022Escapes {
+			for _, esc := range set.escapes {
+				// Later encodings are supersets of ISO-2022-JP.
+				if bytes.HasPrefix(sample[i:], []byte(esc)) && (name == "" || name == "ISO-2022-JP") {
+					name = set.encoding
+				}
+			}
+		}
+	}
+	if name == "" {
+		return "", 0
+	}
+
+	d := GetDecoder(name)
+	if d == nil {
+		return "", 0
+	}
+	r := bytes.NewReader(sample)
+	for {
+		_, err := d.Decode(r)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			if truncated && r.Len() == 0 && errors.Is(err, ErrTruncated) {
+				break
+			}
+			return "", 0
+		}
+	}
+	return name, 0.95
+}
+
+var (
+	detectModelsOnce sync.Once
+
+	// singleByteModels holds a model for each language of each of the
+	// singleByteCandidates.
+	singleByteModels [][]*byteModel
+
+	// multiByteTables holds the encoded form of the frequentCharacters
+	// for each of the multiByteCandidates.
+	multiByteTables []map[string]bool
+)
+
+// loadDetectModels encodes the frequency tables with each candidate encoding.
+// It's done on first use, since the codecs must all be registered first.
+func loadDetectModels() {
+	detectModelsOnce.Do(func() {
+		singleByteModels = make([][]*byteModel, len(singleByteCandidates))
+		for i, c := range singleByteCandidates {
+			for _, lang := range c.languages {
+				m := newByteModel(c.encoding, letterFrequencies[lang])
+				if m != nil {
+					singleByteModels[i] = append(singleByteModels[i], m)
+				}
+			}
+		}
+
+		multiByteTables = make([]map[string]bool, len(multiByteCandidates))
+		for i, c := range multiByteCandidates {
+			multiByteTables[i] = encodeFrequent(c.encoding, frequentCharacters[c.language])
+		}
+	})
+}
+
+// Probabilities given to bytes a byteModel doesn't expect. unexpectedByte is
+// for bytes that decode to a character the language doesn't use, and
+// invalidByte for bytes that don't decode to a printable character at all.
+const (
+	unexpectedByte = 1e-3
+	invalidByte    = 1e-6
+)
+
+// byteModel is the expected frequency of bytes 0x80-0xFF in one language
+// written in one single-byte encoding. ASCII is ignored since every candidate
+// encoding agrees on it.
+type byteModel struct {
+	// logp is the log of the probability of each byte, indexed from
+	// 0x80.
+	logp [128]float64
+}
+
+// newByteModel builds a byteModel from the letter frequencies of a language.
+// Returns nil if the encoding isn't registered.
+func newByteModel(encoding string, freq map[rune]float64) *byteModel {
+	d := GetDecoder(encoding)
+	e := GetEncoder(encoding)
+	if d == nil || e == nil {
+		return nil
+	}
+
+	var weights [128]float64
+	add := func(r rune, f float64) {
+		var buf bytes.Buffer
+		if e.Encode(&buf, r) != nil || buf.Len() != 1 || buf.Bytes()[0] < 0x80 {
+			return
+		}
+		weights[buf.Bytes()[0]-0x80] += f
+	}
+	for r, f := range freq {
+		add(r, f*(1-capitalShare))
+		if u := unicode.ToUpper(r); u != r {
+			add(u, f*capitalShare)
+		}
+	}
+
+	// Probabilities are relative to the letters alone, so encodings that
+	// place the letters at the same bytes score text that only uses those
+	// letters the same, and the earlier candidate wins.
+	var letters float64
+	for _, w := range weights {
+		letters += w
+	}
+	if letters == 0 {
+		return nil
+	}
+
+	for r, f := range punctuationFrequencies {
+		add(r, f)
+	}
+
+	m := &byteModel{}
+	for i, w := range weights {
+		if w > 0 {
+			p := w / letters
+			m.logp[i] = math.Log(p)
+			continue
+		}
+
+		char, err := d.Decode(bytes.NewReader([]byte{byte(i + 0x80)}))
+		if err != nil || !unicode.IsPrint(char) {
+			m.logp[i] = math.Log(invalidByte)
+		} else {
+			m.logp[i] = math.Log(unexpectedByte)
+		}
+	}
+	return m
+}
+
+// score returns a confidence that text with the given byte counts matches the
+// model. It's based on the Kullback-Leibler divergence of the byte frequencies
+// in the text from the model, which is 0 for a perfect match.
+func (m *byteModel) score(counts *[256]int) float64 {
+	var n int
+	for _, c := range counts[0x80:] {
+		n += c
+	}
+	if n == 0 {
+		return 0
+	}
+
+	var divergence float64
+	for i, lp := range m.logp {
+		c := counts[i+0x80]
+		if c == 0 {
+			continue
+		}
+		q := float64(c) / float64(n)
+		divergence += q * (math.Log(q) - lp)
+	}
+
+	// Statistics are never as sure as valid UTF-8, so stay below it.
+	return 0.9 * math.Exp(-divergence) * certainty(n)
+}
+
+// encodeFrequent returns the encoded form of each of chars that takes more
+// than one byte in the named encoding.
+func encodeFrequent(encoding string, chars string) map[string]bool {
+	e := GetEncoder(encoding)
+	if e == nil {
+		return nil
+	}
+
+	table := map[string]bool{}
+	for _, r := range chars {
+		var buf bytes.Buffer
+		if e.Encode(&buf, r) == nil && buf.Len() > 1 {
+			table[buf.String()] = true
+		}
+	}
+	return table
+}
+
+// frequentShare is the share of the multi-byte characters in text that are
+// expected to be among the frequentCharacters of its language. Text decoded
+// with the wrong encoding turns up frequent characters too, since encodings
+// tend to put the most common characters first, so scores are based on the
+// odds of a character being frequent rather than the share.
+const frequentShare = 0.8
+
+// scoreMultiByte returns a confidence that sample is in the named multi-byte
+// encoding. The sample must be valid, apart from the occasional error, and
+// the multi-byte characters in it are looked up in table, which holds the
+// byte sequences of the most frequent characters in the language.
+func scoreMultiByte(encoding string, table map[string]bool, sample []byte, truncated bool) float64 {
+	d := GetDecoder(encoding)
+	if d == nil || table == nil {
+		return 0
+	}
+
+	r := bytes.NewReader(sample)
+	var chars, frequent, errs int
+	start := 0
+	for {
+		_, err := d.Decode(r)
+		if err == io.EOF {
+			break
+		}
+
+		end := len(sample) - r.Len()
+		if b, ok := d.(buffered); ok {
+			end -= b.Buffered()
+		}
+		seq := sample[start:end]
+		start = end
+
+		if err != nil {
+			var de *DecodeError
+			if !errors.As(err, &de) {
+				return 0
+			}
+			if truncated && r.Len() == 0 && errors.Is(err, ErrTruncated) {
+				break
+			}
+
+			// A few errors may be corruption, more means this
+			// isn't the encoding. Either way they count as
+			// characters that aren't frequent.
+			errs++
+			if errs > 2+len(sample)/1000 {
+				return 0
+			}
+			chars++
+			continue
+		}
+
+		if len(seq) > 1 {
+			chars++
+			if table[string(seq)] {
+				frequent++
+			}
+		}
+	}
+	if chars == 0 {
+		return 0
+	}
+
+	share := float64(frequent) / float64(chars)
+	odds := share / (1 - share) / (frequentShare / (1 - frequentShare))
+	return 0.95 * math.Min(1, odds) * certainty(chars)
+}
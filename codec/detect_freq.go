@@ -0,0 +1,240 @@
+package codec
+
+// The tables Detect uses to recognise legacy encodings. None of them come from
+// the text Detect is tested with.
+
+// letterFrequencies gives the frequency of the letters in each language that
+// aren't in ASCII, as a percentage of all letters. Languages written in Latin
+// script only list their accented letters. The figures are rounded from
+// published letter frequency tables. Capitals aren't listed, they're assumed
+// to make up capitalShare of each letter.
+var letterFrequencies = map[string]map[rune]float64{
+	"fr": {
+		'à': 0.486, 'â': 0.051, 'ç': 0.085, 'è': 0.271, 'é': 1.504, 'ê': 0.218,
+		'ë': 0.008, 'î': 0.045, 'ï': 0.005, 'ô': 0.023, 'ù': 0.058, 'û': 0.060,
+		'œ': 0.018,
+	},
+	"de": {
+		'ä': 0.578, 'ö': 0.443, 'ß': 0.307, 'ü': 0.995,
+	},
+	"es": {
+		'á': 0.502, 'é': 0.433, 'í': 0.725, 'ñ': 0.311, 'ó': 0.827, 'ú': 0.168,
+		'ü': 0.012,
+	},
+	"pt": {
+		'à': 0.072, 'á': 0.118, 'â': 0.562, 'ã': 0.733, 'ç': 0.530, 'é': 0.337,
+		'ê': 0.450, 'í': 0.132, 'ó': 0.296, 'ô': 0.635, 'õ': 0.040, 'ú': 0.207,
+		'ü': 0.026,
+	},
+	"it": {
+		'à': 0.635, 'è': 0.263, 'é': 0.043, 'ì': 0.030, 'ò': 0.002, 'ù': 0.166,
+	},
+	"sv": {
+		'å': 1.338, 'ä': 1.797, 'é': 0.010, 'ö': 1.305,
+	},
+	"da": {
+		'å': 1.190, 'æ': 0.872, 'é': 0.010, 'ø': 0.939,
+	},
+	"cs": {
+		'á': 0.867, 'č': 0.462, 'ď': 0.015, 'é': 0.633, 'ě': 1.222, 'í': 1.643,
+		'ň': 0.007, 'ó': 0.024, 'ř': 0.380, 'š': 0.688, 'ť': 0.006, 'ú': 0.045,
+		'ů': 0.204, 'ý': 0.995, 'ž': 0.721,
+	},
+	"sk": {
+		'á': 2.10, 'ä': 0.10, 'č': 0.90, 'ď': 0.20, 'é': 0.70, 'í': 1.60,
+		'ĺ': 0.01, 'ľ': 0.30, 'ň': 0.10, 'ó': 0.20, 'ô': 0.20, 'ŕ': 0.01,
+		'š': 0.80, 'ť': 0.30, 'ú': 0.60, 'ý': 1.40, 'ž': 0.90,
+	},
+	"pl": {
+		'ą': 0.699, 'ć': 0.743, 'ę': 1.035, 'ł': 2.109, 'ń': 0.362, 'ó': 1.141,
+		'ś': 0.814, 'ź': 0.078, 'ż': 0.706,
+	},
+	"hu": {
+		'á': 3.40, 'é': 3.30, 'í': 0.60, 'ó': 0.90, 'ö': 1.00, 'ő': 0.90,
+		'ú': 0.30, 'ü': 0.50, 'ű': 0.40,
+	},
+	"hr": {
+		'č': 1.00, 'ć': 0.90, 'đ': 0.30, 'š': 1.00, 'ž': 0.70,
+	},
+	"tr": {
+		'ç': 1.463, 'ğ': 1.125, 'ı': 5.114, 'ö': 0.777, 'ş': 1.780, 'ü': 1.854,
+		'â': 0.050, 'î': 0.010, 'û': 0.010,
+	},
+	"lt": {
+		'ą': 0.60, 'č': 0.40, 'ę': 0.20, 'ė': 1.70, 'į': 1.00, 'š': 1.20,
+		'ų': 1.00, 'ū': 0.50, 'ž': 0.80,
+	},
+	"lv": {
+		'ā': 4.00, 'č': 0.30, 'ē': 1.80, 'ģ': 0.10, 'ī': 1.50, 'ķ': 0.20,
+		'ļ': 0.40, 'ņ': 0.40, 'š': 1.40, 'ū': 0.50, 'ž': 0.60,
+	},
+	"et": {
+		'ä': 1.00, 'õ': 1.00, 'ö': 0.20, 'ü': 1.00, 'š': 0.01, 'ž': 0.01,
+	},
+	"ru": {
+		'о': 10.97, 'е': 8.45, 'а': 8.01, 'и': 7.35, 'н': 6.70, 'т': 6.26,
+		'с': 5.47, 'р': 4.73, 'в': 4.54, 'л': 4.40, 'к': 3.49, 'м': 3.21,
+		'д': 2.98, 'п': 2.81, 'у': 2.62, 'я': 2.01, 'ы': 1.90, 'ь': 1.74,
+		'г': 1.70, 'з': 1.65, 'б': 1.59, 'ч': 1.44, 'й': 1.21, 'х': 0.97,
+		'ж': 0.94, 'ш': 0.73, 'ю': 0.64, 'ц': 0.48, 'щ': 0.36, 'э': 0.32,
+		'ф': 0.26, 'ъ': 0.04, 'ё': 0.04,
+	},
+	"uk": {
+		'о': 9.40, 'а': 7.90, 'н': 6.80, 'и': 6.00, 'і': 5.60, 'т': 5.10,
+		'в': 5.00, 'е': 4.80, 'р': 4.60, 'с': 4.20, 'к': 3.80, 'л': 3.60,
+		'у': 3.30, 'д': 3.20, 'м': 3.20, 'п': 2.80, 'я': 2.30, 'з': 2.20,
+		'ь': 1.80, 'г': 1.60, 'б': 1.60, 'ч': 1.30, 'й': 1.30, 'х': 1.00,
+		'ц': 0.90, 'ї': 0.90, 'ю': 0.80, 'ж': 0.80, 'ш': 0.80, 'щ': 0.50,
+		'є': 0.40, 'ф': 0.20, 'ґ': 0.01,
+	},
+	"bg": {
+		'а': 9.50, 'о': 9.00, 'е': 8.00, 'и': 8.00, 'т': 7.00, 'н': 6.50,
+		'р': 5.00, 'с': 4.50, 'в': 4.00, 'к': 3.50, 'д': 3.30, 'л': 3.20,
+		'п': 2.90, 'м': 2.80, 'я': 2.30, 'ъ': 1.90, 'з': 1.80, 'г': 1.50,
+		'у': 1.40, 'б': 1.30, 'ч': 1.20, 'ц': 0.80, 'ж': 0.70, 'х': 0.60,
+		'ш': 0.40, 'щ': 0.40, 'й': 0.30, 'ф': 0.30, 'ю': 0.20, 'ь': 0.05,
+	},
+	"el": {
+		'α': 10.00, 'ά': 1.70, 'β': 0.80, 'γ': 1.80, 'δ': 1.70, 'ε': 7.00,
+		'έ': 1.40, 'ζ': 0.40, 'η': 4.20, 'ή': 1.20, 'θ': 1.30, 'ι': 7.20,
+		'ί': 1.80, 'ϊ': 0.10, 'ΐ': 0.02, 'κ': 4.00, 'λ': 2.80, 'μ': 3.40,
+		'ν': 6.60, 'ξ': 0.40, 'ο': 8.00, 'ό': 2.00, 'π': 4.30, 'ρ': 4.50,
+		'σ': 4.20, 'ς': 2.60, 'τ': 8.40, 'υ': 3.90, 'ύ': 0.70, 'ϋ': 0.02,
+		'φ': 0.80, 'χ': 1.20, 'ψ': 0.20, 'ω': 1.40, 'ώ': 0.70,
+	},
+	"he": {
+		'י': 11.00, 'ו': 10.00, 'ה': 9.00, 'ל': 7.00, 'א': 6.00, 'מ': 5.00,
+		'ר': 5.00, 'ב': 5.00, 'ת': 5.00, 'ש': 4.00, 'נ': 4.00, 'ם': 3.00,
+		'ד': 3.00, 'כ': 2.50, 'ע': 2.50, 'ח': 2.00, 'ק': 2.00, 'פ': 1.50,
+		'ן': 1.50, 'ס': 1.00, 'ג': 1.00, 'ט': 1.00, 'ז': 0.80, 'צ': 0.80,
+		'ך': 0.50, 'ף': 0.30, 'ץ': 0.10,
+	},
+	"ar": {
+		'ا': 12.00, 'ل': 11.00, 'ي': 7.00, 'م': 6.00, 'و': 6.00, 'ن': 6.00,
+		'ر': 4.50, 'ه': 4.00, 'ت': 4.00, 'ب': 3.50, 'ع': 3.00, 'ة': 3.00,
+		'د': 2.50, 'ف': 2.50, 'أ': 2.50, 'ق': 2.00, 'س': 2.00, 'ك': 2.00,
+		'ح': 1.50, 'إ': 1.00, 'ج': 1.00, 'ش': 1.00, 'ص': 1.00, 'ى': 1.00,
+		'ط': 0.70, 'خ': 0.60, 'ذ': 0.60, 'ث': 0.50, 'ض': 0.50, 'ء': 0.50,
+		'ئ': 0.40, 'ز': 0.40, 'غ': 0.30, 'ظ': 0.20, 'ؤ': 0.20, 'آ': 0.10,
+	},
+	"th": {
+		'า': 8.00, 'น': 6.00, 'ร': 5.50, 'อ': 5.00, 'ก': 4.50, 'เ': 4.50,
+		'่': 4.50, 'ม': 3.50, 'ง': 3.50, 'ั': 3.00, '้': 3.00, 'ย': 3.00,
+		'ว': 3.00, 'ด': 2.80, 'ท': 2.80, 'ี': 2.50, 'ิ': 2.50, 'ล': 2.50,
+		'ต': 2.20, 'ส': 2.20, 'บ': 2.00, 'ค': 2.00, 'ห': 2.00, 'ป': 1.80,
+		'จ': 1.50, 'ะ': 1.50, 'แ': 1.50, 'พ': 1.50, 'ุ': 1.50, 'ไ': 1.20,
+		'ข': 1.20, 'ใ': 1.00, 'ช': 1.00, 'ำ': 1.00, '์': 0.90, 'ู': 0.80,
+		'ื': 0.80, '็': 0.80, 'ึ': 0.60, 'โ': 0.60, 'ผ': 0.50, 'ธ': 0.50,
+		'ถ': 0.50, 'ณ': 0.50, 'ภ': 0.40, 'ซ': 0.40, 'ศ': 0.30, 'ญ': 0.30,
+		'ษ': 0.20, 'ฟ': 0.20, 'ๆ': 0.20, 'ฉ': 0.10, 'ฝ': 0.10, 'ฐ': 0.10,
+		'ฮ': 0.10, '๊': 0.10, 'ฏ': 0.05, 'ฎ': 0.05, 'ฑ': 0.05, 'ฒ': 0.05,
+		'๋': 0.05, 'ฬ': 0.02,
+	},
+}
+
+// punctuationFrequencies is the frequency, as a percentage of letters, of
+// punctuation outside ASCII that turns up in most languages.
+var punctuationFrequencies = map[rune]float64{
+	'\u00a0': 0.02, '«': 0.03, '»': 0.03, '‘': 0.02, '’': 0.10, '‚': 0.01,
+	'“': 0.05, '”': 0.05, '„': 0.02, '–': 0.05, '—': 0.05, '…': 0.02,
+	'€': 0.01, '°': 0.01,
+}
+
+// capitalShare is the proportion of each letter assumed to be written as a
+// capital.
+const capitalShare = 0.03
+
+// frequentCharacters lists the characters that make up most of the text in
+// each of the languages written with multi-byte encodings:
+//
+//   - ja is the hiragana and katakana and the 240 kanji taught in the first
+//     two years of Japanese primary school.
+//   - ko is a few hundred of the most frequent Hangul syllables.
+//   - zh is the 1,500 or so most frequent characters in Jun Da's Modern
+//     Chinese character frequency list, with their traditional forms.
+var frequentCharacters = map[string]string{
+	"ja": "ぁあぃいぅうぇえぉおかがきぎくぐけげこごさざしじすずせぜそぞただちぢっつづてでとどなにぬねのはばぱひびぴふぶぷへべぺほぼぽまみむめもゃやゅゆょよらりるれろゎわゐゑをん" +
+		"ァアィイゥウェエォオカガキギクグケゲコゴサザシジスズセゼソゾタダチヂッツヅテデトドナニヌネノハバパヒビピフブプヘベペホボポマミムメモャヤュユョヨラリルレロヮワヰヱヲンヴヵヶー" +
+		"一右雨円王音下火花貝学気九休玉金空月犬見五口校左三山子四糸字耳七車手十出女小上森" +
+		"人水正生青夕石赤千川先早草足村大男竹中虫町天田土二日入年白八百文木本名目立力林六" +
+		"引羽雲園遠何科夏家歌画回会海絵外角楽活間丸岩顔汽記帰弓牛魚京強教近兄形計元言原戸" +
+		"古午後語工公広交光考行高黄合谷国黒今才細作算止市矢姉思紙寺自時室社弱首秋週春書少" +
+		"場色食心新親図数西声星晴切雪船線前組走多太体台地池知茶昼長鳥朝直通弟店点電刀冬当" +
+		"東答頭同道読内南肉馬売買麦半番父風分聞米歩母方北毎妹万明鳴毛門夜野友用曜来里理話",
+	"ko": "이다는의에하고가을지기로한서사어도리자인들를시나있대정일수해전게보아만상주국것년" +
+		"장제부과라생성와요그여우적문동까면내경중러비소무용위신구회되원운학화실거관세물진" +
+		"공히저니며연말했데치개방발께분모르마간안식오미계할선유결통없후민현조작영입던야산" +
+		"같명업았음단었속각체날반점람교래더금될본두새외초또받심행드표때였목활건합근차터청" +
+		"임복월법집직려된살종특남준강함형처양불설석권군호감님파배증련락녀왔습쪽번필토료품" +
+		"망편매육록순난승험최판술색올림런늘머못많놓좋알왜얼잘약억격향허혼희힘투태택타크프" +
+		"피평씨쓰삼십둘네셋넷엇겠테럼렇떻떤렸갔봤줬버친너누디언",
+	"zh": "的一是不了在人有我他这個个们們中来來上大为為和国國地到以说說时時要就出会會可也你" +
+		"对對生能而子那得于於着著下自之年过過发發后後作里裡用道行所然家种種事成方多经經么" +
+		"麼去法学學如都同现現当當没沒动動面起看定天分还還进進好小部其些主样樣理心她本前开" +
+		"開但因只从從想实實日军軍者意无無力它与與长長把机機十民第公此已工使情明性知全三又" +
+		"关關点點正业業外将將两兩高间間由问問很最重并物手应應战戰向头頭文体體政美相见見被" +
+		"利什二等产產或新己制身果加西斯月话話合回特代内內信表化老给給世位次度门門任常先海" +
+		"通教儿兒原东東声聲提立及比员員解水名真论論处處走义義各入几幾口认認条條平系气氣题" +
+		"題活尔爾更别別打女变變四神总總何电電数數安少报報才结結反受目太量再感建务務做接必" +
+		"场場件计計管期市直德资資命山金指克许許统統区區保至队隊形社便空决決治展马馬科司五" +
+		"基眼书書非则則听聽白却界达達光放强強即像难難且权權思王象完设設式色路记記南品住告" +
+		"类類求据據程北边邊死张張该該交规規万萬取拉格望觉覺术術领領共确確传傳师師观觀清今" +
+		"切院让讓识識候带帶导導争爭运運笑飞飛风風步改收根干造言联聯持组組每济濟车車亲親极" +
+		"極林服快办辦议議往元英士证證近失转轉夫令准准布始怎呢存未远遠叫台单單影具罗羅字爱" +
+		"愛击擊流备備兵连連调調深商算质質团團集百需价價花党黨华華城石级級整府离離况況亚亞" +
+		"请請技际際约約示复復病息究线線似官火断斷精满滿支视視消越器容照须須九增研写寫称稱" +
+		"企八功吗嗎包片史委乎查轻輕易早曾除农農找装裝广廣显顯吧阿李标標谈談吃图圖念六引历" +
+		"歷首医醫局突专專费費号號尽盡另周较較注语語仅僅考落青随隨选選列武红紅响響虽雖推势" +
+		"勢参參希古众眾构構房半节節土投某案黑维維革划劃敌敵致陈陳律足态態护護七兴興派孩验" +
+		"驗责責营營星够夠章音跟志底站严嚴巴例防族供效续續施留讲講型料终終答紧緊黄黃绝絕奇" +
+		"察母京段依批群项項故按河米围圍江织織害斗双雙境客纪紀采举舉杀殺攻父苏蘇密低朝友诉" +
+		"訴止细細愿千值仍男钱錢破网網热熱助倒育属屬坐帝限船脸臉职職速刻乐樂否刚剛威毛状狀" +
+		"率甚独獨球般普怕弹彈校苦创創假久错錯承印晚兰蘭试試股拿脑腦预預谁誰益阳陽若哪微尼" +
+		"继繼送急血惊驚伤傷素药藥适適波夜省初喜卫衛源食险險待述陆陸习習置居劳勞财財环環排" +
+		"福纳納欢歡雷警获獲模充负負云雲停木游龙龍树樹疑层層冷洲冲衝射略范範竟句室异異激汉" +
+		"漢村哈策演简簡卡罪判担擔州静靜退既衣您宗积積余痛检檢差富灵靈协協角占配征修皮挥揮" +
+		"胜勝降阶階审審沉坚堅善妈媽刘劉读讀啊超免压壓银銀买買皇养養伊怀懷执執副乱亂抗犯追" +
+		"帮幫宣佛岁歲航优優怪香著田铁鐵控税稅左右份穿艺藝背阵陣草脚腳概恶惡块塊顿頓敢守酒" +
+		"岛島托央户戶烈洋哥索胡款靠评評版宝寶座释釋景顾顧弟登货貨互付伯慢欧歐换換闻聞危忙" +
+		"核暗姐介坏壞讨討丽麗良序升监監临臨亮露永呼味野架域沙掉括舰艦鱼魚杂雜误誤湾灣吉减" +
+		"減编編楚肯测測败敗屋跑梦夢散温溫困剑劍渐漸封救贵貴枪槍缺楼樓县縣尚毫移娘朋画畫班" +
+		"智亦耳恩短掌恐遗遺固席松秘谢謝鲁魯遇康虑慮幸均销銷钟鐘诗詩藏赶趕剧劇票损損忽巨炮" +
+		"旧舊端探湖录錄叶葉春乡鄉附吸予礼禮港雨呀板庭妇婦归歸睛饭飯额額含顺順输輸摇搖招婚" +
+		"脱脫补補谓謂督毒油疗療旅泽澤材灭滅逐莫笔筆亡鲜鮮词詞圣聖择擇寻尋厂廠睡博勒烟煙授" +
+		"诺諾伦倫岸奥奧唐卖賣俄炸载載洛健堂旁宫宮喝借君禁阴陰园園谋謀宋避抓荣榮姑孙孫逃牙" +
+		"束跳顶頂玉镇鎮雪午练練迫爷爺篇肉嘴馆館遍凡础礎洞卷坦牛宁寧纸紙诸諸训訓私庄莊祖丝" +
+		"絲翻暴森塔默握戏戲隐隱熟骨访訪弱蒙歌店鬼软軟典欲萨薩伙遭盘盤爸扩擴盖蓋弄雄稳穩忘" +
+		"亿億刺拥擁徒姆杨楊齐齊赛賽趣曲刀床迎冰虚虛玩析窗醒妻透购購替塞努休虎扬揚途侵刑绿" +
+		"綠兄迅套贸貿毕畢唯谷轮輪库庫迹跡尤竞競街促延震弃棄甲伟偉麻川申缓緩潜潛闪閃售灯燈" +
+		"针針哲络絡抵朱埃抱鼓植纯純夏忍页頁杰筑折郑鄭贝貝尊吴吳秀混臣雅振染盛怒舞圆圓搞狂" +
+		"措姓残殘秋培迷诚誠宽寬宇猛摆擺梅毁毀伸摩盟末乃悲拍丁赵趙硬麦麥操耶阻订訂彩抽赞贊" +
+		"魔纷紛沿喊违違妹浪汇匯币幣丰豐蓝藍殊献獻桌啦瓦莱萊援译譯夺奪汽烧燒距裁偏符勇触觸" +
+		"课課敬哭懂墙牆袭襲召罚罰侠俠厅廳拜巧侧側韩韓冒债債曼融惯慣享戴童犹猶乘挂掛奖獎绍" +
+		"紹厚纵縱障讯訊涉彻徹刊丈爆乌烏役描洗玛瑪患妙镜鏡唱烦煩签簽仙彼弗症仿倾傾牌陷鸟鳥" +
+		"轰轟咱菜闭閉奋奮庆慶撤泪淚茶疾缘緣播朗杜奶季丹狗尾仪儀偷奔珠虫蟲驻駐孔宜艾桥橋淡" +
+		"翼恨繁寒伴叹嘆旦愈潮粮糧缩縮罢罷聚径徑恰挑袋灰捕徐珍幕映裂泰隔启啟尖忠累炎暂暫估" +
+		"泛荒偿償横橫拒瑞忆憶孤鼻闹鬧羊呆厉厲衡胞零穷窮舍码碼赫婆魂灾災洪腿胆膽津俗辩辯胸" +
+		"晓曉劲勁贫貧仁偶辑輯邦恢赖賴圈摸仰润潤堆碰艇稍迟遲辆輛废廢净淨凶署壁御奉旋冬矿礦" +
+		"抬蛋晨伏吹鸡雞倍糊秦盾杯租骑騎乏隆诊診奴摄攝丧喪污渡旗甘耐凭憑扎抢搶绪緒粗肩梁幻" +
+		"菲皆碎宙叔岩荡蕩综綜爬荷悉蒂返井壮壯薄悄扫掃敏碍礙殖详詳迪矛霍允幅撒剩凯凱颗顆骂" +
+		"罵赏賞液番箱贴貼漫酸郎腰舒眉忧憂浮辛恋戀餐吓嚇挺励勵辞辭艘键鍵伍峰尺昨黎辈輩贯貫" +
+		"侦偵滑券崇扰擾宪憲绕繞趋趨慈乔喬阅閱汗枝拖墨胁脅插箭腊臘粉泥氏彭拔骗騙凤鳳慧媒佩" +
+		"愤憤扑撲龄齡驱驅惜豪掩兼跃躍尸屍肃肅帕驶駛堡届屆欣惠册冊储儲飘飄桑闲閒惨慘洁潔踪" +
+		"蹤勃宾賓频頻仇磨递遞邪撞拟擬滚滾奏巡颜顏剂劑绩績贡貢疯瘋坡瞧截燃焦殿伪偽柳锁鎖逼" +
+		"颇頗昏劝勸呈搜勤戒驾駕漂饮飲曹朵仔柔俩倆孟腐幼践踐籍牧凉涼牲佳娜浓濃芳稿竹腹跌逻" +
+		"邏垂遵脉脈貌柏狱獄猜怜憐惑陶兽獸帐帳饰飾贷貸昌叙敘躺钢鋼沟溝寄扶铺鋪邓鄧寿壽惧懼" +
+		"询詢汤湯盗盜肥尝嘗匆辉輝奈扣廷澳嘛董迁遷凝慰厌厭脏髒腾騰幽怨鞋丢丟埋泉涌辖轄躲晋" +
+		"晉紫艰艱魏吾慌祝邮郵吐狠鉴鑑曰械咬邻鄰赤挤擠弯彎椅陪割揭韦韋悟聪聰雾霧锋鋒梯猫貓" +
+		"祥阔闊誉譽筹籌丛叢牵牽鸣鳴沈阁閣穆屈旨袖猎獵臂蛇贺賀柱抛拋鼠瑟戈牢逊遜迈邁欺吨噸" +
+		"琴衰瓶恼惱燕仲诱誘狼池疼卢盧仗冠粒遥遙吕呂玄尘塵冯馮抚撫浅淺敦纠糾钻鑽晶岂豈峡峽" +
+		"苍蒼喷噴耗凌敲菌赔賠涂塗粹扁亏虧寂煤熊恭湿濕循暖糖赋賦抑秩帽哀宿踏烂爛袁侯抖夹夾" +
+		"昆肝擦猪豬炼煉恒恆慎搬纽紐纹紋玻渔漁磁铜銅齿齒跨押怖漠疲叛遣兹茲祭醉拳弥彌斜档檔" +
+		"稀捷肤膚疫肿腫豆削岗崗晃吞宏癌肚隶隸履涨漲耀扭坛壇拨撥沃绘繪伐堪仆郭牺犧歼殲墓雇" +
+		"廉契拼惩懲捉覆刷劫嫌瓜歇雕闷悶乳串娃缴繳唤喚赢贏莲蓮霸桃妥瘦搭赴岳嘉舱艙俊址庞龐" +
+		"耕锐銳缝縫悔邀玲惟斥宅添挖呵讼訟氧浩羽斤酷掠妖祸禍侍乙妨贪貪挣掙汪尿莉悬懸唇翰仓" +
+		"倉轨軌枚盐鹽览覽傅帅帥庙廟芬屏寺胖璃愚滴疏萧蕭姿颤顫丑劣柯寸扔盯辱匹俱辨饿餓蜂哦" +
+		"腔郁溃潰谨謹糟葛苗肠腸忌溜鸿鴻爵鹏鵬鹰鷹笼籠丘桂滋聊挡擋纲綱肌茨壳殼痕碗穴膀卓贤" +
+		"賢卧臥膜毅锦錦欠哩函茫昂薛皱皺夸誇豫胃舌剥剝傲拾窝窩睁睜携攜陵哼棉晴铃鈴填饲飼渴" +
+		"吻扮逆脆喘罩卜炉爐柴愉绳繩胎蓄眠竭喂傻慕浑渾奸扇柜櫃悦悅拦攔诞誕饱飽乾泡贼賊亭夕" +
+		"爹酬儒姻卵氛泄杆挨僧蜜吟猩遂狭狹肖甜霜揉咳浸蓬崩荐薦",
+}
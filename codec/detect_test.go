@@ -0,0 +1,149 @@
+package codec
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+// TestDetectCorpus checks Detect against the files in testdata/detect. Each
+// directory is named for the encoding of the files in it, and each file is a
+// few paragraphs of text named for its language. The text was written for
+// this test, so it has nothing in common with the tables Detect uses.
+func TestDetectCorpus(t *testing.T) {
+	dirs, err := ioutil.ReadDir(filepath.Join("testdata", "detect"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, dir := range dirs {
+		if !dir.IsDir() {
+			continue
+		}
+
+		encoding := dir.Name()
+		t.Run(encoding, func(t *testing.T) {
+			c := Lookup(encoding)
+			if c == nil {
+				t.Fatalf("%s is not registered", encoding)
+			}
+
+			files, err := filepath.Glob(filepath.Join("testdata", "detect", encoding, "*.txt"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, file := range files {
+				in, err := ioutil.ReadFile(file)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				name, confidence, err := Detect(bytes.NewReader(in))
+				if err != nil {
+					t.Errorf("%s: detect error: %v", file, err)
+					continue
+				}
+
+				if name != c.Name {
+					t.Errorf("%s: got %s (%.2f), want %s", file, name, confidence, c.Name)
+				}
+			}
+		})
+	}
+}
+
+// TestDetectCandidates checks that every encoding Detect considers is
+// registered and has something to score it with.
+func TestDetectCandidates(t *testing.T) {
+	loadDetectModels()
+
+	for i, c := range singleByteCandidates {
+		if Lookup(c.encoding) == nil {
+			t.Errorf("%s is not registered", c.encoding)
+			continue
+		}
+		if len(singleByteModels[i]) != len(c.languages) {
+			t.Errorf("%s: got %d models, want %d", c.encoding, len(singleByteModels[i]), len(c.languages))
+		}
+	}
+
+	for i, c := range multiByteCandidates {
+		if Lookup(c.encoding) == nil {
+			t.Errorf("%s is not registered", c.encoding)
+			continue
+		}
+		if len(multiByteTables[i]) == 0 {
+			t.Errorf("%s: no frequent characters", c.encoding)
+		}
+	}
+
+	for _, set := range iso2022Escapes {
+		if Lookup(set.encoding) == nil {
+			t.Errorf("%s is not registered", set.encoding)
+		}
+	}
+}
+
+func TestDetect(t *testing.T) {
+	cases := []struct {
+		in         []byte
+		expected   string
+		confidence float64
+	}{
+		{
+			in:         []byte{},
+			expected:   "UTF-8",
+			confidence: 0,
+		},
+		{
+			in:         []byte("plain ASCII text\n"),
+			expected:   "UTF-8",
+			confidence: 0.5,
+		},
+		{
+			in:         []byte("\xef\xbb\xbfx"),
+			expected:   "UTF-8",
+			confidence: 1,
+		},
+		{
+			in:         []byte{0xff, 0xfe, 0x78, 0x00},
+			expected:   "UTF-16",
+			confidence: 1,
+		},
+		{
+			in:         []byte{0xff, 0xfe, 0x00, 0x00, 0x78, 0x00, 0x00, 0x00},
+			expected:   "UTF-32",
+			confidence: 1,
+		},
+	}
+
+	for _, c := range cases {
+		name, confidence, err := Detect(bytes.NewReader(c.in))
+		if err != nil {
+			t.Errorf("%q: detect error: %v", c.in, err)
+			continue
+		}
+
+		if name != c.expected || confidence != c.confidence {
+			t.Errorf("%q: got %s (%v), want %s (%v)", c.in, name, confidence, c.expected, c.confidence)
+		}
+	}
+}
+
+func TestDetectLongInput(t *testing.T) {
+	// Split a character at the end of the sample, Detect should still
+	// find UTF-8.
+	in := bytes.Repeat([]byte("é"), detectSampleSize)
+	in = append([]byte{'x'}, in...)
+
+	name, _, err := Detect(bytes.NewReader(in))
+	if err != nil {
+		t.Fatalf("detect error: %v", err)
+	}
+
+	if name != "UTF-8" {
+		t.Errorf("got %s, want UTF-8", name)
+	}
+}
@@ -0,0 +1,89 @@
+package codec
+
+//go:generate go run gen_charmap.go -o ebcdic_tables.go -overrides mappings/OVERRIDES.TXT ibm037=mappings/VENDORS/MICSFT/EBCDIC/CP037.TXT ibm273=mappings/VENDORS/IBM/CP273.TXT ibm500=mappings/VENDORS/MICSFT/EBCDIC/CP500.TXT ibm875=mappings/VENDORS/MICSFT/EBCDIC/CP875.TXT ibm1026=mappings/VENDORS/MICSFT/EBCDIC/CP1026.TXT ibm1047=mappings/VENDORS/MICSFT/EBCDIC/CP037.TXT ibm1140=mappings/VENDORS/IBM/CP1140.TXT ibm1141=mappings/VENDORS/IBM/CP273.TXT ibm1148=mappings/VENDORS/MICSFT/EBCDIC/CP500.TXT
+
+// EBCDIC is the family of single-byte encodings used by IBM mainframes. It
+// isn't compatible with ASCII, even the letters and digits are in different
+// places. The CP1140 family are the older code pages with the euro sign
+// replacing the currency sign at 0x9F.
+//
+// Lines end with either LF (0x25) or NL (0x15), depending on where the text
+// came from. Options.Newline chooses which one decodes to U+000A.
+func init() {
+	registerEBCDIC("IBM037", []string{
+		// IANA
+		"cp037", "ebcdic-cp-us", "ebcdic-cp-ca", "ebcdic-cp-wt",
+		"ebcdic-cp-nl", "csIBM037",
+	}, &ibm037)
+	registerEBCDIC("IBM273", []string{
+		// IANA
+		"CP273", "csIBM273",
+		// Windows code page
+		"cp20273",
+	}, &ibm273)
+	registerEBCDIC("IBM500", []string{
+		// IANA
+		"CP500", "ebcdic-cp-be", "ebcdic-cp-ch", "csIBM500",
+	}, &ibm500)
+	registerEBCDIC("IBM875", []string{
+		// Windows code page
+		"cp875",
+	}, &ibm875)
+	registerEBCDIC("IBM1026", []string{
+		// IANA
+		"CP1026", "csIBM1026",
+	}, &ibm1026)
+	registerEBCDIC("IBM1047", []string{
+		// IANA
+		"IBM-1047", "csIBM1047",
+		// Windows code page
+		"cp1047",
+	}, &ibm1047)
+	registerEBCDIC("IBM01140", []string{
+		// IANA
+		"CCSID01140", "CP01140", "ebcdic-us-37+euro", "csIBM01140",
+		// Windows code page
+		"cp1140", "IBM1140",
+	}, &ibm1140)
+	registerEBCDIC("IBM01141", []string{
+		// IANA
+		"CCSID01141", "CP01141", "ebcdic-de-273+euro", "csIBM01141",
+		// Windows code page
+		"cp1141", "IBM1141",
+	}, &ibm1141)
+	registerEBCDIC("IBM01148", []string{
+		// IANA
+		"CCSID01148", "CP01148", "ebcdic-international-500+euro",
+		"csIBM01148",
+		// Windows code page
+		"cp1148", "IBM1148",
+	}, &ibm1148)
+}
+
+// registerEBCDIC registers an EBCDIC codec that decodes with table, or with
+// 0x15 and 0x25 swapped if Options.Newline is EBCDICNextLine.
+func registerEBCDIC(name string, aliases []string, table *[256]rune) {
+	swapped := *table
+	swapped[0x15], swapped[0x25] = table[0x25], table[0x15]
+
+	lf := &charmap{name: name, decode: table}
+	nl := &charmap{name: name, decode: &swapped}
+	pick := func(opts []Options) *charmap {
+		if mergeOptions(opts).Newline == EBCDICNextLine {
+			return nl
+		}
+		return lf
+	}
+
+	registerCodec(Codec{
+		Name:    name,
+		Aliases: aliases,
+		NewDecoder: func(opts ...Options) Decoder {
+			return &CharmapDecoder{charmap: pick(opts)}
+		},
+		NewEncoder: func(opts ...Options) Encoder {
+			return &CharmapEncoder{charmap: pick(opts)}
+		},
+		Info: lf.info(),
+	})
+}
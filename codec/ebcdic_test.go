@@ -0,0 +1,139 @@
+package codec
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestEBCDIC(t *testing.T) {
+	cases := []struct {
+		encoding string
+		opts     Options
+		text     string
+		encoded  []byte
+	}{
+		{
+			encoding: "cp037",
+			text:     "Hello, [world]!\n",
+			encoded:  []byte{0xc8, 0x85, 0x93, 0x93, 0x96, 0x6b, 0x40, 0xba, 0xa6, 0x96, 0x99, 0x93, 0x84, 0xbb, 0x5a, 0x25},
+		},
+		{
+			encoding: "IBM1047",
+			text:     "a[i]^b\n\u0085",
+			encoded:  []byte{0x81, 0xad, 0x89, 0xbd, 0x5f, 0x82, 0x25, 0x15},
+		},
+		{
+			encoding: "IBM1047",
+			opts:     Options{Newline: EBCDICNextLine},
+			text:     "a[i]^b\n\u0085",
+			encoded:  []byte{0x81, 0xad, 0x89, 0xbd, 0x5f, 0x82, 0x15, 0x25},
+		},
+		{
+			encoding: "CP273",
+			text:     "Grüße",
+			encoded:  []byte{0xc7, 0x99, 0xd0, 0xa1, 0x85},
+		},
+		{
+			encoding: "CP500",
+			text:     "[x]¤",
+			encoded:  []byte{0x4a, 0xa7, 0x5a, 0x9f},
+		},
+		{
+			encoding: "cp1140",
+			text:     "5€",
+			encoded:  []byte{0xf5, 0x9f},
+		},
+		{
+			encoding: "cp1141",
+			text:     "5€",
+			encoded:  []byte{0xf5, 0x9f},
+		},
+		{
+			encoding: "cp1148",
+			text:     "[€]",
+			encoded:  []byte{0x4a, 0x9f, 0x5a},
+		},
+	}
+
+	for _, c := range cases {
+		actual := &bytes.Buffer{}
+		err := Recode(bytes.NewReader(c.encoded), actual, GetDecoder(c.encoding, c.opts), NewUTF8Encoder())
+		if err != nil {
+			t.Errorf("%s: decode error: %v", c.encoding, err)
+		} else if actual.String() != c.text {
+			t.Errorf("%s: got %q, want %q", c.encoding, actual.String(), c.text)
+		}
+
+		actual.Reset()
+		err = Recode(strings.NewReader(c.text), actual, NewUTF8Decoder(), GetEncoder(c.encoding, c.opts))
+		if err != nil {
+			t.Errorf("%s: encode error: %v", c.encoding, err)
+		} else if !bytes.Equal(actual.Bytes(), c.encoded) {
+			t.Errorf("%s: got % x, want % x", c.encoding, actual.Bytes(), c.encoded)
+		}
+	}
+}
+
+// TestEBCDICVariants checks which bytes differ between related code pages.
+func TestEBCDICVariants(t *testing.T) {
+	cases := []struct {
+		a, b     string
+		expected []byte
+	}{
+		{"IBM037", "IBM1047", []byte{0x5f, 0xad, 0xb0, 0xba, 0xbb, 0xbd}},
+		{"IBM037", "IBM01140", []byte{0x9f}},
+		{"IBM273", "IBM01141", []byte{0x9f}},
+		{"IBM500", "IBM01148", []byte{0x9f}},
+	}
+
+	for _, c := range cases {
+		da, db := GetDecoder(c.a), GetDecoder(c.b)
+
+		var actual []byte
+		for i := 0; i < 256; i++ {
+			in := []byte{byte(i)}
+			ra, err := da.Decode(bytes.NewReader(in))
+			if err != nil {
+				t.Fatalf("%s 0x%02x: %v", c.a, i, err)
+			}
+			rb, err := db.Decode(bytes.NewReader(in))
+			if err != nil {
+				t.Fatalf("%s 0x%02x: %v", c.b, i, err)
+			}
+			if ra != rb {
+				actual = append(actual, byte(i))
+			}
+		}
+
+		if !bytes.Equal(actual, c.expected) {
+			t.Errorf("%s and %s: got % x, want % x", c.a, c.b, actual, c.expected)
+		}
+	}
+}
+
+// TestNewlineOption checks that only the encodings listed in the Options
+// documentation use Newline.
+func TestNewlineOption(t *testing.T) {
+	expected := []string{"IBM037", "IBM273", "IBM500", "IBM875", "IBM1026", "IBM1047", "IBM01140", "IBM01141", "IBM01148"}
+
+	var actual []string
+	for _, name := range Names() {
+		c := Lookup(name)
+		if c.NewDecoder == nil {
+			continue
+		}
+		lf, err1 := c.NewDecoder().Decode(bytes.NewReader([]byte{0x15}))
+		nl, err2 := c.NewDecoder(Options{Newline: EBCDICNextLine}).Decode(bytes.NewReader([]byte{0x15}))
+		if lf != nl || (err1 == nil) != (err2 == nil) {
+			actual = append(actual, name)
+		}
+	}
+
+	sort.Strings(actual)
+	sort.Strings(expected)
+	if strings.Join(actual, " ") != strings.Join(expected, " ") {
+		t.Errorf("got %v, want %v", actual, expected)
+	}
+}
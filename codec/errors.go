@@ -0,0 +1,120 @@
+package codec
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrInvalidSequence means the input contains bytes that aren't valid
+	// in the encoding.
+	ErrInvalidSequence = errors.New("invalid byte sequence")
+
+	// ErrOutOfRange means a character is outside the range the encoding
+	// can represent.
+	ErrOutOfRange = errors.New("character out of range")
+
+	// ErrTruncated means the input ended part way through a character.
+	ErrTruncated = errors.New("truncated character")
+
+	// ErrLoneSurrogate means a UTF-16 surrogate code point was found
+	// outside of a valid surrogate pair.
+	ErrLoneSurrogate = errors.New("lone surrogate")
+
+	// ErrShortDst means the destination passed to DecodeBytes or
+	// EncodeRunes is full.
+	ErrShortDst = errors.New("short destination buffer")
+
+	// ErrShortSrc means the source passed to DecodeBytes ends part way
+	// through a character, and more input is needed.
+	ErrShortSrc = errors.New("short source buffer")
+)
+
+// Position locates a character in the input.
+//
+// Decoders and encoders don't know where they are in the input, so they return
+// errors with a zero Position. Recode, Reader and Writer fill it in.
+type Position struct {
+	// Offset is the number of bytes before the character.
+	Offset int64
+
+	// Index is the number of characters before the character. Invalid
+	// input counts as one character.
+	Index int64
+
+	// Line and Column start at 1. Lines are separated by '\n'. Line is 0
+	// if the position isn't known.
+	Line   int
+	Column int
+}
+
+func (p Position) String() string {
+	if p.Line == 0 {
+		return ""
+	}
+	return fmt.Sprintf("at offset %d (line %d, column %d)", p.Offset, p.Line, p.Column)
+}
+
+// DecodeError is returned by a Decoder when its input is invalid.
+//
+// The decoder consumes the invalid bytes before returning a DecodeError, so
+// the next call to Decode resumes with the input that follows them.
+type DecodeError struct {
+	Position
+
+	// Encoding is the name of the encoding being decoded.
+	Encoding string
+
+	// Bytes holds the invalid input.
+	Bytes []byte
+
+	// Err is one of ErrInvalidSequence, ErrOutOfRange, ErrTruncated or
+	// ErrLoneSurrogate.
+	Err error
+}
+
+func (e *DecodeError) Error() string {
+	return formatError(e.Encoding, fmt.Sprintf("%v (% x)", e.Err, e.Bytes), e.Position)
+}
+
+// Unwrap returns e.Err, so errors.Is can be used to check the cause.
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// EncodeError is returned by an Encoder when it can't represent a character.
+// Nothing is written for the character.
+type EncodeError struct {
+	Position
+
+	// Encoding is the name of the encoding being encoded.
+	Encoding string
+
+	// Rune is the character that couldn't be encoded.
+	Rune rune
+
+	// Err is ErrOutOfRange or ErrLoneSurrogate.
+	Err error
+}
+
+func (e *EncodeError) Error() string {
+	return formatError(e.Encoding, fmt.Sprintf("%v (%U)", e.Err, e.Rune), e.Position)
+}
+
+// Unwrap returns e.Err, so errors.Is can be used to check the cause.
+func (e *EncodeError) Unwrap() error {
+	return e.Err
+}
+
+func formatError(encoding, msg string, pos Position) string {
+	parts := make([]string, 0, 3)
+	if encoding != "" {
+		parts = append(parts, encoding+":")
+	}
+	parts = append(parts, msg)
+	if p := pos.String(); p != "" {
+		parts = append(parts, p)
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,219 @@
+package codec
+
+import "io"
+
+// EUC-JP encodes ASCII in single bytes, JIS X 0208 in two bytes from
+// 0xA1-0xFE, half-width katakana as 0x8E followed by 0xA1-0xDF, and JIS X 0212
+// as 0x8F followed by two bytes from 0xA1-0xFE.
+func init() {
+	registerCodec(Codec{
+		Name: "EUC-JP",
+		Aliases: []string{
+			// IANA
+			"Extended_UNIX_Code_Packed_Format_for_Japanese",
+			"csEUCPkdFmtJapanese",
+			// WHATWG
+			"x-euc-jp",
+		},
+		NewDecoder: func(...Options) Decoder {
+			return &EUCJPDecoder{}
+		},
+		NewEncoder: func(...Options) Encoder {
+			return &EUCJPEncoder{}
+		},
+		Info: charsetInfo(3, eucJPExtra(), jis0208Set, jis0212Set),
+	})
+}
+
+const (
+	// eucSS2 and eucSS3 are the single shifts, which take the next
+	// character from the third or fourth character set.
+	eucSS2 = 0x8e
+	eucSS3 = 0x8f
+)
+
+// eucJPExtra returns the characters EUC-JP can encode that aren't ASCII or in
+// a 94x94 set.
+func eucJPExtra() []rune {
+	extra := []rune{0xa5, 0x203e}
+	for r := rune(0xff61); r <= 0xff9f; r++ {
+		extra = append(extra, r)
+	}
+	return extra
+}
+
+var _ BulkDecoder = &EUCJPDecoder{}
+
+// EUCJPDecoder reads EUC-JP. Use GetDecoder to get one.
+type EUCJPDecoder struct {
+	in input
+}
+
+// Decode satisfies the Decoder interface for EUC-JP.
+func (d *EUCJPDecoder) Decode(r io.Reader) (rune, error) {
+	return d.in.decodeRune(r, "EUC-JP", decodeEUCJP)
+}
+
+// BulkDecoder satisfies the BulkDecoder interface for EUC-JP.
+func (d *EUCJPDecoder) BulkDecoder() BulkDecoder {
+	return d
+}
+
+// DecodeBytes satisfies the BulkDecoder interface for EUC-JP.
+func (d *EUCJPDecoder) DecodeBytes(dst []rune, src []byte, atEOF bool) (nDst, nSrc int, err error) {
+	return decodeBytes(dst, src, atEOF, "EUC-JP", decodeEUCJP)
+}
+
+// Buffered returns the number of bytes that have been read but not decoded.
+func (d *EUCJPDecoder) Buffered() int {
+	return d.in.Buffered()
+}
+
+// decodeEUCJP is the decodeFunc for EUC-JP.
+func decodeEUCJP(src []byte, atEOF bool) (rune, int, error) {
+	b := src[0]
+	switch {
+	case b < 0x80:
+		return rune(b), 1, nil
+	case b == eucSS2:
+		if len(src) < 2 {
+			return shortEUC(src, atEOF)
+		}
+		switch t := src[1]; {
+		case t >= 0xa1 && t <= 0xdf:
+			return 0xff61 + rune(t-0xa1), 2, nil
+		case isEUCByte(t):
+			return 0, 2, ErrInvalidSequence
+		default:
+			return eucTrailError(t, 2)
+		}
+	case b == eucSS3:
+		if len(src) < 3 {
+			if len(src) == 2 && !isEUCByte(src[1]) {
+				return eucTrailError(src[1], 2)
+			}
+			return shortEUC(src, atEOF)
+		}
+		if !isEUCByte(src[1]) {
+			return eucTrailError(src[1], 2)
+		}
+		if !isEUCByte(src[2]) {
+			return eucTrailError(src[2], 3)
+		}
+		if r := jis0212.char(src[1]&0x7f, src[2]&0x7f); r != 0 {
+			return r, 3, nil
+		}
+		return 0, 3, ErrInvalidSequence
+	case isEUCByte(b):
+		if len(src) < 2 {
+			return shortEUC(src, atEOF)
+		}
+		if !isEUCByte(src[1]) {
+			return eucTrailError(src[1], 2)
+		}
+		if r := jis0208.char(b&0x7f, src[1]&0x7f); r != 0 {
+			return r, 2, nil
+		}
+		return 0, 2, ErrInvalidSequence
+	default:
+		return 0, 1, ErrInvalidSequence
+	}
+}
+
+// isEUCByte reports whether b is one of the bytes of a character from a 94x94
+// set.
+func isEUCByte(b byte) bool {
+	return b >= 0xa1 && b <= 0xfe
+}
+
+// shortEUC is returned by EUC decoders when src ends part way through a
+// character.
+func shortEUC(src []byte, atEOF bool) (rune, int, error) {
+	if !atEOF {
+		return 0, 0, ErrShortSrc
+	}
+	return 0, len(src), ErrTruncated
+}
+
+// eucTrailError returns the number of bytes to skip and the error for an EUC
+// sequence that's invalid because of trail, the byte at position n-1. An ASCII
+// byte isn't skipped, so it can be decoded on its own.
+func eucTrailError(trail byte, n int) (rune, int, error) {
+	if trail < 0x80 {
+		return 0, n - 1, ErrInvalidSequence
+	}
+	return 0, n, ErrInvalidSequence
+}
+
+var _ BulkEncoder = &EUCJPEncoder{}
+
+// EUCJPEncoder writes EUC-JP. Use GetEncoder to get one.
+//
+// YEN SIGN and OVERLINE are encoded as 0x5C and 0x7E, since EUC-JP text often
+// uses them that way, though both decode as ASCII.
+type EUCJPEncoder struct{}
+
+// Encode satisfies the Encoder interface for EUC-JP.
+func (e *EUCJPEncoder) Encode(w io.Writer, r rune) error {
+	buf := make([]byte, 3)
+	n := encodeEUCJP(buf, r)
+	if n == 0 {
+		return &EncodeError{Encoding: "EUC-JP", Rune: r, Err: ErrOutOfRange}
+	}
+
+	_, err := w.Write(buf[:n])
+	return err
+}
+
+// BulkEncoder satisfies the BulkEncoder interface for EUC-JP.
+func (e *EUCJPEncoder) BulkEncoder() BulkEncoder {
+	return e
+}
+
+// EncodeRunes satisfies the BulkEncoder interface for EUC-JP.
+func (e *EUCJPEncoder) EncodeRunes(dst []byte, src []rune) (nDst, nSrc int, err error) {
+	for ; nSrc < len(src); nSrc++ {
+		if len(dst)-nDst < 3 {
+			return nDst, nSrc, ErrShortDst
+		}
+		n := encodeEUCJP(dst[nDst:], src[nSrc])
+		if n == 0 {
+			return nDst, nSrc, &EncodeError{Encoding: "EUC-JP", Rune: src[nSrc], Err: ErrOutOfRange}
+		}
+		nDst += n
+	}
+	return nDst, nSrc, nil
+}
+
+// encodeEUCJP writes r to buf, which must have room for 3 bytes, and returns
+// the number of bytes written, or 0 if r can't be encoded.
+func encodeEUCJP(buf []byte, r rune) int {
+	switch {
+	case r >= 0 && r < 0x80:
+		buf[0] = byte(r)
+		return 1
+	case r == 0xa5:
+		buf[0] = 0x5c
+		return 1
+	case r == 0x203e:
+		buf[0] = 0x7e
+		return 1
+	case r >= 0xff61 && r <= 0xff9f:
+		buf[0] = eucSS2
+		buf[1] = byte(r-0xff61) + 0xa1
+		return 2
+	}
+
+	if code, ok := jis0208Set.lookup(r); ok {
+		buf[0] = byte(code>>8) | 0x80
+		buf[1] = byte(code) | 0x80
+		return 2
+	}
+	if code, ok := jis0212Set.lookup(r); ok {
+		buf[0] = eucSS3
+		buf[1] = byte(code>>8) | 0x80
+		buf[2] = byte(code) | 0x80
+		return 3
+	}
+	return 0
+}
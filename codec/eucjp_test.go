@@ -0,0 +1,149 @@
+package codec
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestEUCJP(t *testing.T) {
+	cases := []struct {
+		text    string
+		encoded []byte
+	}{
+		{
+			text:    "日本語 ok",
+			encoded: []byte{0xc6, 0xfc, 0xcb, 0xdc, 0xb8, 0xec, 0x20, 0x6f, 0x6b},
+		},
+		{
+			// Half-width katakana
+			text:    "ｱｲｳﾟ",
+			encoded: []byte{0x8e, 0xb1, 0x8e, 0xb2, 0x8e, 0xb3, 0x8e, 0xdf},
+		},
+		{
+			// JIS X 0212 takes three bytes
+			text:    "丂鼂",
+			encoded: []byte{0x8f, 0xb0, 0xa1, 0x8f, 0xed, 0xa3},
+		},
+		{
+			// First and last codes of JIS X 0208
+			text:    "　熙",
+			encoded: []byte{0xa1, 0xa1, 0xf4, 0xa6},
+		},
+	}
+
+	for _, c := range cases {
+		actual := &bytes.Buffer{}
+		err := Recode(bytes.NewReader(c.encoded), actual, GetDecoder("EUC-JP"), NewUTF8Encoder())
+		if err != nil {
+			t.Errorf("decode error: %v", err)
+		} else if actual.String() != c.text {
+			t.Errorf("got %q, want %q", actual.String(), c.text)
+		}
+
+		actual.Reset()
+		err = Recode(strings.NewReader(c.text), actual, NewUTF8Decoder(), GetEncoder("EUC-JP"))
+		if err != nil {
+			t.Errorf("%q: encode error: %v", c.text, err)
+		} else if !bytes.Equal(actual.Bytes(), c.encoded) {
+			t.Errorf("%q: got % x, want % x", c.text, actual.Bytes(), c.encoded)
+		}
+	}
+
+	// YEN SIGN and OVERLINE are written as ASCII, but don't decode back.
+	actual := &bytes.Buffer{}
+	err := Recode(strings.NewReader("¥‾"), actual, NewUTF8Decoder(), GetEncoder("EUC-JP"))
+	if err != nil || actual.String() != `\~` {
+		t.Errorf("got %q, %v, want %q", actual.Bytes(), err, `\~`)
+	}
+}
+
+func TestEUCJPErrors(t *testing.T) {
+	cases := []struct {
+		in    []byte
+		bytes []byte
+		err   error
+		next  rune
+	}{
+		{
+			in:    []byte{0x80, 'A'},
+			bytes: []byte{0x80},
+			err:   ErrInvalidSequence,
+			next:  'A',
+		},
+		{
+			// The ASCII byte after the lead isn't skipped
+			in:    []byte{0xc6, 'A'},
+			bytes: []byte{0xc6},
+			err:   ErrInvalidSequence,
+			next:  'A',
+		},
+		{
+			in:    []byte{0xc6, 0xff, 'A'},
+			bytes: []byte{0xc6, 0xff},
+			err:   ErrInvalidSequence,
+			next:  'A',
+		},
+		{
+			// Row 9 of JIS X 0208 is empty
+			in:    []byte{0xa9, 0xa1, 'A'},
+			bytes: []byte{0xa9, 0xa1},
+			err:   ErrInvalidSequence,
+			next:  'A',
+		},
+		{
+			// Past the half-width katakana
+			in:    []byte{0x8e, 0xe0, 'A'},
+			bytes: []byte{0x8e, 0xe0},
+			err:   ErrInvalidSequence,
+			next:  'A',
+		},
+		{
+			// Row 1 of JIS X 0212 is empty
+			in:    []byte{0x8f, 0xa1, 0xa1, 'A'},
+			bytes: []byte{0x8f, 0xa1, 0xa1},
+			err:   ErrInvalidSequence,
+			next:  'A',
+		},
+		{
+			in:    []byte{0x8f, 0xb0, 'A'},
+			bytes: []byte{0x8f, 0xb0},
+			err:   ErrInvalidSequence,
+			next:  'A',
+		},
+		{
+			in:    []byte{0x8f, 0xb0},
+			bytes: []byte{0x8f, 0xb0},
+			err:   ErrTruncated,
+			next:  -1,
+		},
+		{
+			in:    []byte{0x8e},
+			bytes: []byte{0x8e},
+			err:   ErrTruncated,
+			next:  -1,
+		},
+	}
+
+	for _, c := range cases {
+		decoder := GetDecoder("EUC-JP")
+		in := bytes.NewReader(c.in)
+
+		var de *DecodeError
+		char, err := decoder.Decode(in)
+		if !errors.As(err, &de) || de.Err != c.err || !bytes.Equal(de.Bytes, c.bytes) {
+			t.Errorf("% x: got %U, %v, want %v (% x)", c.in, char, err, c.err, c.bytes)
+			continue
+		}
+
+		char, err = decoder.Decode(in)
+		if c.next < 0 {
+			if err == nil {
+				t.Errorf("% x: got %U after the error, want EOF", c.in, char)
+			}
+		} else if err != nil || char != c.next {
+			t.Errorf("% x: got %U, %v after the error, want %U", c.in, char, err, c.next)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package codec
+
+//go:generate go run gen_dbcs.go -o euckr_tables.go -overrides mappings/OVERRIDES.TXT cp949=mappings/VENDORS/MICSFT/WINDOWS/CP949.TXT
+
+// EUC-KR is the EUC form of KS X 1001: ASCII in single bytes and the KS X 1001
+// characters in two bytes from 0xA1-0xFE. It only has 2,350 of the 11,172
+// Hangul syllables.
+//
+// UHC, Microsoft's Unified Hangul Code or code page 949, adds the rest of the
+// syllables in two byte codes with a lead byte from 0x81-0xC6 and a trail
+// byte from 0x41-0xFE.
+func init() {
+	registerDBCS(&dbcs{name: "EUC-KR", table: eucTable(&ksc5601)}, []string{
+		// IANA
+		"csEUCKR",
+		// KS X 1001 is almost always used in its EUC form, so its own
+		// names refer to that too.
+		"KS_C_5601-1987", "KS_C_5601-1989", "KSC_5601", "iso-ir-149", "korean", "csKSC56011987",
+	})
+	registerDBCS(&dbcs{name: "UHC", table: &cp949}, []string{
+		// WHATWG
+		"windows-949",
+		// Java
+		"MS949", "x-windows-949",
+		// Windows code page
+		"cp949",
+	})
+}
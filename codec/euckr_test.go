@@ -0,0 +1,157 @@
+package codec
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestEUCKR(t *testing.T) {
+	cases := []struct {
+		encoding string
+		text     string
+		encoded  []byte
+	}{
+		{
+			encoding: "EUC-KR",
+			text:     "한국어 ok",
+			encoded:  []byte{0xc7, 0xd1, 0xb1, 0xb9, 0xbe, 0xee, 0x20, 0x6f, 0x6b},
+		},
+		{
+			encoding: "UHC",
+			text:     "한국어 ok",
+			encoded:  []byte{0xc7, 0xd1, 0xb1, 0xb9, 0xbe, 0xee, 0x20, 0x6f, 0x6b},
+		},
+		{
+			// Outside KS X 1001
+			encoding: "cp949",
+			text:     "갂똠",
+			encoded:  []byte{0x81, 0x41, 0x8c, 0x63},
+		},
+		{
+			// The first and last syllables
+			encoding: "UHC",
+			text:     "가힣",
+			encoded:  []byte{0xb0, 0xa1, 0xc6, 0x52},
+		},
+		{
+			// Hangul filler
+			encoding: "EUC-KR",
+			text:     "\u3164",
+			encoded:  []byte{0xa4, 0xd4},
+		},
+		{
+			encoding: "UHC",
+			text:     "\u3164",
+			encoded:  []byte{0xa4, 0xd4},
+		},
+	}
+
+	for _, c := range cases {
+		actual := &bytes.Buffer{}
+		err := Recode(bytes.NewReader(c.encoded), actual, GetDecoder(c.encoding), NewUTF8Encoder())
+		if err != nil {
+			t.Errorf("%s: decode error: %v", c.encoding, err)
+		} else if actual.String() != c.text {
+			t.Errorf("%s: got %q, want %q", c.encoding, actual.String(), c.text)
+		}
+
+		actual.Reset()
+		err = Recode(strings.NewReader(c.text), actual, NewUTF8Decoder(), GetEncoder(c.encoding))
+		if err != nil {
+			t.Errorf("%s %q: encode error: %v", c.encoding, c.text, err)
+		} else if !bytes.Equal(actual.Bytes(), c.encoded) {
+			t.Errorf("%s %q: got % x, want % x", c.encoding, c.text, actual.Bytes(), c.encoded)
+		}
+	}
+
+	_, err := GetDecoder("EUC-KR").Decode(bytes.NewReader([]byte{0x81, 0x41}))
+	if !errors.Is(err, ErrInvalidSequence) {
+		t.Errorf("EUC-KR: 81 41: got %v, want %v", err, ErrInvalidSequence)
+	}
+}
+
+// TestHangulSyllables encodes every precomposed Hangul syllable with each
+// Korean encoding and decodes it back. UHC has all of them, and the others
+// have the 2,350 in KS X 1001, with the same codes.
+func TestHangulSyllables(t *testing.T) {
+	cases := []struct {
+		encoding string
+		count    int
+		// encoded returns the expected encoding of a syllable with
+		// the EUC-KR code euc.
+		encoded func(euc []byte) []byte
+	}{
+		{
+			encoding: "UHC",
+			count:    11172,
+			encoded:  func(euc []byte) []byte { return euc },
+		},
+		{
+			encoding: "EUC-KR",
+			count:    2350,
+			encoded:  func(euc []byte) []byte { return euc },
+		},
+		{
+			encoding: "ISO-2022-KR",
+			count:    2350,
+			encoded: func(euc []byte) []byte {
+				return []byte{esc, '$', ')', 'C', so, euc[0] & 0x7f, euc[1] & 0x7f, si}
+			},
+		},
+	}
+
+	euc := map[rune][]byte{}
+	for r := rune(0xac00); r <= 0xd7a3; r++ {
+		if code, ok := ksc5601Set.lookup(r); ok {
+			euc[r] = []byte{byte(code>>8) | 0x80, byte(code) | 0x80}
+		}
+	}
+	if len(euc) != 2350 {
+		t.Fatalf("KS X 1001 has %d syllables, want 2350", len(euc))
+	}
+
+	for _, c := range cases {
+		count := 0
+		codes := map[string]rune{}
+		encoder := GetEncoder(c.encoding)
+		for r := rune(0xac00); r <= 0xd7a3; r++ {
+			encoded := &bytes.Buffer{}
+			err := encoder.Encode(encoded, r)
+			if f, ok := encoder.(Flusher); ok {
+				f.Flush(encoded)
+			}
+			if err != nil {
+				var ee *EncodeError
+				if !errors.As(err, &ee) || ee.Err != ErrOutOfRange || euc[r] != nil {
+					t.Errorf("%s: %U: %v", c.encoding, r, err)
+				}
+				continue
+			}
+			count++
+
+			if prev, ok := codes[encoded.String()]; ok {
+				t.Errorf("%s: %U and %U are both % x", c.encoding, prev, r, encoded.Bytes())
+			}
+			codes[encoded.String()] = r
+			if euc[r] != nil && !bytes.Equal(encoded.Bytes(), c.encoded(euc[r])) {
+				t.Errorf("%s: %U: got % x, want % x", c.encoding, r, encoded.Bytes(), c.encoded(euc[r]))
+			}
+
+			decoder := GetDecoder(c.encoding)
+			in := bytes.NewReader(encoded.Bytes())
+			char, err := decoder.Decode(in)
+			if err != nil || char != r {
+				t.Errorf("%s: %U: % x decodes to %U, %v", c.encoding, r, encoded.Bytes(), char, err)
+			}
+			if char, err := decoder.Decode(in); err != io.EOF {
+				t.Errorf("%s: %U: % x decodes to %U, %v after the syllable", c.encoding, r, encoded.Bytes(), char, err)
+			}
+		}
+		if count != c.count {
+			t.Errorf("%s: %d syllables, want %d", c.encoding, count, c.count)
+		}
+	}
+}
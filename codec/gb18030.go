@@ -0,0 +1,268 @@
+package codec
+
+import (
+	"io"
+	"sort"
+	"sync"
+)
+
+//go:generate go run gen_dbcs.go -o gb18030_tables.go -overrides mappings/OVERRIDES.TXT gb18030=mappings/VENDORS/MISC/GB18030.TXT
+
+// GB18030 can encode every Unicode code point. It's a superset of GBK, with
+// ASCII in single bytes and two byte codes with a lead byte from 0x81-0xFE and
+// a trail byte from 0x40-0xFE. Everything else takes four bytes: a byte from
+// 0x81-0xFE, a digit, another byte from 0x81-0xFE and another digit.
+//
+// The four byte codes are numbered in order, from 0x81308130 as 0. Numbers 0
+// to 39419 are assigned to the rest of the Basic Multilingual Plane in code
+// point order, so the mapping is a list of ranges. The supplementary planes
+// start at number 189000, 0x90308130, and are mapped linearly.
+//
+// This is GB 18030-2005, which swapped U+1E3F and U+E7C7: 0xA8BC decodes to
+// LATIN SMALL LETTER M WITH ACUTE, and 0x8135F437 to the private use area.
+func init() {
+	registerCodec(Codec{
+		Name: "GB18030",
+		Aliases: []string{
+			// IANA
+			"csGB18030",
+		},
+		NewDecoder: func(...Options) Decoder {
+			return &GB18030Decoder{}
+		},
+		NewEncoder: func(...Options) Encoder {
+			return &GB18030Encoder{}
+		},
+		Info: Info{
+			MaxRune:  0x10ffff,
+			MinBytes: 1,
+			MaxBytes: 4,
+		},
+	})
+}
+
+// gb18030Set holds the one and two byte codes.
+var gb18030Set = &dbcs{name: "GB18030", table: &gb18030}
+
+const (
+	// gb18030MaxBMP is the number of the four byte code for U+FFFF.
+	gb18030MaxBMP = 39419
+
+	// gb18030Supplementary is the number of the four byte code for
+	// U+10000.
+	gb18030Supplementary = 189000
+
+	// U+E7C7 has the four byte code that belonged to U+1E3F before
+	// GB 18030-2005.
+	gb18030Swapped = 7457
+)
+
+// gb18030Range is the start of a run of consecutive code points with
+// consecutive four byte codes.
+type gb18030Range struct {
+	index int
+	char  rune
+}
+
+var (
+	gb18030RangesOnce sync.Once
+	gb18030Ranges     []gb18030Range
+)
+
+// buildGB18030Ranges numbers the code points in the Basic Multilingual Plane
+// that don't have a two byte code.
+func buildGB18030Ranges() {
+	index := 0
+	for r := rune(0x80); r <= 0xffff; r++ {
+		if r >= 0xd800 && r <= 0xdfff {
+			continue
+		}
+
+		// The numbering follows GB 18030-2000, where U+E7C7 had
+		// 0xA8BC and U+1E3F was numbered instead.
+		if r == 0xe7c7 {
+			continue
+		}
+		if _, ok := gb18030Set.lookup(r); ok && r != 0x1e3f {
+			continue
+		}
+
+		n := len(gb18030Ranges)
+		if n == 0 || gb18030Ranges[n-1].index+int(r-gb18030Ranges[n-1].char) != index {
+			gb18030Ranges = append(gb18030Ranges, gb18030Range{index: index, char: r})
+		}
+		index++
+	}
+}
+
+// gb18030Char returns the character for the four byte code numbered index,
+// or -1 if there isn't one.
+func gb18030Char(index int) rune {
+	switch {
+	case index == gb18030Swapped:
+		return 0xe7c7
+	case index <= gb18030MaxBMP:
+		gb18030RangesOnce.Do(buildGB18030Ranges)
+		i := sort.Search(len(gb18030Ranges), func(i int) bool {
+			return gb18030Ranges[i].index > index
+		}) - 1
+		return gb18030Ranges[i].char + rune(index-gb18030Ranges[i].index)
+	case index >= gb18030Supplementary && index < gb18030Supplementary+0x100000:
+		return 0x10000 + rune(index-gb18030Supplementary)
+	default:
+		return -1
+	}
+}
+
+// gb18030Index returns the number of the four byte code for r, which must
+// not have a shorter code, or -1 if there isn't one.
+func gb18030Index(r rune) int {
+	switch {
+	case r == 0xe7c7:
+		return gb18030Swapped
+	case r < 0x80 || r > 0x10ffff || (r >= 0xd800 && r <= 0xdfff):
+		return -1
+	case r >= 0x10000:
+		return gb18030Supplementary + int(r-0x10000)
+	default:
+		gb18030RangesOnce.Do(buildGB18030Ranges)
+		i := sort.Search(len(gb18030Ranges), func(i int) bool {
+			return gb18030Ranges[i].char > r
+		}) - 1
+		return gb18030Ranges[i].index + int(r-gb18030Ranges[i].char)
+	}
+}
+
+// isGBDigit reports whether b is the second or fourth byte of a four byte
+// code.
+func isGBDigit(b byte) bool {
+	return b >= 0x30 && b <= 0x39
+}
+
+// isGBLead reports whether b is the first or third byte of a four byte code.
+func isGBLead(b byte) bool {
+	return b >= 0x81 && b <= 0xfe
+}
+
+var _ BulkDecoder = &GB18030Decoder{}
+
+// GB18030Decoder reads GB18030. Use GetDecoder to get one.
+type GB18030Decoder struct {
+	in input
+}
+
+// Decode satisfies the Decoder interface for GB18030.
+func (d *GB18030Decoder) Decode(r io.Reader) (rune, error) {
+	return d.in.decodeRune(r, "GB18030", decodeGB18030)
+}
+
+// BulkDecoder satisfies the BulkDecoder interface for GB18030.
+func (d *GB18030Decoder) BulkDecoder() BulkDecoder {
+	return d
+}
+
+// DecodeBytes satisfies the BulkDecoder interface for GB18030.
+func (d *GB18030Decoder) DecodeBytes(dst []rune, src []byte, atEOF bool) (nDst, nSrc int, err error) {
+	return decodeBytes(dst, src, atEOF, "GB18030", decodeGB18030)
+}
+
+// Buffered returns the number of bytes that have been read but not decoded.
+func (d *GB18030Decoder) Buffered() int {
+	return d.in.Buffered()
+}
+
+// decodeGB18030 is the decodeFunc for GB18030. When a four byte code is cut
+// short by an invalid byte only the first byte is skipped, since the second
+// is an ASCII digit.
+func decodeGB18030(src []byte, atEOF bool) (rune, int, error) {
+	b := src[0]
+	switch {
+	case b < 0x80:
+		return rune(b), 1, nil
+	case !isGBLead(b):
+		return 0, 1, ErrInvalidSequence
+	case len(src) < 2:
+		return shortEUC(src, atEOF)
+	case !isGBDigit(src[1]):
+		return gb18030Set.decode(src, atEOF)
+	}
+
+	if len(src) > 2 && !isGBLead(src[2]) {
+		return 0, 1, ErrInvalidSequence
+	}
+	if len(src) < 4 {
+		return shortEUC(src, atEOF)
+	}
+	if !isGBDigit(src[3]) {
+		return 0, 1, ErrInvalidSequence
+	}
+
+	index := ((int(b-0x81)*10+int(src[1]-0x30))*126+int(src[2]-0x81))*10 + int(src[3]-0x30)
+	if r := gb18030Char(index); r >= 0 {
+		return r, 4, nil
+	}
+	return 0, 4, ErrInvalidSequence
+}
+
+var _ BulkEncoder = &GB18030Encoder{}
+
+// GB18030Encoder writes GB18030. Use GetEncoder to get one.
+type GB18030Encoder struct{}
+
+// Encode satisfies the Encoder interface for GB18030.
+func (e *GB18030Encoder) Encode(w io.Writer, r rune) error {
+	buf := make([]byte, 4)
+	n := encodeGB18030(buf, r)
+	if n == 0 {
+		return &EncodeError{Encoding: "GB18030", Rune: r, Err: ErrOutOfRange}
+	}
+
+	_, err := w.Write(buf[:n])
+	return err
+}
+
+// BulkEncoder satisfies the BulkEncoder interface for GB18030.
+func (e *GB18030Encoder) BulkEncoder() BulkEncoder {
+	return e
+}
+
+// EncodeRunes satisfies the BulkEncoder interface for GB18030.
+func (e *GB18030Encoder) EncodeRunes(dst []byte, src []rune) (nDst, nSrc int, err error) {
+	for ; nSrc < len(src); nSrc++ {
+		if len(dst)-nDst < 4 {
+			return nDst, nSrc, ErrShortDst
+		}
+		n := encodeGB18030(dst[nDst:], src[nSrc])
+		if n == 0 {
+			return nDst, nSrc, &EncodeError{Encoding: "GB18030", Rune: src[nSrc], Err: ErrOutOfRange}
+		}
+		nDst += n
+	}
+	return nDst, nSrc, nil
+}
+
+// encodeGB18030 writes r to buf, which must have room for 4 bytes, and returns
+// the number of bytes written, or 0 if r can't be encoded.
+func encodeGB18030(buf []byte, r rune) int {
+	if r >= 0 && r < 0x80 {
+		buf[0] = byte(r)
+		return 1
+	}
+	if code, ok := gb18030Set.lookup(r); ok {
+		buf[0] = byte(code >> 8)
+		buf[1] = byte(code)
+		return 2
+	}
+
+	index := gb18030Index(r)
+	if index < 0 {
+		return 0
+	}
+	buf[3] = byte(index%10) + 0x30
+	index /= 10
+	buf[2] = byte(index%126) + 0x81
+	index /= 126
+	buf[1] = byte(index%10) + 0x30
+	buf[0] = byte(index/10) + 0x81
+	return 4
+}
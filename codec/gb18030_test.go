@@ -0,0 +1,219 @@
+package codec
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestGB18030(t *testing.T) {
+	cases := []struct {
+		text    string
+		encoded []byte
+	}{
+		{
+			text:    "中文 ok",
+			encoded: []byte{0xd6, 0xd0, 0xce, 0xc4, 0x20, 0x6f, 0x6b},
+		},
+		{
+			// Unlike GBK, the euro sign only has a two byte code
+			text:    "€",
+			encoded: []byte{0xa2, 0xe3},
+		},
+		{
+			// The first four byte code, and the start of the next range
+			text:    "\u0080¥",
+			encoded: []byte{0x81, 0x30, 0x81, 0x30, 0x81, 0x30, 0x84, 0x36},
+		},
+		{
+			text:    "ђ",
+			encoded: []byte{0x81, 0x30, 0xd3, 0x30},
+		},
+		{
+			// The last code in the Basic Multilingual Plane
+			text:    "￿",
+			encoded: []byte{0x84, 0x31, 0xa4, 0x39},
+		},
+		{
+			// Swapped in GB 18030-2005
+			text:    "ḿ",
+			encoded: []byte{0xa8, 0xbc, 0x81, 0x35, 0xf4, 0x37},
+		},
+		{
+			// Private use characters in the two byte user defined area
+			text:    "",
+			encoded: []byte{0xaa, 0xa1, 0xa3, 0xa0},
+		},
+		{
+			// Supplementary planes
+			text:    "\U00010000𠀀\U0010ffff",
+			encoded: []byte{0x90, 0x30, 0x81, 0x30, 0x95, 0x32, 0x82, 0x36, 0xe3, 0x32, 0x9a, 0x35},
+		},
+	}
+
+	for _, c := range cases {
+		actual := &bytes.Buffer{}
+		err := Recode(bytes.NewReader(c.encoded), actual, GetDecoder("GB18030"), NewUTF8Encoder())
+		if err != nil {
+			t.Errorf("% x: decode error: %v", c.encoded, err)
+		} else if actual.String() != c.text {
+			t.Errorf("% x: got %q, want %q", c.encoded, actual.String(), c.text)
+		}
+
+		actual.Reset()
+		err = Recode(strings.NewReader(c.text), actual, NewUTF8Decoder(), GetEncoder("GB18030"))
+		if err != nil {
+			t.Errorf("%q: encode error: %v", c.text, err)
+		} else if !bytes.Equal(actual.Bytes(), c.encoded) {
+			t.Errorf("%q: got % x, want % x", c.text, actual.Bytes(), c.encoded)
+		}
+	}
+}
+
+// TestGB18030FourByte checks that every code point can be encoded and decoded
+// back, and that the four byte codes in the Basic Multilingual Plane are
+// numbered in code point order with no gaps.
+func TestGB18030FourByte(t *testing.T) {
+	buf := make([]byte, 4)
+	next := 0
+	for r := rune(0); r <= 0x10ffff; r++ {
+		n := encodeGB18030(buf, r)
+		if r >= 0xd800 && r <= 0xdfff {
+			if n != 0 {
+				t.Errorf("%U: got % x, want nothing", r, buf[:n])
+			}
+			continue
+		}
+		if n == 0 {
+			t.Errorf("%U: can't be encoded", r)
+			continue
+		}
+
+		char, size, err := decodeGB18030(buf[:n], true)
+		if err != nil || char != r || size != n {
+			t.Errorf("%U: % x decodes to %U, %d, %v", r, buf[:n], char, size, err)
+		}
+
+		// U+1E3F has a two byte code, and its old number went
+		// to U+E7C7.
+		if r == 0x1e3f {
+			next++
+		}
+		if n != 4 || r == 0xe7c7 {
+			continue
+		}
+		index := gb18030Index(r)
+		switch {
+		case r < 0x10000 && index != next:
+			t.Errorf("%U: got number %d, want %d", r, index, next)
+		case r >= 0x10000 && index != gb18030Supplementary+int(r-0x10000):
+			t.Errorf("%U: got number %d", r, index)
+		}
+		next = index + 1
+	}
+	if next != gb18030Supplementary+0x100000 {
+		t.Errorf("got %d four byte codes", next)
+	}
+}
+
+func TestGB18030Errors(t *testing.T) {
+	cases := []struct {
+		in    []byte
+		bytes []byte
+		err   error
+		next  rune
+	}{
+		{
+			in:    []byte{0x80, 'A'},
+			bytes: []byte{0x80},
+			err:   ErrInvalidSequence,
+			next:  'A',
+		},
+		{
+			in:    []byte{0xff, 'A'},
+			bytes: []byte{0xff},
+			err:   ErrInvalidSequence,
+			next:  'A',
+		},
+		{
+			in:    []byte{0x81, '\n'},
+			bytes: []byte{0x81},
+			err:   ErrInvalidSequence,
+			next:  '\n',
+		},
+		{
+			in:    []byte{0x81, 0xff, 'A'},
+			bytes: []byte{0x81, 0xff},
+			err:   ErrInvalidSequence,
+			next:  'A',
+		},
+		{
+			// The digit isn't skipped
+			in:    []byte{0x81, 0x30, 'A'},
+			bytes: []byte{0x81},
+			err:   ErrInvalidSequence,
+			next:  '0',
+		},
+		{
+			in:    []byte{0x81, 0x30, 0x81, 'A'},
+			bytes: []byte{0x81},
+			err:   ErrInvalidSequence,
+			next:  '0',
+		},
+		{
+			// Between the Basic Multilingual Plane and the
+			// supplementary planes
+			in:    []byte{0x84, 0x31, 0xa5, 0x30, 'A'},
+			bytes: []byte{0x84, 0x31, 0xa5, 0x30},
+			err:   ErrInvalidSequence,
+			next:  'A',
+		},
+		{
+			in:    []byte{0x8f, 0x39, 0xfe, 0x39, 'A'},
+			bytes: []byte{0x8f, 0x39, 0xfe, 0x39},
+			err:   ErrInvalidSequence,
+			next:  'A',
+		},
+		{
+			// Past U+10FFFF
+			in:    []byte{0xe3, 0x32, 0x9a, 0x36, 'A'},
+			bytes: []byte{0xe3, 0x32, 0x9a, 0x36},
+			err:   ErrInvalidSequence,
+			next:  'A',
+		},
+		{
+			in:    []byte{0x81, 0x30, 0x81},
+			bytes: []byte{0x81, 0x30, 0x81},
+			err:   ErrTruncated,
+			next:  -1,
+		},
+	}
+
+	for _, c := range cases {
+		decoder := GetDecoder("GB18030")
+		in := bytes.NewReader(c.in)
+
+		var de *DecodeError
+		char, err := decoder.Decode(in)
+		if !errors.As(err, &de) || de.Err != c.err || !bytes.Equal(de.Bytes, c.bytes) {
+			t.Errorf("% x: got %U, %v, want %v (% x)", c.in, char, err, c.err, c.bytes)
+			continue
+		}
+
+		char, err = decoder.Decode(in)
+		if c.next < 0 {
+			if err == nil {
+				t.Errorf("% x: got %U after the error, want EOF", c.in, char)
+			}
+		} else if err != nil || char != c.next {
+			t.Errorf("% x: got %U, %v after the error, want %U", c.in, char, err, c.next)
+		}
+	}
+
+	var ee *EncodeError
+	err := GetEncoder("GB18030").Encode(&bytes.Buffer{}, 0xd800)
+	if !errors.As(err, &ee) || ee.Err != ErrOutOfRange {
+		t.Errorf("got %v, want %v", err, ErrOutOfRange)
+	}
+}
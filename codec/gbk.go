@@ -0,0 +1,29 @@
+package codec
+
+//go:generate go run gen_dbcs.go -o gbk_tables.go -overrides mappings/OVERRIDES.TXT cp936=mappings/VENDORS/MICSFT/WINDOWS/CP936.TXT
+
+// GB2312, also known as EUC-CN, is the EUC form of GB 2312: ASCII in single
+// bytes and the GB 2312 characters in two bytes from 0xA1-0xFE.
+//
+// GBK extends it with two byte codes that have a lead byte from 0x81-0xFE and
+// a trail byte from 0x40-0xFE, so it covers all the CJK Unified Ideographs in
+// Unicode 1.1. This is Microsoft's version, code
+// page 936, which also has the euro sign at 0x80.
+//
+// GB18030 is a superset of both.
+func init() {
+	registerDBCS(&dbcs{name: "GB2312", table: eucTable(&gb2312)}, []string{
+		// IANA
+		"csGB2312",
+		// GB 2312 is almost always used in its EUC form, so its own
+		// names refer to that too.
+		"GB_2312-80", "iso-ir-58", "chinese", "csISO58GB231280",
+		"EUC-CN", "x-euc-cn",
+	})
+	registerDBCS(&dbcs{name: "GBK", table: &cp936}, []string{
+		// IANA
+		"CP936", "MS936", "windows-936", "csGBK",
+		// WHATWG
+		"x-gbk",
+	})
+}
@@ -0,0 +1,69 @@
+package codec
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestGBK(t *testing.T) {
+	cases := []struct {
+		encoding string
+		text     string
+		encoded  []byte
+	}{
+		{
+			encoding: "GB2312",
+			text:     "中文 ok",
+			encoded:  []byte{0xd6, 0xd0, 0xce, 0xc4, 0x20, 0x6f, 0x6b},
+		},
+		{
+			encoding: "EUC-CN",
+			text:     "　",
+			encoded:  []byte{0xa1, 0xa1},
+		},
+		{
+			encoding: "GBK",
+			text:     "中文 ok",
+			encoded:  []byte{0xd6, 0xd0, 0xce, 0xc4, 0x20, 0x6f, 0x6b},
+		},
+		{
+			// Outside GB 2312
+			encoding: "GBK",
+			text:     "丂€",
+			encoded:  []byte{0x81, 0x40, 0x80},
+		},
+	}
+
+	for _, c := range cases {
+		actual := &bytes.Buffer{}
+		err := Recode(bytes.NewReader(c.encoded), actual, GetDecoder(c.encoding), NewUTF8Encoder())
+		if err != nil {
+			t.Errorf("%s: decode error: %v", c.encoding, err)
+		} else if actual.String() != c.text {
+			t.Errorf("%s: got %q, want %q", c.encoding, actual.String(), c.text)
+		}
+
+		actual.Reset()
+		err = Recode(strings.NewReader(c.text), actual, NewUTF8Decoder(), GetEncoder(c.encoding))
+		if err != nil {
+			t.Errorf("%s %q: encode error: %v", c.encoding, c.text, err)
+		} else if !bytes.Equal(actual.Bytes(), c.encoded) {
+			t.Errorf("%s %q: got % x, want % x", c.encoding, c.text, actual.Bytes(), c.encoded)
+		}
+	}
+
+	// GB2312 is a subset of GBK.
+	for _, r := range []rune{'丂', '€'} {
+		var ee *EncodeError
+		err := GetEncoder("GB2312").Encode(&bytes.Buffer{}, r)
+		if !errors.As(err, &ee) || ee.Err != ErrOutOfRange {
+			t.Errorf("GB2312: %U: got %v, want %v", r, err, ErrOutOfRange)
+		}
+	}
+	_, err := GetDecoder("GB2312").Decode(bytes.NewReader([]byte{0x81, 0x40}))
+	if !errors.Is(err, ErrInvalidSequence) {
+		t.Errorf("GB2312: 81 40: got %v, want %v", err, ErrInvalidSequence)
+	}
+}
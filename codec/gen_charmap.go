@@ -0,0 +1,209 @@
+//go:build ignore
+// +build ignore
+
+// gen_charmap generates the decoding tables for single-byte encodings from
+// mapping files in the format of the Unicode Consortium's MAPPINGS tables: one
+// byte per line, as two hex numbers for the byte and the code point, followed
+// by a comment. Bytes that aren't listed, or are listed without a code point,
+// are undefined.
+//
+// Usage:
+//
+//	go run gen_charmap.go -o output.go [-overrides overrides.txt] name=mapping.txt...
+//
+// The overrides file changes individual bytes in the named tables, so the
+// mapping files can be kept as they were published. Each line has the name of
+// a table, the byte and the code point, or no code point if the byte is
+// undefined.
+//
+// Each table is written as a [256]rune variable called name, with -1 for
+// undefined bytes.
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+)
+
+func main() {
+	output := flag.String("o", "", "output `file`")
+	overridesFile := flag.String("overrides", "", "overrides `file`")
+	flag.Parse()
+
+	if *output == "" || flag.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "usage: go run gen_charmap.go -o output.go [-overrides overrides.txt] name=mapping.txt...")
+		os.Exit(2)
+	}
+
+	var overrides map[string][]override
+	if *overridesFile != "" {
+		var err error
+		overrides, err = readOverrides(*overridesFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "// Code generated by gen_charmap.go. DO NOT EDIT.\n\n")
+	fmt.Fprintf(buf, "package codec\n")
+
+	for _, arg := range flag.Args() {
+		parts := strings.SplitN(arg, "=", 2)
+		if len(parts) != 2 {
+			log.Fatalf("%s: want name=file", arg)
+		}
+
+		table, err := readMapping(parts[1])
+		if err != nil {
+			log.Fatal(err)
+		}
+		source := parts[1]
+		for _, o := range overrides[parts[0]] {
+			if o.code > 0xff {
+				log.Fatalf("%s: %s: 0x%X isn't a single byte", o.pos, parts[0], o.code)
+			}
+			table[o.code] = o.r
+			source = parts[1] + " and " + *overridesFile
+		}
+		writeTable(buf, parts[0], source, table)
+	}
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = ioutil.WriteFile(*output, src, 0644)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// readMapping reads a mapping file into a table.
+func readMapping(path string) ([256]rune, error) {
+	var table [256]rune
+	for i := range table {
+		table[i] = -1
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return table, err
+	}
+	defer f.Close()
+
+	s := bufio.NewScanner(f)
+	line := 0
+	for s.Scan() {
+		line++
+
+		text := s.Text()
+		if i := strings.IndexByte(text, '#'); i >= 0 {
+			text = text[:i]
+		}
+		fields := strings.Fields(text)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) == 1 {
+			// Microsoft's tables list undefined bytes without a
+			// code point.
+			continue
+		}
+		if len(fields) != 2 {
+			return table, fmt.Errorf("%s:%d: want two columns", path, line)
+		}
+
+		b, err := strconv.ParseUint(fields[0], 0, 8)
+		if err != nil {
+			return table, fmt.Errorf("%s:%d: %v", path, line, err)
+		}
+		r, err := strconv.ParseUint(fields[1], 0, 32)
+		if err != nil {
+			return table, fmt.Errorf("%s:%d: %v", path, line, err)
+		}
+		if table[b] != -1 {
+			return table, fmt.Errorf("%s:%d: 0x%02X is listed twice", path, line, b)
+		}
+		table[b] = rune(r)
+	}
+	return table, s.Err()
+}
+
+// override changes the character for one code in a table.
+type override struct {
+	pos  string
+	code uint64
+	r    rune // -1 if the code is undefined
+}
+
+// readOverrides reads an overrides file into lists of overrides by table name.
+func readOverrides(path string) (map[string][]override, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	overrides := map[string][]override{}
+	s := bufio.NewScanner(f)
+	line := 0
+	for s.Scan() {
+		line++
+
+		text := s.Text()
+		if i := strings.IndexByte(text, '#'); i >= 0 {
+			text = text[:i]
+		}
+		fields := strings.Fields(text)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 2 && len(fields) != 3 {
+			return nil, fmt.Errorf("%s:%d: want two or three columns", path, line)
+		}
+
+		o := override{pos: fmt.Sprintf("%s:%d", path, line), r: -1}
+		o.code, err = strconv.ParseUint(fields[1], 0, 16)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", o.pos, err)
+		}
+		if len(fields) == 3 {
+			r, err := strconv.ParseUint(fields[2], 0, 32)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", o.pos, err)
+			}
+			o.r = rune(r)
+		}
+		overrides[fields[0]] = append(overrides[fields[0]], o)
+	}
+	return overrides, s.Err()
+}
+
+func writeTable(buf *bytes.Buffer, name, path string, table [256]rune) {
+	fmt.Fprintf(buf, "\n// %s is generated from %s.\n", name, path)
+	fmt.Fprintf(buf, "var %s = [256]rune{\n", name)
+	for i := 0; i < 256; i += 8 {
+		for j, r := range table[i : i+8] {
+			if j > 0 {
+				buf.WriteByte(' ')
+			}
+			if r == -1 {
+				fmt.Fprintf(buf, "-1,")
+			} else {
+				fmt.Fprintf(buf, "0x%04x,", r)
+			}
+		}
+		fmt.Fprintf(buf, " // 0x%02x\n", i)
+	}
+	fmt.Fprintf(buf, "}\n")
+}
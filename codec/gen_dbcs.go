@@ -0,0 +1,312 @@
+//go:build ignore
+// +build ignore
+
+// gen_dbcs generates the decoding tables for double-byte encodings, such as
+// Shift_JIS, from mapping files in the format of the Unicode Consortium's
+// MAPPINGS tables. Each line has a one byte code (0xXX) or a two byte code
+// (0xXXXX), the code point, and a comment. Codes that aren't listed, or are
+// listed without a code point, are undefined.
+//
+// Usage:
+//
+//	go run gen_dbcs.go -o output.go [-overrides overrides.txt] name=mapping.txt...
+//
+// The overrides file is the same as for gen_charmap.go. Each line has the name
+// of a table, the code and the code point, or no code point if the code is
+// undefined.
+//
+// Each table is written as a dbcsTable variable called name.
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+)
+
+func main() {
+	output := flag.String("o", "", "output `file`")
+	overridesFile := flag.String("overrides", "", "overrides `file`")
+	flag.Parse()
+
+	if *output == "" || flag.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "usage: go run gen_dbcs.go -o output.go [-overrides overrides.txt] name=mapping.txt...")
+		os.Exit(2)
+	}
+
+	var overrides map[string][]override
+	if *overridesFile != "" {
+		var err error
+		overrides, err = readOverrides(*overridesFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "// Code generated by gen_dbcs.go. DO NOT EDIT.\n\n")
+	fmt.Fprintf(buf, "package codec\n")
+
+	for _, arg := range flag.Args() {
+		parts := strings.SplitN(arg, "=", 2)
+		if len(parts) != 2 {
+			log.Fatalf("%s: want name=file", arg)
+		}
+
+		t, err := readMapping(parts[1])
+		if err != nil {
+			log.Fatal(err)
+		}
+		source := parts[1]
+		for _, o := range overrides[parts[0]] {
+			err := t.set(o.code, o.r)
+			if err != nil {
+				log.Fatalf("%s: %v", o.pos, err)
+			}
+			source = parts[1] + " and " + *overridesFile
+		}
+		if err := t.finish(); err != nil {
+			log.Fatalf("%s: %v", source, err)
+		}
+		writeTable(buf, parts[0], source, t)
+	}
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = ioutil.WriteFile(*output, src, 0644)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+type table struct {
+	single [256]rune
+	double map[uint16]rune
+
+	leadMin, leadMax   byte
+	trailMin, trailMax byte
+}
+
+// readMapping reads a mapping file into a table.
+func readMapping(path string) (*table, error) {
+	t := &table{
+		double: map[uint16]rune{},
+	}
+	for i := range t.single {
+		t.single[i] = -1
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	s := bufio.NewScanner(f)
+	line := 0
+	for s.Scan() {
+		line++
+
+		text := s.Text()
+		if i := strings.IndexByte(text, '#'); i >= 0 {
+			text = text[:i]
+		}
+		fields := strings.Fields(text)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) == 1 {
+			// Microsoft's tables list undefined codes without a
+			// code point.
+			continue
+		}
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("%s:%d: want two columns", path, line)
+		}
+
+		r, err := strconv.ParseUint(fields[1], 0, 32)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %v", path, line, err)
+		}
+		if t.defined(fields[0]) {
+			return nil, fmt.Errorf("%s:%d: %s is listed twice", path, line, fields[0])
+		}
+		if err := t.set(fields[0], rune(r)); err != nil {
+			return nil, fmt.Errorf("%s:%d: %v", path, line, err)
+		}
+	}
+	return t, s.Err()
+}
+
+// parseCode parses a one or two byte code. The number of digits gives the
+// length of the code.
+func parseCode(code string) (uint16, bool, error) {
+	n, err := strconv.ParseUint(code, 0, 16)
+	return uint16(n), len(code) > 4, err
+}
+
+// defined returns true if code has a character.
+func (t *table) defined(code string) bool {
+	n, double, err := parseCode(code)
+	if err != nil {
+		return false
+	}
+	if double {
+		_, ok := t.double[n]
+		return ok
+	}
+	return n <= 0xff && t.single[n] != -1
+}
+
+// set sets the character for code, or makes it undefined if r is -1.
+func (t *table) set(code string, r rune) error {
+	n, double, err := parseCode(code)
+	if err != nil {
+		return err
+	}
+
+	if !double {
+		if n > 0xff {
+			return fmt.Errorf("%s isn't a one or two byte code", code)
+		}
+		t.single[n] = r
+		return nil
+	}
+
+	switch r {
+	case -1:
+		delete(t.double, n)
+	case 0:
+		return fmt.Errorf("%s maps to U+0000", code)
+	default:
+		t.double[n] = r
+	}
+	return nil
+}
+
+// finish works out the range of lead and trail bytes once all the codes are
+// set.
+func (t *table) finish() error {
+	t.leadMin, t.trailMin = 0xff, 0xff
+	for code := range t.double {
+		lead, trail := byte(code>>8), byte(code)
+		if t.single[lead] != -1 {
+			return fmt.Errorf("0x%02X is a character and a lead byte", lead)
+		}
+		if lead < t.leadMin {
+			t.leadMin = lead
+		}
+		if lead > t.leadMax {
+			t.leadMax = lead
+		}
+		if trail < t.trailMin {
+			t.trailMin = trail
+		}
+		if trail > t.trailMax {
+			t.trailMax = trail
+		}
+	}
+	return nil
+}
+
+// override changes the character for one code in a table.
+type override struct {
+	pos  string
+	code string
+	r    rune // -1 if the code is undefined
+}
+
+// readOverrides reads an overrides file into lists of overrides by table name.
+func readOverrides(path string) (map[string][]override, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	overrides := map[string][]override{}
+	s := bufio.NewScanner(f)
+	line := 0
+	for s.Scan() {
+		line++
+
+		text := s.Text()
+		if i := strings.IndexByte(text, '#'); i >= 0 {
+			text = text[:i]
+		}
+		fields := strings.Fields(text)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 2 && len(fields) != 3 {
+			return nil, fmt.Errorf("%s:%d: want two or three columns", path, line)
+		}
+
+		o := override{pos: fmt.Sprintf("%s:%d", path, line), code: fields[1], r: -1}
+		if _, _, err := parseCode(o.code); err != nil {
+			return nil, fmt.Errorf("%s: %v", o.pos, err)
+		}
+		if len(fields) == 3 {
+			r, err := strconv.ParseUint(fields[2], 0, 32)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", o.pos, err)
+			}
+			o.r = rune(r)
+		}
+		overrides[fields[0]] = append(overrides[fields[0]], o)
+	}
+	return overrides, s.Err()
+}
+
+func writeTable(buf *bytes.Buffer, name, path string, t *table) {
+	fmt.Fprintf(buf, "\n// %s is generated from %s.\n", name, path)
+	fmt.Fprintf(buf, "var %s = dbcsTable{\n", name)
+
+	fmt.Fprintf(buf, "single: [256]rune{\n")
+	for i := 0; i < 256; i += 8 {
+		for j, r := range t.single[i : i+8] {
+			if j > 0 {
+				buf.WriteByte(' ')
+			}
+			if r == -1 {
+				fmt.Fprintf(buf, "-1,")
+			} else {
+				fmt.Fprintf(buf, "0x%04x,", r)
+			}
+		}
+		fmt.Fprintf(buf, " // 0x%02x\n", i)
+	}
+	fmt.Fprintf(buf, "},\n")
+
+	fmt.Fprintf(buf, "leadMin: 0x%02x, leadMax: 0x%02x,\n", t.leadMin, t.leadMax)
+	fmt.Fprintf(buf, "trailMin: 0x%02x, trailMax: 0x%02x,\n", t.trailMin, t.trailMax)
+
+	fmt.Fprintf(buf, "double: []rune{\n")
+	for lead := int(t.leadMin); lead <= int(t.leadMax); lead++ {
+		fmt.Fprintf(buf, "// 0x%02x\n", lead)
+		n := 0
+		for trail := int(t.trailMin); trail <= int(t.trailMax); trail++ {
+			if n > 0 {
+				buf.WriteByte(' ')
+			}
+			fmt.Fprintf(buf, "0x%04x,", t.double[uint16(lead<<8|trail)])
+			n++
+			if n == 8 || trail == int(t.trailMax) {
+				buf.WriteByte('\n')
+				n = 0
+			}
+		}
+	}
+	fmt.Fprintf(buf, "},\n")
+	fmt.Fprintf(buf, "}\n")
+}
@@ -0,0 +1,401 @@
+package codec
+
+import (
+	"io"
+	"strings"
+)
+
+// ISO-2022-JP is a 7-bit encoding used in Japanese email. It starts in ASCII,
+// and escape sequences switch between ASCII, JIS X 0201 Roman and JIS X 0208.
+// The text has to end in ASCII, so the encoder needs to be flushed.
+//
+// ISO-2022-JP-2 adds JIS X 0212, GB 2312 and KS X 1001, and the upper halves
+// of ISO-8859-1 and ISO-8859-7, which are designated to G2 and used one
+// character at a time with the single shift ESC N.
+//
+// Both decoders also accept ESC ( I for JIS X 0201 katakana, which is common
+// though not part of either standard. The encoders don't use it.
+func init() {
+	registerCodec(Codec{
+		Name: "ISO-2022-JP",
+		Aliases: []string{
+			// IANA
+			"csISO2022JP",
+		},
+		NewDecoder: func(...Options) Decoder {
+			return &ISO2022JPDecoder{name: "ISO-2022-JP", escapes: iso2022JPEscapes}
+		},
+		NewEncoder: func(...Options) Encoder {
+			return &ISO2022JPEncoder{name: "ISO-2022-JP", sets: iso2022JPSets}
+		},
+		Info: charsetInfo(5, []rune{0xa5, 0x203e}, jis0208Set),
+	})
+	registerCodec(Codec{
+		Name: "ISO-2022-JP-2",
+		Aliases: []string{
+			// IANA
+			"csISO2022JP2",
+		},
+		NewDecoder: func(...Options) Decoder {
+			return &ISO2022JPDecoder{name: "ISO-2022-JP-2", escapes: iso2022JP2Escapes}
+		},
+		NewEncoder: func(...Options) Encoder {
+			return &ISO2022JPEncoder{name: "ISO-2022-JP-2", sets: iso2022JP2Sets, g2Sets: iso2022G2Sets}
+		},
+		Info: charsetInfo(6, iso2022JP2Extra(), jis0208Set, jis0212Set, ksc5601Set, gb2312Set),
+	})
+}
+
+const (
+	esc = 0x1b
+	so  = 0x0e
+	si  = 0x0f
+)
+
+// iso2022Set is a character set that can be designated to G0.
+type iso2022Set int
+
+const (
+	iso2022ASCII iso2022Set = iota
+	iso2022Roman
+	iso2022Katakana
+	iso2022JIS0208
+	iso2022JIS0212
+	iso2022GB2312
+	iso2022KSC5601
+)
+
+// iso2022Designations holds the escape sequence that designates each set to
+// G0, and the table for the two byte sets.
+var iso2022Designations = [...]struct {
+	escape  string
+	charset *dbcs
+}{
+	iso2022ASCII:    {"\x1b(B", nil},
+	iso2022Roman:    {"\x1b(J", nil},
+	iso2022Katakana: {"\x1b(I", nil},
+	iso2022JIS0208:  {"\x1b$B", jis0208Set},
+	iso2022JIS0212:  {"\x1b$(D", jis0212Set},
+	iso2022GB2312:   {"\x1b$A", gb2312Set},
+	iso2022KSC5601:  {"\x1b$(C", ksc5601Set},
+}
+
+// The G2 sets of ISO-2022-JP-2 are the upper halves of ISO-8859-1 and
+// ISO-8859-7.
+var (
+	iso2022Latin1 = &charmap{name: "ISO-8859-1", decode: &iso8859_1}
+	iso2022Greek  = &charmap{name: "ISO-8859-7", decode: &iso8859_7}
+
+	iso2022G2Designations = map[*charmap]string{
+		iso2022Latin1: "\x1b.A",
+		iso2022Greek:  "\x1b.F",
+	}
+)
+
+// iso2022SS2 is the single shift that takes the next character from G2.
+const iso2022SS2 = "\x1bN"
+
+// iso2022Escape is an escape sequence a decoder recognizes. It designates
+// either g0 or, if g2 isn't nil, g2.
+type iso2022Escape struct {
+	seq string
+	g0  iso2022Set
+	g2  *charmap
+}
+
+var iso2022JPEscapes = []iso2022Escape{
+	{seq: "\x1b(B", g0: iso2022ASCII},
+	{seq: "\x1b(J", g0: iso2022Roman},
+	{seq: "\x1b(I", g0: iso2022Katakana},
+	// JIS C 6226-1978, the first version of JIS X 0208
+	{seq: "\x1b$@", g0: iso2022JIS0208},
+	{seq: "\x1b$B", g0: iso2022JIS0208},
+}
+
+var iso2022JP2Escapes = append(iso2022JPEscapes[:len(iso2022JPEscapes):len(iso2022JPEscapes)],
+	iso2022Escape{seq: "\x1b$A", g0: iso2022GB2312},
+	// The long form of ESC $ A, which some encoders write
+	iso2022Escape{seq: "\x1b$(A", g0: iso2022GB2312},
+	iso2022Escape{seq: "\x1b$(C", g0: iso2022KSC5601},
+	iso2022Escape{seq: "\x1b$(D", g0: iso2022JIS0212},
+	iso2022Escape{seq: "\x1b.A", g2: iso2022Latin1},
+	iso2022Escape{seq: "\x1b.F", g2: iso2022Greek},
+)
+
+// The encoders try the two byte sets in this order.
+var (
+	iso2022JPSets  = []iso2022Set{iso2022JIS0208}
+	iso2022JP2Sets = []iso2022Set{iso2022JIS0208, iso2022JIS0212, iso2022KSC5601, iso2022GB2312}
+	iso2022G2Sets  = []*charmap{iso2022Latin1, iso2022Greek}
+)
+
+// iso2022JP2Extra returns the characters ISO-2022-JP-2 can encode that aren't
+// ASCII or in a 94x94 set.
+func iso2022JP2Extra() []rune {
+	extra := []rune{0xa5, 0x203e}
+	for _, g2 := range iso2022G2Sets {
+		for _, r := range g2.decode[0xa0:] {
+			if r >= 0 {
+				extra = append(extra, r)
+			}
+		}
+	}
+	return extra
+}
+
+var _ BulkDecoder = &ISO2022JPDecoder{}
+
+// ISO2022JPDecoder reads ISO-2022-JP and ISO-2022-JP-2. Use GetDecoder to get
+// one.
+type ISO2022JPDecoder struct {
+	name    string
+	in      input
+	escapes []iso2022Escape
+	g0      iso2022Set
+	g2      *charmap
+}
+
+// Decode satisfies the Decoder interface for ISO-2022-JP.
+func (d *ISO2022JPDecoder) Decode(r io.Reader) (rune, error) {
+	return d.in.decodeRune(r, d.name, d.decode)
+}
+
+// BulkDecoder satisfies the BulkDecoder interface for ISO-2022-JP.
+func (d *ISO2022JPDecoder) BulkDecoder() BulkDecoder {
+	return d
+}
+
+// DecodeBytes satisfies the BulkDecoder interface for ISO-2022-JP.
+func (d *ISO2022JPDecoder) DecodeBytes(dst []rune, src []byte, atEOF bool) (nDst, nSrc int, err error) {
+	return decodeBytes(dst, src, atEOF, d.name, d.decode)
+}
+
+// Buffered returns the number of bytes that have been read but not decoded.
+func (d *ISO2022JPDecoder) Buffered() int {
+	return d.in.Buffered()
+}
+
+// decode is the decodeFunc for ISO-2022-JP.
+func (d *ISO2022JPDecoder) decode(src []byte, atEOF bool) (rune, int, error) {
+	b := src[0]
+	switch {
+	case b == esc:
+		return d.escape(src, atEOF)
+	case b >= 0x80, b == so, b == si:
+		return 0, 1, ErrInvalidSequence
+	case b <= 0x20 || b == 0x7f:
+		// Control codes and space are the same in every set.
+		return rune(b), 1, nil
+	}
+
+	switch d.g0 {
+	case iso2022ASCII:
+		return rune(b), 1, nil
+	case iso2022Roman:
+		switch b {
+		case 0x5c:
+			return 0xa5, 1, nil
+		case 0x7e:
+			return 0x203e, 1, nil
+		}
+		return rune(b), 1, nil
+	case iso2022Katakana:
+		if b <= 0x5f {
+			return 0xff61 + rune(b-0x21), 1, nil
+		}
+		return 0, 1, ErrInvalidSequence
+	}
+
+	if len(src) < 2 {
+		if !atEOF {
+			return 0, 0, ErrShortSrc
+		}
+		return 0, 1, ErrTruncated
+	}
+	trail := src[1]
+	if trail <= 0x20 || trail >= 0x7f {
+		return 0, 1, ErrInvalidSequence
+	}
+	if r := iso2022Designations[d.g0].charset.table.char(b, trail); r != 0 {
+		return r, 2, nil
+	}
+	return 0, 2, ErrInvalidSequence
+}
+
+// escape decodes the escape sequence at the start of src.
+func (d *ISO2022JPDecoder) escape(src []byte, atEOF bool) (rune, int, error) {
+	// No escape sequence is longer than four bytes.
+	if len(src) > 4 {
+		src = src[:4]
+	}
+	s := string(src)
+
+	// Nothing designates G2 in ISO-2022-JP, so the single shift is only
+	// recognized in ISO-2022-JP-2.
+	if d.g2 != nil && strings.HasPrefix(s, iso2022SS2) {
+		if len(s) < 3 {
+			if !atEOF {
+				return 0, 0, ErrShortSrc
+			}
+			return 0, len(s), ErrTruncated
+		}
+		c := s[2]
+		if c < 0x20 || c > 0x7f {
+			return 0, 2, ErrInvalidSequence
+		}
+		if r := d.g2.decode[c|0x80]; r >= 0 {
+			return r, 3, nil
+		}
+		return 0, 3, ErrInvalidSequence
+	}
+
+	partial := false
+	for _, e := range d.escapes {
+		if strings.HasPrefix(s, e.seq) {
+			if e.g2 != nil {
+				d.g2 = e.g2
+			} else {
+				d.g0 = e.g0
+			}
+			return noRune, len(e.seq), nil
+		}
+		if strings.HasPrefix(e.seq, s) {
+			partial = true
+		}
+	}
+
+	switch {
+	case partial && !atEOF:
+		return 0, 0, ErrShortSrc
+	case partial:
+		return 0, len(s), ErrTruncated
+	default:
+		return 0, 1, ErrInvalidSequence
+	}
+}
+
+var (
+	_ BulkEncoder = &ISO2022JPEncoder{}
+	_ Flusher     = &ISO2022JPEncoder{}
+)
+
+// ISO2022JPEncoder writes ISO-2022-JP and ISO-2022-JP-2. Use GetEncoder to
+// get one.
+//
+// It switches sets as needed, and Flush switches back to ASCII at the end of
+// the text. ASCII characters are always written in ASCII, JIS X 0201 Roman is
+// only used for YEN SIGN and OVERLINE. Half-width katakana can't be encoded.
+type ISO2022JPEncoder struct {
+	name   string
+	sets   []iso2022Set
+	g2Sets []*charmap
+	g0     iso2022Set
+	g2     *charmap
+}
+
+// iso2022MaxBytes is the most an ISO2022JPEncoder writes for a character,
+// including escape sequences.
+const iso2022MaxBytes = 6
+
+// Encode satisfies the Encoder interface for ISO-2022-JP.
+func (e *ISO2022JPEncoder) Encode(w io.Writer, r rune) error {
+	buf := make([]byte, iso2022MaxBytes)
+	n := e.encodeChar(buf, r)
+	if n == 0 {
+		return &EncodeError{Encoding: e.name, Rune: r, Err: ErrOutOfRange}
+	}
+
+	_, err := w.Write(buf[:n])
+	return err
+}
+
+// BulkEncoder satisfies the BulkEncoder interface for ISO-2022-JP.
+func (e *ISO2022JPEncoder) BulkEncoder() BulkEncoder {
+	return e
+}
+
+// EncodeRunes satisfies the BulkEncoder interface for ISO-2022-JP.
+func (e *ISO2022JPEncoder) EncodeRunes(dst []byte, src []rune) (nDst, nSrc int, err error) {
+	for ; nSrc < len(src); nSrc++ {
+		if len(dst)-nDst < iso2022MaxBytes {
+			return nDst, nSrc, ErrShortDst
+		}
+		n := e.encodeChar(dst[nDst:], src[nSrc])
+		if n == 0 {
+			return nDst, nSrc, &EncodeError{Encoding: e.name, Rune: src[nSrc], Err: ErrOutOfRange}
+		}
+		nDst += n
+	}
+	return nDst, nSrc, nil
+}
+
+// Flush switches back to ASCII if needed. It satisfies the Flusher interface.
+func (e *ISO2022JPEncoder) Flush(w io.Writer) error {
+	defer func() {
+		e.g0 = iso2022ASCII
+		e.g2 = nil
+	}()
+
+	if e.g0 == iso2022ASCII {
+		return nil
+	}
+	_, err := io.WriteString(w, iso2022Designations[iso2022ASCII].escape)
+	return err
+}
+
+// encodeChar writes r to buf, which must have room for iso2022MaxBytes, and
+// returns the number of bytes written. It returns 0 without changing state if
+// r can't be encoded.
+func (e *ISO2022JPEncoder) encodeChar(buf []byte, r rune) int {
+	switch {
+	case r == esc || r == so || r == si:
+		return 0
+	case r >= 0 && r < 0x80:
+		n := e.designate(buf, iso2022ASCII)
+		buf[n] = byte(r)
+		return n + 1
+	case r == 0xa5 || r == 0x203e:
+		n := e.designate(buf, iso2022Roman)
+		buf[n] = 0x5c
+		if r == 0x203e {
+			buf[n] = 0x7e
+		}
+		return n + 1
+	}
+
+	for _, set := range e.sets {
+		if code, ok := iso2022Designations[set].charset.lookup(r); ok {
+			n := e.designate(buf, set)
+			buf[n] = byte(code >> 8)
+			buf[n+1] = byte(code)
+			return n + 2
+		}
+	}
+
+	for _, g2 := range e.g2Sets {
+		b, ok := g2.lookup(r)
+		if !ok || b < 0xa0 {
+			continue
+		}
+
+		n := 0
+		if e.g2 != g2 {
+			n = copy(buf, iso2022G2Designations[g2])
+			e.g2 = g2
+		}
+		n += copy(buf[n:], iso2022SS2)
+		buf[n] = b & 0x7f
+		return n + 1
+	}
+	return 0
+}
+
+// designate writes the escape sequence for set to buf if it isn't already
+// designated, and returns its length.
+func (e *ISO2022JPEncoder) designate(buf []byte, set iso2022Set) int {
+	if e.g0 == set {
+		return 0
+	}
+	e.g0 = set
+	return copy(buf, iso2022Designations[set].escape)
+}
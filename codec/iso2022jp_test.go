@@ -0,0 +1,211 @@
+package codec
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestISO2022JP(t *testing.T) {
+	cases := []struct {
+		encoding string
+		text     string
+		encoded  string
+	}{
+		{
+			encoding: "ISO-2022-JP",
+			text:     "日本語ABC",
+			encoded:  "\x1b$BF|K\\8l\x1b(BABC",
+		},
+		{
+			// Ends in ASCII
+			encoding: "ISO-2022-JP",
+			text:     "a\n日\n本",
+			encoded:  "a\n\x1b$BF|\x1b(B\n\x1b$BK\\\x1b(B",
+		},
+		{
+			encoding: "ISO-2022-JP",
+			text:     "¥1‾",
+			encoded:  "\x1b(J\\\x1b(B1\x1b(J~\x1b(B",
+		},
+		{
+			encoding: "ISO-2022-JP-2",
+			text:     "ÀΩ",
+			encoded:  "\x1b$(D*\"\x1b$B&8\x1b(B",
+		},
+		{
+			encoding: "ISO-2022-JP-2",
+			text:     "a们",
+			encoded:  "a\x1b$ACG\x1b(B",
+		},
+		{
+			encoding: "ISO-2022-JP-2",
+			text:     "한국어 们",
+			encoded:  "\x1b$(CGQ19>n\x1b(B \x1b$ACG\x1b(B",
+		},
+		{
+			// Characters that are only in the G2 sets
+			encoding: "csISO2022JP2",
+			text:     " µ₯x",
+			encoded:  "\x1b.A\x1bN \x1bN5\x1b.F\x1bN%x",
+		},
+	}
+
+	for _, c := range cases {
+		actual := &bytes.Buffer{}
+		err := Recode(strings.NewReader(c.encoded), actual, GetDecoder(c.encoding), NewUTF8Encoder())
+		if err != nil {
+			t.Errorf("%s: decode error: %v", c.encoding, err)
+		} else if actual.String() != c.text {
+			t.Errorf("%s: got %q, want %q", c.encoding, actual.String(), c.text)
+		}
+
+		actual.Reset()
+		err = Recode(strings.NewReader(c.text), actual, NewUTF8Decoder(), GetEncoder(c.encoding))
+		if err != nil {
+			t.Errorf("%s: encode error: %v", c.encoding, err)
+		} else if actual.String() != c.encoded {
+			t.Errorf("%s: got %q, want %q", c.encoding, actual.String(), c.encoded)
+		}
+	}
+}
+
+func TestISO2022JPDecode(t *testing.T) {
+	cases := []struct {
+		encoding string
+		in       string
+		expected string
+	}{
+		{
+			// The old JIS X 0208 escape
+			encoding: "ISO-2022-JP",
+			in:       "\x1b$@F|\x1b(B",
+			expected: "日",
+		},
+		{
+			encoding: "ISO-2022-JP",
+			in:       "\x1b(I12\x1b(B3",
+			expected: "ｱｲ3",
+		},
+		{
+			// Control codes and spaces are ASCII in every set
+			encoding: "ISO-2022-JP",
+			in:       "\x1b$BF| K\\\r\n8l",
+			expected: "日 本\r\n語",
+		},
+		{
+			encoding: "ISO-2022-JP-2",
+			in:       "\x1b$(ACG\x1b(B",
+			expected: "们",
+		},
+		{
+			// ISO-2022-JP doesn't have a G2
+			encoding: "ISO-2022-JP",
+			in:       "\x1b.A\x1bNi",
+			expected: "�.A�Ni",
+		},
+		{
+			encoding: "ISO-2022-JP-2",
+			in:       "\x1bNi\x1b.A\x1bNi",
+			expected: "�Nié",
+		},
+		{
+			encoding: "ISO-2022-JP",
+			in:       "\x1b$BF\x1b(B",
+			expected: "�",
+		},
+		{
+			encoding: "ISO-2022-JP",
+			in:       "a\x1b$",
+			expected: "a�",
+		},
+		{
+			// Row 9 of JIS X 0208 is empty
+			encoding: "ISO-2022-JP",
+			in:       "\x1b$B)!\x1b(Ba",
+			expected: "�a",
+		},
+		{
+			encoding: "ISO-2022-JP",
+			in:       "\x80a\x0e",
+			expected: "�a�",
+		},
+	}
+
+	for _, c := range cases {
+		actual := &bytes.Buffer{}
+		err := Recode(strings.NewReader(c.in), actual, GetDecoder(c.encoding), NewUTF8Encoder(), WithErrorPolicy(Replace))
+		if err != nil {
+			t.Errorf("%s %q: recode error: %v", c.encoding, c.in, err)
+		} else if actual.String() != c.expected {
+			t.Errorf("%s %q: got %q, want %q", c.encoding, c.in, actual.String(), c.expected)
+		}
+	}
+
+	// An escape sequence on its own isn't a character.
+	_, err := GetDecoder("ISO-2022-JP").Decode(strings.NewReader("\x1b$B"))
+	if err != io.EOF {
+		t.Errorf("got %v, want %v", err, io.EOF)
+	}
+
+	// Codes without a character are invalid.
+	for _, in := range []string{"\x1b$B)!", "\x1b(I`", "\x1b.F\x1bNR"} {
+		_, err := GetDecoder("ISO-2022-JP-2").Decode(strings.NewReader(in))
+		if !errors.Is(err, ErrInvalidSequence) {
+			t.Errorf("%q: got %v, want %v", in, err, ErrInvalidSequence)
+		}
+	}
+}
+
+func TestISO2022JPEncodeErrors(t *testing.T) {
+	for _, r := range []rune{'\x1b', '\x0e', '\x0f', 'ｱ', 'é'} {
+		var ee *EncodeError
+		err := GetEncoder("ISO-2022-JP").Encode(&bytes.Buffer{}, r)
+		if !errors.As(err, &ee) || ee.Err != ErrOutOfRange || ee.Encoding != "ISO-2022-JP" {
+			t.Errorf("%U: got %v, want %v", r, err, ErrOutOfRange)
+		}
+	}
+}
+
+func TestISO2022JPFlush(t *testing.T) {
+	e := GetEncoder("ISO-2022-JP")
+	f, ok := e.(Flusher)
+	if !ok {
+		t.Fatal("ISO-2022-JP encoder isn't a Flusher")
+	}
+
+	actual := &bytes.Buffer{}
+	e.Encode(actual, '日')
+	if actual.String() != "\x1b$BF|" {
+		t.Errorf("got %q before flushing", actual.String())
+	}
+	f.Flush(actual)
+	if actual.String() != "\x1b$BF|\x1b(B" {
+		t.Errorf("got %q after flushing", actual.String())
+	}
+
+	// Flushing in ASCII writes nothing, and the encoder starts over.
+	actual.Reset()
+	f.Flush(actual)
+	e.Encode(actual, '日')
+	if actual.String() != "\x1b$BF|" {
+		t.Errorf("got %q after flushing again", actual.String())
+	}
+
+	// Reader and Writer flush too.
+	out, err := ioutil.ReadAll(NewReader(strings.NewReader("日本"), NewUTF8Decoder(), GetEncoder("ISO-2022-JP")))
+	if err != nil || string(out) != "\x1b$BF|K\\\x1b(B" {
+		t.Errorf("Reader: got %q, %v", out, err)
+	}
+
+	actual.Reset()
+	w := NewWriter(actual, NewUTF8Decoder(), GetEncoder("ISO-2022-JP"))
+	w.Write([]byte("日本"))
+	err = w.Close()
+	if err != nil || actual.String() != "\x1b$BF|K\\\x1b(B" {
+		t.Errorf("Writer: got %q, %v", actual.String(), err)
+	}
+}
@@ -0,0 +1,225 @@
+package codec
+
+import (
+	"io"
+	"strings"
+)
+
+// ISO-2022-KR is the 7-bit encoding of RFC 1557, used in Korean email. The
+// header ESC $ ) C designates KS X 1001 to G1, then SO switches to it and SI
+// switches back to ASCII. The text has to end in ASCII, so the encoder needs
+// to be flushed.
+//
+// The encoder writes the header at the start of the text, even if it turns
+// out to be all ASCII, since RFC 1557 wants it at the start of a line. The
+// decoder doesn't require it.
+func init() {
+	registerCodec(Codec{
+		Name: "ISO-2022-KR",
+		Aliases: []string{
+			// IANA
+			"csISO2022KR",
+		},
+		NewDecoder: func(...Options) Decoder {
+			return &ISO2022KRDecoder{}
+		},
+		NewEncoder: func(...Options) Encoder {
+			return &ISO2022KREncoder{}
+		},
+		Info: charsetInfo(iso2022KRMaxBytes, nil, ksc5601Set),
+	})
+}
+
+// iso2022KRHeader designates KS X 1001 to G1.
+const iso2022KRHeader = "\x1b$)C"
+
+var _ BulkDecoder = &ISO2022KRDecoder{}
+
+// ISO2022KRDecoder reads ISO-2022-KR. Use GetDecoder to get one.
+type ISO2022KRDecoder struct {
+	in input
+
+	// shifted is true between SO and SI.
+	shifted bool
+}
+
+// Decode satisfies the Decoder interface for ISO-2022-KR.
+func (d *ISO2022KRDecoder) Decode(r io.Reader) (rune, error) {
+	return d.in.decodeRune(r, "ISO-2022-KR", d.decode)
+}
+
+// BulkDecoder satisfies the BulkDecoder interface for ISO-2022-KR.
+func (d *ISO2022KRDecoder) BulkDecoder() BulkDecoder {
+	return d
+}
+
+// DecodeBytes satisfies the BulkDecoder interface for ISO-2022-KR.
+func (d *ISO2022KRDecoder) DecodeBytes(dst []rune, src []byte, atEOF bool) (nDst, nSrc int, err error) {
+	return decodeBytes(dst, src, atEOF, "ISO-2022-KR", d.decode)
+}
+
+// Buffered returns the number of bytes that have been read but not decoded.
+func (d *ISO2022KRDecoder) Buffered() int {
+	return d.in.Buffered()
+}
+
+// decode is the decodeFunc for ISO-2022-KR.
+func (d *ISO2022KRDecoder) decode(src []byte, atEOF bool) (rune, int, error) {
+	b := src[0]
+	switch {
+	case b == esc:
+		return d.escape(src, atEOF)
+	case b == so:
+		d.shifted = true
+		return noRune, 1, nil
+	case b == si:
+		d.shifted = false
+		return noRune, 1, nil
+	case b >= 0x80:
+		return 0, 1, ErrInvalidSequence
+	case b <= 0x20 || b == 0x7f || !d.shifted:
+		// Control codes and space are ASCII even after SO.
+		return rune(b), 1, nil
+	}
+
+	if len(src) < 2 {
+		if !atEOF {
+			return 0, 0, ErrShortSrc
+		}
+		return 0, 1, ErrTruncated
+	}
+	trail := src[1]
+	if trail <= 0x20 || trail >= 0x7f {
+		return 0, 1, ErrInvalidSequence
+	}
+	if r := ksc5601.char(b, trail); r != 0 {
+		return r, 2, nil
+	}
+	return 0, 2, ErrInvalidSequence
+}
+
+// escape decodes the header at the start of src, which is the only escape
+// sequence in ISO-2022-KR.
+func (d *ISO2022KRDecoder) escape(src []byte, atEOF bool) (rune, int, error) {
+	if len(src) > len(iso2022KRHeader) {
+		src = src[:len(iso2022KRHeader)]
+	}
+	s := string(src)
+
+	switch {
+	case s == iso2022KRHeader:
+		return noRune, len(s), nil
+	case !strings.HasPrefix(iso2022KRHeader, s):
+		return 0, 1, ErrInvalidSequence
+	case !atEOF:
+		return 0, 0, ErrShortSrc
+	default:
+		return 0, len(s), ErrTruncated
+	}
+}
+
+var (
+	_ BulkEncoder = &ISO2022KREncoder{}
+	_ Flusher     = &ISO2022KREncoder{}
+)
+
+// ISO2022KREncoder writes ISO-2022-KR. Use GetEncoder to get one.
+//
+// It writes the header before the first character, shifts with SO and SI as
+// needed, and Flush shifts back to ASCII at the end of the text.
+type ISO2022KREncoder struct {
+	started bool
+	shifted bool
+}
+
+// iso2022KRMaxBytes is the most an ISO2022KREncoder writes for a character,
+// including the header and a shift.
+const iso2022KRMaxBytes = len(iso2022KRHeader) + 3
+
+// Encode satisfies the Encoder interface for ISO-2022-KR.
+func (e *ISO2022KREncoder) Encode(w io.Writer, r rune) error {
+	buf := make([]byte, iso2022KRMaxBytes)
+	n := e.encodeChar(buf, r)
+	if n == 0 {
+		return &EncodeError{Encoding: "ISO-2022-KR", Rune: r, Err: ErrOutOfRange}
+	}
+
+	_, err := w.Write(buf[:n])
+	return err
+}
+
+// BulkEncoder satisfies the BulkEncoder interface for ISO-2022-KR.
+func (e *ISO2022KREncoder) BulkEncoder() BulkEncoder {
+	return e
+}
+
+// EncodeRunes satisfies the BulkEncoder interface for ISO-2022-KR.
+func (e *ISO2022KREncoder) EncodeRunes(dst []byte, src []rune) (nDst, nSrc int, err error) {
+	for ; nSrc < len(src); nSrc++ {
+		if len(dst)-nDst < iso2022KRMaxBytes {
+			return nDst, nSrc, ErrShortDst
+		}
+		n := e.encodeChar(dst[nDst:], src[nSrc])
+		if n == 0 {
+			return nDst, nSrc, &EncodeError{Encoding: "ISO-2022-KR", Rune: src[nSrc], Err: ErrOutOfRange}
+		}
+		nDst += n
+	}
+	return nDst, nSrc, nil
+}
+
+// Flush shifts back to ASCII if needed. It satisfies the Flusher interface.
+func (e *ISO2022KREncoder) Flush(w io.Writer) error {
+	shifted := e.shifted
+	e.started = false
+	e.shifted = false
+
+	if !shifted {
+		return nil
+	}
+	_, err := w.Write([]byte{si})
+	return err
+}
+
+// encodeChar writes r to buf, which must have room for iso2022KRMaxBytes, and
+// returns the number of bytes written. It returns 0 without changing state if
+// r can't be encoded.
+func (e *ISO2022KREncoder) encodeChar(buf []byte, r rune) int {
+	var code uint16
+	switch {
+	case r == esc || r == so || r == si:
+		return 0
+	case r >= 0 && r < 0x80:
+	default:
+		var ok bool
+		code, ok = ksc5601Set.lookup(r)
+		if !ok {
+			return 0
+		}
+	}
+
+	n := 0
+	if !e.started {
+		n = copy(buf, iso2022KRHeader)
+		e.started = true
+	}
+
+	if code == 0 {
+		if e.shifted {
+			buf[n] = si
+			n++
+			e.shifted = false
+		}
+		buf[n] = byte(r)
+		return n + 1
+	}
+
+	if !e.shifted {
+		buf[n] = so
+		n++
+		e.shifted = true
+	}
+	buf[n] = byte(code >> 8)
+	buf[n+1] = byte(code)
+	return n + 2
+}
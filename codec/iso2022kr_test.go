@@ -0,0 +1,140 @@
+package codec
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestISO2022KR(t *testing.T) {
+	cases := []struct {
+		text    string
+		encoded string
+	}{
+		{
+			text:    "",
+			encoded: "",
+		},
+		{
+			text:    "ok",
+			encoded: "\x1b$)Cok",
+		},
+		{
+			text:    "한국어 ok",
+			encoded: "\x1b$)C\x0eGQ19>n\x0f ok",
+		},
+		{
+			// Ends shifted
+			text:    "a\n가\n가",
+			encoded: "\x1b$)Ca\n\x0e0!\x0f\n\x0e0!\x0f",
+		},
+		{
+			// Hangul filler
+			text:    "\u3164",
+			encoded: "\x1b$)C\x0e$T\x0f",
+		},
+	}
+
+	for _, c := range cases {
+		actual := &bytes.Buffer{}
+		err := Recode(strings.NewReader(c.encoded), actual, GetDecoder("ISO-2022-KR"), NewUTF8Encoder())
+		if err != nil {
+			t.Errorf("%q: decode error: %v", c.encoded, err)
+		} else if actual.String() != c.text {
+			t.Errorf("%q: got %q, want %q", c.encoded, actual.String(), c.text)
+		}
+
+		actual.Reset()
+		err = Recode(strings.NewReader(c.text), actual, NewUTF8Decoder(), GetEncoder("ISO-2022-KR"))
+		if err != nil {
+			t.Errorf("%q: encode error: %v", c.text, err)
+		} else if actual.String() != c.encoded {
+			t.Errorf("%q: got %q, want %q", c.text, actual.String(), c.encoded)
+		}
+	}
+}
+
+func TestISO2022KRDecode(t *testing.T) {
+	cases := []struct {
+		in       string
+		expected string
+	}{
+		{
+			// The header isn't required
+			in:       "\x0e0!\x0f",
+			expected: "가",
+		},
+		{
+			// Control codes and spaces are ASCII after SO
+			in:       "\x1b$)C\x0eGQ 19\r\n>n",
+			expected: "한 국\r\n어",
+		},
+		{
+			in:       "\x1b$(Ca",
+			expected: "�$(Ca",
+		},
+		{
+			in:       "a\x1b$)",
+			expected: "a�",
+		},
+		{
+			in:       "\x0e0",
+			expected: "�",
+		},
+		{
+			// Row 0x22 ends at 0x2267
+			in:       "\x0e\"h\x0ff",
+			expected: "�f",
+		},
+		{
+			in:       "\x0e0\n\x0f!",
+			expected: "�\n!",
+		},
+		{
+			in:       "\xb0\xa1a",
+			expected: "��a",
+		},
+	}
+
+	for _, c := range cases {
+		actual := &bytes.Buffer{}
+		err := Recode(strings.NewReader(c.in), actual, GetDecoder("ISO-2022-KR"), NewUTF8Encoder(), WithErrorPolicy(Replace))
+		if err != nil {
+			t.Errorf("%q: recode error: %v", c.in, err)
+		} else if actual.String() != c.expected {
+			t.Errorf("%q: got %q, want %q", c.in, actual.String(), c.expected)
+		}
+	}
+
+	// A code without a character is invalid.
+	_, err := GetDecoder("ISO-2022-KR").Decode(strings.NewReader("\x0e\"h"))
+	if !errors.Is(err, ErrInvalidSequence) {
+		t.Errorf("got %v, want %v", err, ErrInvalidSequence)
+	}
+}
+
+func TestISO2022KREncode(t *testing.T) {
+	for _, r := range []rune{'\x1b', '\x0e', '\x0f', '갂', '丂'} {
+		var ee *EncodeError
+		err := GetEncoder("ISO-2022-KR").Encode(&bytes.Buffer{}, r)
+		if !errors.As(err, &ee) || ee.Err != ErrOutOfRange || ee.Encoding != "ISO-2022-KR" {
+			t.Errorf("%U: got %v, want %v", r, err, ErrOutOfRange)
+		}
+	}
+
+	e := GetEncoder("ISO-2022-KR")
+	actual := &bytes.Buffer{}
+	e.Encode(actual, '가')
+	e.(Flusher).Flush(actual)
+	if actual.String() != "\x1b$)C\x0e0!\x0f" {
+		t.Errorf("got %q", actual.String())
+	}
+
+	// After flushing the encoder starts over, with a new header.
+	actual.Reset()
+	e.Encode(actual, 'a')
+	if actual.String() != "\x1b$)Ca" {
+		t.Errorf("got %q after flushing", actual.String())
+	}
+}
@@ -0,0 +1,109 @@
+package codec
+
+//go:generate go run gen_charmap.go -o iso8859_tables.go -overrides mappings/OVERRIDES.TXT iso8859_1=mappings/ISO8859/8859-1.TXT iso8859_2=mappings/ISO8859/8859-2.TXT iso8859_3=mappings/ISO8859/8859-3.TXT iso8859_4=mappings/ISO8859/8859-4.TXT iso8859_5=mappings/ISO8859/8859-5.TXT iso8859_6=mappings/ISO8859/8859-6.TXT iso8859_7=mappings/ISO8859/8859-7.TXT iso8859_8=mappings/ISO8859/8859-8.TXT iso8859_9=mappings/ISO8859/8859-9.TXT iso8859_10=mappings/ISO8859/8859-10.TXT iso8859_11=mappings/ISO8859/8859-11.TXT iso8859_13=mappings/ISO8859/8859-13.TXT iso8859_14=mappings/ISO8859/8859-14.TXT iso8859_15=mappings/ISO8859/8859-15.TXT iso8859_16=mappings/ISO8859/8859-16.TXT
+
+// The ISO-8859 encodings are single-byte encodings that extend ASCII with
+// another 96 characters in 0xA0-0xFF. Bytes 0x80-0x9F are the C1 control
+// codes. There is no ISO-8859-12, work on it was abandoned.
+func init() {
+	registerCharmap("ISO-8859-1", []string{
+		// IANA
+		"ISO_8859-1:1987", "iso-ir-100", "ISO_8859-1", "latin1", "l1",
+		"IBM819", "CP819", "csISOLatin1",
+		// Windows code page
+		"cp28591",
+	}, &iso8859_1)
+	registerCharmap("ISO-8859-2", []string{
+		// IANA
+		"ISO_8859-2:1987", "iso-ir-101", "ISO_8859-2", "latin2", "l2",
+		"csISOLatin2",
+		// Windows code page
+		"cp28592",
+	}, &iso8859_2)
+	registerCharmap("ISO-8859-3", []string{
+		// IANA
+		"ISO_8859-3:1988", "iso-ir-109", "ISO_8859-3", "latin3", "l3",
+		"csISOLatin3",
+		// Windows code page
+		"cp28593",
+	}, &iso8859_3)
+	registerCharmap("ISO-8859-4", []string{
+		// IANA
+		"ISO_8859-4:1988", "iso-ir-110", "ISO_8859-4", "latin4", "l4",
+		"csISOLatin4",
+		// Windows code page
+		"cp28594",
+	}, &iso8859_4)
+	registerCharmap("ISO-8859-5", []string{
+		// IANA
+		"ISO_8859-5:1988", "iso-ir-144", "ISO_8859-5", "cyrillic",
+		"csISOLatinCyrillic",
+		// Windows code page
+		"cp28595",
+	}, &iso8859_5)
+	registerCharmap("ISO-8859-6", []string{
+		// IANA
+		"ISO_8859-6:1987", "iso-ir-127", "ISO_8859-6", "ECMA-114",
+		"ASMO-708", "arabic", "csISOLatinArabic",
+		"ISO-8859-6-E", "csISO88596E", "ISO-8859-6-I", "csISO88596I",
+		// Windows code page
+		"cp28596",
+	}, &iso8859_6)
+	registerCharmap("ISO-8859-7", []string{
+		// IANA
+		"ISO_8859-7:1987", "iso-ir-126", "ISO_8859-7", "ELOT_928",
+		"ECMA-118", "greek", "greek8", "csISOLatinGreek",
+		// WHATWG
+		"sun_eu_greek",
+		// Windows code page
+		"cp28597",
+	}, &iso8859_7)
+	// ISO-8859-8-I has the same bytes as ISO-8859-8. The name only says
+	// the text is in logical order rather than visual order.
+	registerCharmap("ISO-8859-8", []string{
+		// IANA
+		"ISO_8859-8:1988", "iso-ir-138", "ISO_8859-8", "hebrew",
+		"csISOLatinHebrew",
+		"ISO-8859-8-E", "csISO88598E", "ISO-8859-8-I", "csISO88598I",
+		// WHATWG
+		"visual", "logical",
+		// Windows code page
+		"cp28598", "cp38598",
+	}, &iso8859_8)
+	registerCharmap("ISO-8859-9", []string{
+		// IANA
+		"ISO_8859-9:1989", "iso-ir-148", "ISO_8859-9", "latin5", "l5",
+		"csISOLatin5",
+		// Windows code page
+		"cp28599",
+	}, &iso8859_9)
+	registerCharmap("ISO-8859-10", []string{
+		// IANA
+		"ISO_8859-10:1992", "iso-ir-157", "latin6", "l6", "csISOLatin6",
+	}, &iso8859_10)
+	registerCharmap("ISO-8859-11", nil, &iso8859_11)
+	registerCharmap("ISO-8859-13", []string{
+		// IANA
+		"csISO885913",
+		// Windows code page
+		"cp28603",
+	}, &iso8859_13)
+	registerCharmap("ISO-8859-14", []string{
+		// IANA
+		"ISO_8859-14:1998", "iso-ir-199", "ISO_8859-14", "latin8",
+		"iso-celtic", "l8", "csISO885914",
+	}, &iso8859_14)
+	registerCharmap("ISO-8859-15", []string{
+		// IANA
+		"ISO_8859-15", "Latin-9", "csISO885915",
+		// WHATWG
+		"csisolatin9", "l9",
+		// Windows code page
+		"cp28605",
+	}, &iso8859_15)
+	registerCharmap("ISO-8859-16", []string{
+		// IANA
+		"ISO_8859-16:2001", "iso-ir-226", "ISO_8859-16", "latin10", "l10",
+		"csISO885916",
+	}, &iso8859_16)
+}
@@ -0,0 +1,92 @@
+package codec
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestISO8859(t *testing.T) {
+	cases := []struct {
+		encoding string
+		text     string
+		encoded  []byte
+	}{
+		{
+			encoding: "latin1",
+			text:     "Café ½\u0085",
+			encoded:  []byte{0x43, 0x61, 0x66, 0xe9, 0x20, 0xbd, 0x85},
+		},
+		{
+			encoding: "ISO-8859-2",
+			text:     "Żółć",
+			encoded:  []byte{0xaf, 0xf3, 0xb3, 0xe6},
+		},
+		{
+			encoding: "ISO-8859-5",
+			text:     "Все",
+			encoded:  []byte{0xb2, 0xe1, 0xd5},
+		},
+		{
+			encoding: "greek",
+			text:     "Ελλάδα",
+			encoded:  []byte{0xc5, 0xeb, 0xeb, 0xdc, 0xe4, 0xe1},
+		},
+		{
+			encoding: "ISO-8859-8-I",
+			text:     "שלום",
+			encoded:  []byte{0xf9, 0xec, 0xe5, 0xed},
+		},
+		{
+			encoding: "Latin-9",
+			text:     "€uro",
+			encoded:  []byte{0xa4, 0x75, 0x72, 0x6f},
+		},
+		{
+			encoding: "ISO-8859-16",
+			text:     "Țară",
+			encoded:  []byte{0xde, 0x61, 0x72, 0xe3},
+		},
+	}
+
+	for _, c := range cases {
+		actual := &bytes.Buffer{}
+		err := Recode(bytes.NewReader(c.encoded), actual, GetDecoder(c.encoding), NewUTF8Encoder())
+		if err != nil {
+			t.Errorf("%s: decode error: %v", c.encoding, err)
+		} else if actual.String() != c.text {
+			t.Errorf("%s: got %q, want %q", c.encoding, actual.String(), c.text)
+		}
+
+		actual.Reset()
+		err = Recode(strings.NewReader(c.text), actual, NewUTF8Decoder(), GetEncoder(c.encoding))
+		if err != nil {
+			t.Errorf("%s: encode error: %v", c.encoding, err)
+		} else if !bytes.Equal(actual.Bytes(), c.encoded) {
+			t.Errorf("%s: got % x, want % x", c.encoding, actual.Bytes(), c.encoded)
+		}
+	}
+}
+
+func TestISO8859Info(t *testing.T) {
+	cases := []struct {
+		encoding string
+		expected Info
+	}{
+		{
+			encoding: "ISO-8859-1",
+			expected: Info{MaxRune: 0xff, MinBytes: 1, MaxBytes: 1},
+		},
+		{
+			encoding: "ISO-8859-3",
+			expected: Info{MaxRune: 0x2d9, Repertoire: "249 characters", MinBytes: 1, MaxBytes: 1},
+		},
+	}
+
+	for _, c := range cases {
+		actual := Lookup(c.encoding).Info
+		if actual != c.expected {
+			t.Errorf("%s: got %+v, want %+v", c.encoding, actual, c.expected)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package codec
+
+//go:generate go run gen_charmap.go -o koi8_tables.go -overrides mappings/OVERRIDES.TXT koi8r=mappings/VENDORS/MISC/KOI8-R.TXT koi8u=mappings/VENDORS/MISC/KOI8-U.TXT koi8ru=mappings/VENDORS/MISC/KOI8-U.TXT
+
+// The KOI8 encodings put Cyrillic letters in the upper half in the order of
+// the Latin letters they sound like, so text stays readable if the high bit is
+// lost. Lower case letters are in 0xC0-0xDF and upper case in 0xE0-0xFF, the
+// rest of the upper half is box drawing and other symbols.
+//
+// KOI8-U replaces eight box drawing characters of KOI8-R with the Ukrainian
+// letters Є, І, Ї and Ґ in both cases. KOI8-RU adds the Belarusian Ў to
+// KOI8-U, replacing two more.
+func init() {
+	registerCharmap("KOI8-R", []string{
+		// IANA
+		"csKOI8R",
+		// WHATWG
+		"koi", "koi8",
+		// Windows code page
+		"cp20866",
+	}, &koi8r)
+	registerCharmap("KOI8-U", []string{
+		// IANA
+		"csKOI8U",
+		// Windows code page
+		"cp21866",
+	}, &koi8u)
+	registerCharmap("KOI8-RU", nil, &koi8ru)
+}
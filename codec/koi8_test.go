@@ -0,0 +1,114 @@
+package codec
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestKOI8(t *testing.T) {
+	cases := []struct {
+		encoding string
+		text     string
+		encoded  []byte
+	}{
+		{
+			encoding: "KOI8-R",
+			text:     "Привет",
+			encoded:  []byte{0xf0, 0xd2, 0xc9, 0xd7, 0xc5, 0xd4},
+		},
+		{
+			encoding: "KOI8-R",
+			text:     "┌─┐│└┘═║╔╗╚╝",
+			encoded:  []byte{0x82, 0x80, 0x83, 0x81, 0x84, 0x85, 0xa0, 0xa1, 0xa5, 0xa8, 0xab, 0xae},
+		},
+		{
+			encoding: "KOI8-U",
+			text:     "Їжак ґанок Єва Іван",
+			encoded:  []byte{0xb7, 0xd6, 0xc1, 0xcb, 0x20, 0xad, 0xc1, 0xce, 0xcf, 0xcb, 0x20, 0xb4, 0xd7, 0xc1, 0x20, 0xb6, 0xd7, 0xc1, 0xce},
+		},
+		{
+			encoding: "KOI8-RU",
+			text:     "Ўў Її",
+			encoded:  []byte{0xbe, 0xae, 0x20, 0xb7, 0xa7},
+		},
+	}
+
+	for _, c := range cases {
+		actual := &bytes.Buffer{}
+		err := Recode(bytes.NewReader(c.encoded), actual, GetDecoder(c.encoding), NewUTF8Encoder())
+		if err != nil {
+			t.Errorf("%s: decode error: %v", c.encoding, err)
+		} else if actual.String() != c.text {
+			t.Errorf("%s: got %q, want %q", c.encoding, actual.String(), c.text)
+		}
+
+		actual.Reset()
+		err = Recode(strings.NewReader(c.text), actual, NewUTF8Decoder(), GetEncoder(c.encoding))
+		if err != nil {
+			t.Errorf("%s: encode error: %v", c.encoding, err)
+		} else if !bytes.Equal(actual.Bytes(), c.encoded) {
+			t.Errorf("%s: got % x, want % x", c.encoding, actual.Bytes(), c.encoded)
+		}
+	}
+}
+
+// TestKOI8Differences checks which bytes differ between the KOI8 variants.
+func TestKOI8Differences(t *testing.T) {
+	cases := []struct {
+		a, b     string
+		expected string
+	}{
+		{
+			a:        "KOI8-R",
+			b:        "KOI8-U",
+			expected: "╓є╕і╖ї╜ґ╢Є╤І╥Ї╫Ґ",
+		},
+		{
+			a:        "KOI8-U",
+			b:        "KOI8-RU",
+			expected: "╝ў╬Ў",
+		},
+	}
+
+	for _, c := range cases {
+		da, db := GetDecoder(c.a), GetDecoder(c.b)
+
+		var actual []rune
+		for i := 0; i < 256; i++ {
+			in := []byte{byte(i)}
+			ra, err := da.Decode(bytes.NewReader(in))
+			if err != nil {
+				t.Fatalf("%s 0x%02x: %v", c.a, i, err)
+			}
+			rb, err := db.Decode(bytes.NewReader(in))
+			if err != nil {
+				t.Fatalf("%s 0x%02x: %v", c.b, i, err)
+			}
+			if ra != rb {
+				actual = append(actual, ra, rb)
+			}
+		}
+
+		if string(actual) != c.expected {
+			t.Errorf("%s and %s: got %q, want %q", c.a, c.b, string(actual), c.expected)
+		}
+	}
+
+	// Russian text is the same in all of them.
+	for _, name := range []string{"KOI8-U", "KOI8-RU"} {
+		actual := &bytes.Buffer{}
+		err := Recode(strings.NewReader("Съешь же ещё этих мягких французских булок"), actual, NewUTF8Decoder(), GetEncoder(name))
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		expected := &bytes.Buffer{}
+		err = Recode(strings.NewReader("Съешь же ещё этих мягких французских булок"), expected, NewUTF8Decoder(), GetEncoder("KOI8-R"))
+		if err != nil {
+			t.Fatalf("KOI8-R: %v", err)
+		}
+		if !bytes.Equal(actual.Bytes(), expected.Bytes()) {
+			t.Errorf("%s: got % x, want % x", name, actual.Bytes(), expected.Bytes())
+		}
+	}
+}
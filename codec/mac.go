@@ -0,0 +1,55 @@
+package codec
+
+//go:generate go run gen_charmap.go -o mac_tables.go -overrides mappings/OVERRIDES.TXT macRoman=mappings/VENDORS/APPLE/ROMAN.TXT macCentralEurRoman=mappings/VENDORS/APPLE/CENTEURO.TXT macCyrillic=mappings/VENDORS/APPLE/CYRILLIC.TXT macGreek=mappings/VENDORS/APPLE/GREEK.TXT macIcelandic=mappings/VENDORS/APPLE/ICELAND.TXT macTurkish=mappings/VENDORS/APPLE/TURKISH.TXT macSymbol=mappings/VENDORS/APPLE/SYMBOL.TXT
+
+// The Mac OS encodings are the single-byte encodings of the classic Mac OS.
+// They extend ASCII with letters and symbols in 0x80-0xFF, with no C1 control
+// codes.
+//
+// Apple maps its logo, 0xF0 in most of them, to U+F8FF in the private use
+// area. It's an ordinary character as far as the codecs are concerned, so it
+// decodes and encodes like any other.
+func init() {
+	registerCharmap("macintosh", []string{
+		// IANA
+		"mac", "csMacintosh",
+		// WHATWG
+		"x-mac-roman",
+		// Windows code page
+		"cp10000",
+		"MacRoman",
+	}, &macRoman)
+	registerCharmap("x-mac-ce", []string{
+		// Windows code page
+		"cp10029",
+		"MacCentralEurRoman", "MacCE",
+	}, &macCentralEurRoman)
+	registerCharmap("x-mac-cyrillic", []string{
+		// WHATWG
+		"x-mac-ukrainian",
+		// Windows code page
+		"cp10007",
+		"MacCyrillic",
+	}, &macCyrillic)
+	registerCharmap("x-mac-greek", []string{
+		// Windows code page
+		"cp10006",
+		"MacGreek",
+	}, &macGreek)
+	registerCharmap("x-mac-icelandic", []string{
+		// Windows code page
+		"cp10079",
+		"MacIcelandic",
+	}, &macIcelandic)
+	registerCharmap("x-mac-turkish", []string{
+		// Windows code page
+		"cp10081",
+		"MacTurkish",
+	}, &macTurkish)
+	// Mac OS Symbol is the encoding of the Symbol font: Greek letters and
+	// mathematical symbols in place of the Latin letters, and more symbols
+	// in the upper half.
+	registerCharmap("x-mac-symbol", []string{
+		"MacSymbol",
+	}, &macSymbol)
+}
@@ -0,0 +1,69 @@
+package codec
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestMac(t *testing.T) {
+	cases := []struct {
+		encoding string
+		text     string
+		encoded  []byte
+	}{
+		{
+			encoding: "MacRoman",
+			text:     "Café “€” \uf8ff",
+			encoded:  []byte{0x43, 0x61, 0x66, 0x8e, 0x20, 0xd2, 0xdb, 0xd3, 0x20, 0xf0},
+		},
+		{
+			encoding: "MacCentralEurRoman",
+			text:     "Łódź Ře",
+			encoded:  []byte{0xfc, 0x97, 0x64, 0x90, 0x20, 0xdb, 0x65},
+		},
+		{
+			encoding: "MacGreek",
+			text:     "Ελλάδα",
+			encoded:  []byte{0xb6, 0xec, 0xec, 0xc0, 0xe4, 0xe1},
+		},
+		{
+			encoding: "MacTurkish",
+			text:     "İstanbul ğş \uf8ff",
+			encoded:  []byte{0xdc, 0x73, 0x74, 0x61, 0x6e, 0x62, 0x75, 0x6c, 0x20, 0xdb, 0xdf, 0x20, 0xf0},
+		},
+		{
+			encoding: "MacIcelandic",
+			text:     "Þór Ðý",
+			encoded:  []byte{0xde, 0x97, 0x72, 0x20, 0xdc, 0xe0},
+		},
+		{
+			encoding: "MacSymbol",
+			text:     "∀ξ∈ℜ: ξ ≥ 0 \uf8ff€",
+			encoded:  []byte{0x22, 0x78, 0xce, 0xc2, 0x3a, 0x20, 0x78, 0x20, 0xb3, 0x20, 0x30, 0x20, 0xf0, 0xa0},
+		},
+		{
+			encoding: "x-mac-cyrillic",
+			text:     "Ґудзик ґава €",
+			encoded:  []byte{0xa2, 0xf3, 0xe4, 0xe7, 0xe8, 0xea, 0x20, 0xb6, 0xe0, 0xe2, 0xe0, 0x20, 0xff},
+		},
+	}
+
+	for _, c := range cases {
+		actual := &bytes.Buffer{}
+		err := Recode(bytes.NewReader(c.encoded), actual, GetDecoder(c.encoding), NewUTF8Encoder())
+		if err != nil {
+			t.Errorf("%s: decode error: %v", c.encoding, err)
+		} else if actual.String() != c.text {
+			t.Errorf("%s: got %q, want %q", c.encoding, actual.String(), c.text)
+		}
+
+		actual.Reset()
+		err = Recode(strings.NewReader(c.text), actual, NewUTF8Decoder(), GetEncoder(c.encoding))
+		if err != nil {
+			t.Errorf("%s: encode error: %v", c.encoding, err)
+		} else if !bytes.Equal(actual.Bytes(), c.encoded) {
+			t.Errorf("%s: got % x, want % x", c.encoding, actual.Bytes(), c.encoded)
+		}
+	}
+}
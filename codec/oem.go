@@ -0,0 +1,89 @@
+package codec
+
+//go:generate go run gen_charmap.go -o oem_tables.go -overrides mappings/OVERRIDES.TXT ibm437=mappings/VENDORS/MICSFT/PC/CP437.TXT ibm737=mappings/VENDORS/MICSFT/PC/CP737.TXT ibm775=mappings/VENDORS/MICSFT/PC/CP775.TXT ibm850=mappings/VENDORS/MICSFT/PC/CP850.TXT ibm852=mappings/VENDORS/MICSFT/PC/CP852.TXT ibm855=mappings/VENDORS/MICSFT/PC/CP855.TXT ibm857=mappings/VENDORS/MICSFT/PC/CP857.TXT ibm858=mappings/VENDORS/MICSFT/PC/CP858.TXT ibm860=mappings/VENDORS/MICSFT/PC/CP860.TXT ibm861=mappings/VENDORS/MICSFT/PC/CP861.TXT ibm862=mappings/VENDORS/MICSFT/PC/CP862.TXT ibm863=mappings/VENDORS/MICSFT/PC/CP863.TXT ibm864=mappings/VENDORS/MICSFT/PC/CP864.TXT ibm865=mappings/VENDORS/MICSFT/PC/CP865.TXT ibm866=mappings/VENDORS/MICSFT/PC/CP866.TXT ibm869=mappings/VENDORS/MICSFT/PC/CP869.TXT
+
+// The OEM code pages are the single-byte encodings used by DOS and the Windows
+// console. The upper half holds accented letters, another alphabet and box
+// drawing characters.
+func init() {
+	registerCharmap("IBM437", []string{
+		// IANA
+		"cp437", "437", "csPC8CodePage437",
+	}, &ibm437)
+	registerCharmap("IBM437-graphic", []string{
+		"cp437-graphic",
+	}, &ibm437Graphic)
+	registerCharmap("IBM737", []string{
+		// Windows code page
+		"cp737",
+	}, &ibm737)
+	registerCharmap("IBM775", []string{
+		// IANA
+		"cp775", "csPC775Baltic",
+	}, &ibm775)
+	registerCharmap("IBM850", []string{
+		// IANA
+		"cp850", "850", "csPC850Multilingual",
+	}, &ibm850)
+	registerCharmap("IBM852", []string{
+		// IANA
+		"cp852", "852", "csPCp852",
+	}, &ibm852)
+	registerCharmap("IBM855", []string{
+		// IANA
+		"cp855", "855", "csIBM855",
+	}, &ibm855)
+	registerCharmap("IBM857", []string{
+		// IANA
+		"cp857", "857", "csIBM857",
+	}, &ibm857)
+	registerCharmap("IBM00858", []string{
+		// IANA
+		"CCSID00858", "CP00858", "PC-Multilingual-850+euro", "csIBM00858",
+		// Windows code page
+		"cp858", "IBM858",
+	}, &ibm858)
+	registerCharmap("IBM860", []string{
+		// IANA
+		"cp860", "860", "csIBM860",
+	}, &ibm860)
+	registerCharmap("IBM861", []string{
+		// IANA
+		"cp861", "861", "cp-is", "csIBM861",
+	}, &ibm861)
+	registerCharmap("IBM862", []string{
+		// IANA
+		"cp862", "862", "csPC862LatinHebrew",
+	}, &ibm862)
+	registerCharmap("IBM863", []string{
+		// IANA
+		"cp863", "863", "csIBM863",
+	}, &ibm863)
+	registerCharmap("IBM864", []string{
+		// IANA
+		"cp864", "csIBM864",
+	}, &ibm864)
+	registerCharmap("IBM865", []string{
+		// IANA
+		"cp865", "865", "csIBM865",
+	}, &ibm865)
+	registerCharmap("IBM866", []string{
+		// IANA
+		"cp866", "866", "csIBM866",
+	}, &ibm866)
+	registerCharmap("IBM869", []string{
+		// IANA
+		"cp869", "869", "cp-gr", "csIBM869",
+	}, &ibm869)
+}
+
+// ibm437Graphic is IBM437 with the glyphs the IBM PC displayed for control
+// codes 0x01-0x1F and 0x7F, for screen dumps and text written for display.
+// Since line breaks are control codes as well, it's only useful for text
+// without them.
+var ibm437Graphic = func() [256]rune {
+	table := ibm437
+	copy(table[0x01:0x20], []rune("☺☻♥♦♣♠•◘○◙♂♀♪♫☼►◄↕‼¶§▬↨↑↓→←∟↔▲▼"))
+	table[0x7f] = '⌂'
+	return table
+}()
@@ -0,0 +1,85 @@
+package codec
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestOEM(t *testing.T) {
+	cases := []struct {
+		encoding string
+		text     string
+		encoded  []byte
+	}{
+		{
+			encoding: "CP437",
+			text:     "╔═╗ñ\x01\n",
+			encoded:  []byte{0xc9, 0xcd, 0xbb, 0xa4, 0x01, 0x0a},
+		},
+		{
+			encoding: "IBM437-graphic",
+			text:     "☺☻♥◙⌂ ╔",
+			encoded:  []byte{0x01, 0x02, 0x03, 0x0a, 0x7f, 0x20, 0xc9},
+		},
+		{
+			encoding: "850",
+			text:     "Ñandú",
+			encoded:  []byte{0xa5, 0x61, 0x6e, 0x64, 0xa3},
+		},
+		{
+			encoding: "cp852",
+			text:     "Łódź",
+			encoded:  []byte{0x9d, 0xa2, 0x64, 0xab},
+		},
+		{
+			encoding: "IBM866",
+			text:     "Привет",
+			encoded:  []byte{0x8f, 0xe0, 0xa8, 0xa2, 0xa5, 0xe2},
+		},
+		{
+			encoding: "cp862",
+			text:     "שלום",
+			encoded:  []byte{0x99, 0x8c, 0x85, 0x8d},
+		},
+	}
+
+	for _, c := range cases {
+		actual := &bytes.Buffer{}
+		err := Recode(bytes.NewReader(c.encoded), actual, GetDecoder(c.encoding), NewUTF8Encoder())
+		if err != nil {
+			t.Errorf("%s: decode error: %v", c.encoding, err)
+		} else if actual.String() != c.text {
+			t.Errorf("%s: got %q, want %q", c.encoding, actual.String(), c.text)
+		}
+
+		actual.Reset()
+		err = Recode(strings.NewReader(c.text), actual, NewUTF8Decoder(), GetEncoder(c.encoding))
+		if err != nil {
+			t.Errorf("%s: encode error: %v", c.encoding, err)
+		} else if !bytes.Equal(actual.Bytes(), c.encoded) {
+			t.Errorf("%s: got % x, want % x", c.encoding, actual.Bytes(), c.encoded)
+		}
+	}
+}
+
+func TestIBM437Graphic(t *testing.T) {
+	// Only the control codes differ from IBM437.
+	plain, graphic := GetDecoder("IBM437"), GetDecoder("IBM437-graphic")
+	for i := 0; i < 256; i++ {
+		in := []byte{byte(i)}
+		p, err := plain.Decode(bytes.NewReader(in))
+		if err != nil {
+			t.Fatalf("0x%02x: %v", i, err)
+		}
+		g, err := graphic.Decode(bytes.NewReader(in))
+		if err != nil {
+			t.Fatalf("0x%02x: %v", i, err)
+		}
+
+		control := (i >= 0x01 && i < 0x20) || i == 0x7f
+		if control == (p == g) {
+			t.Errorf("0x%02x: got %U and %U", i, p, g)
+		}
+	}
+}
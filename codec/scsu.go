@@ -0,0 +1,627 @@
+package codec
+
+import "io"
+
+// SCSU is the Standard Compression Scheme for Unicode (Unicode Technical
+// Standard #6). In single-byte mode ASCII is written as itself and bytes
+// 0x80-0xFF select from a window of 128 characters, one of eight that can be
+// moved around the code space with tag bytes. Text that doesn't fit in small
+// windows, like Chinese, is written in Unicode mode as UTF-16BE.
+//
+// The encoder looks a few characters ahead to decide when to move or switch
+// windows and when to change modes, so it needs to be flushed at the end of
+// the text.
+func init() {
+	registerCodec(Codec{
+		Name: "SCSU",
+		Aliases: []string{
+			// IANA
+			"csSCSU",
+		},
+		NewDecoder: func(...Options) Decoder {
+			return &SCSUDecoder{state: newSCSUState()}
+		},
+		NewEncoder: func(...Options) Encoder {
+			return &SCSUEncoder{state: newSCSUState()}
+		},
+		Info: Info{MaxRune: 0x10ffff, MinBytes: 1, MaxBytes: scsuMaxBytes},
+	})
+}
+
+// Tag bytes. The single-byte mode tags are followed by the window number in
+// the low three bits, as are UCn and UDn.
+const (
+	scsuSQ0 = 0x01 // quote one character from a window
+	scsuSDX = 0x0b // define an extended window
+	scsuSQU = 0x0e // quote one UTF-16 code unit
+	scsuSCU = 0x0f // change to Unicode mode
+	scsuSC0 = 0x10 // change to a window
+	scsuSD0 = 0x18 // define a window and change to it
+	scsuUC0 = 0xe0 // change to single-byte mode and a window
+	scsuUD0 = 0xe8 // define a window and change to single-byte mode
+	scsuUQU = 0xf0 // quote one UTF-16 code unit
+	scsuUDX = 0xf1 // define an extended window and change to single-byte mode
+	scsuUR  = 0xf2 // reserved
+)
+
+// scsuMaxBytes is the most an SCSUEncoder writes for a character, such as an
+// extended window definition followed by the character.
+const scsuMaxBytes = 4
+
+// scsuStaticWindows are the offsets of the windows that can only be used by
+// quoting.
+var scsuStaticWindows = [8]rune{0x0000, 0x0080, 0x0100, 0x0300, 0x2000, 0x2080, 0x2100, 0x3000}
+
+// scsuInitialWindows are the offsets of the dynamic windows at the start of
+// the text.
+var scsuInitialWindows = [8]rune{0x0080, 0x00c0, 0x0400, 0x0600, 0x0900, 0x3040, 0x30a0, 0xff00}
+
+// scsuFixedOffsets are the offsets selected by bytes 0xF9-0xFF after SDn or
+// UDn, for scripts that don't line up with multiples of 0x80.
+var scsuFixedOffsets = [7]rune{0x00c0, 0x0250, 0x0370, 0x0530, 0x3040, 0x30a0, 0xff60}
+
+// scsuOffset returns the window offset for b, the byte after SDn or UDn.
+func scsuOffset(b byte) (rune, bool) {
+	switch {
+	case b == 0 || (b >= 0xa8 && b < 0xf9):
+		return 0, false
+	case b < 0x68:
+		return rune(b) << 7, true
+	case b < 0xa8:
+		return rune(b)<<7 + 0xac00, true
+	default:
+		return scsuFixedOffsets[b-0xf9], true
+	}
+}
+
+// scsuState is the state shared by the encoder and decoder.
+type scsuState struct {
+	unicode bool
+	window  int
+	offsets [8]rune
+}
+
+func newSCSUState() scsuState {
+	return scsuState{offsets: scsuInitialWindows}
+}
+
+// defineExtended sets a window above U+FFFF from the two bytes after SDX or
+// UDX, and changes to it.
+func (st *scsuState) defineExtended(hi, lo byte) {
+	n := int(hi >> 5)
+	st.offsets[n] = 0x10000 + (rune(hi&0x1f)<<8|rune(lo))<<7
+	st.window = n
+}
+
+// scsuWindow reports whether r is in the window at offset.
+func scsuWindow(offset, r rune) bool {
+	return r >= offset && r < offset+0x80
+}
+
+// scsuDirect reports whether r is written as itself in single-byte mode.
+func scsuDirect(r rune) bool {
+	return r == 0 || r == '\t' || r == '\n' || r == '\r' || (r >= 0x20 && r < 0x80)
+}
+
+var _ BulkDecoder = &SCSUDecoder{}
+
+// SCSUDecoder reads SCSU. Use GetDecoder to get one.
+type SCSUDecoder struct {
+	in    input
+	state scsuState
+}
+
+// Decode satisfies the Decoder interface for SCSU.
+func (d *SCSUDecoder) Decode(r io.Reader) (rune, error) {
+	return d.in.decodeRune(r, "SCSU", d.decode)
+}
+
+// BulkDecoder satisfies the BulkDecoder interface for SCSU.
+func (d *SCSUDecoder) BulkDecoder() BulkDecoder {
+	return d
+}
+
+// DecodeBytes satisfies the BulkDecoder interface for SCSU.
+func (d *SCSUDecoder) DecodeBytes(dst []rune, src []byte, atEOF bool) (nDst, nSrc int, err error) {
+	return decodeBytes(dst, src, atEOF, "SCSU", d.decode)
+}
+
+// Buffered returns the number of bytes that have been read but not decoded.
+func (d *SCSUDecoder) Buffered() int {
+	return d.in.Buffered()
+}
+
+// decode is the decodeFunc for SCSU. It works on a copy of the state, so
+// nothing changes when it returns ErrShortSrc.
+func (d *SCSUDecoder) decode(src []byte, atEOF bool) (rune, int, error) {
+	st := d.state
+	char, n, err := st.next(src, atEOF)
+	switch {
+	case err == ErrShortSrc:
+		return 0, 0, err
+	case err != nil || char < utf16HighSurrogate || char > 0xdfff:
+		d.state = st
+		return char, n, err
+	case char >= utf16LowSurrogate:
+		d.state = st
+		return 0, n, ErrLoneSurrogate
+	}
+
+	// A high surrogate has to be followed by a low surrogate, possibly
+	// after some tags.
+	high, highN, highState := char, n, st
+	for {
+		if n == len(src) {
+			if !atEOF {
+				return 0, 0, ErrShortSrc
+			}
+			break
+		}
+
+		low, m, err := st.next(src[n:], atEOF)
+		if err == ErrShortSrc {
+			return 0, 0, err
+		}
+		if err != nil || (low != noRune && (low < utf16LowSurrogate || low > 0xdfff)) {
+			break
+		}
+		n += m
+		if low != noRune {
+			d.state = st
+			return 0x10000 + (high&0x3ff)<<10 | low&0x3ff, n, nil
+		}
+	}
+
+	d.state = highState
+	return 0, highN, ErrLoneSurrogate
+}
+
+// next decodes a character, or a UTF-16 code unit that may be half of one,
+// from the start of src and updates st. It returns noRune for a tag that
+// only changes the state.
+func (st *scsuState) next(src []byte, atEOF bool) (rune, int, error) {
+	// need checks there are at least n bytes.
+	need := func(n int) error {
+		switch {
+		case len(src) >= n:
+			return nil
+		case !atEOF:
+			return ErrShortSrc
+		default:
+			return ErrTruncated
+		}
+	}
+
+	b := src[0]
+	if st.unicode {
+		switch {
+		case b >= scsuUC0 && b < scsuUC0+8:
+			st.unicode = false
+			st.window = int(b - scsuUC0)
+			return noRune, 1, nil
+		case b >= scsuUD0 && b < scsuUD0+8:
+			if err := need(2); err != nil {
+				return 0, len(src), err
+			}
+			offset, ok := scsuOffset(src[1])
+			if !ok {
+				return 0, 2, ErrInvalidSequence
+			}
+			st.unicode = false
+			st.window = int(b - scsuUD0)
+			st.offsets[st.window] = offset
+			return noRune, 2, nil
+		case b == scsuUQU:
+			if err := need(3); err != nil {
+				return 0, len(src), err
+			}
+			return rune(src[1])<<8 | rune(src[2]), 3, nil
+		case b == scsuUDX:
+			if err := need(3); err != nil {
+				return 0, len(src), err
+			}
+			st.unicode = false
+			st.defineExtended(src[1], src[2])
+			return noRune, 3, nil
+		case b == scsuUR:
+			return 0, 1, ErrInvalidSequence
+		default:
+			if err := need(2); err != nil {
+				return 0, len(src), err
+			}
+			return rune(b)<<8 | rune(src[1]), 2, nil
+		}
+	}
+
+	switch {
+	case scsuDirect(rune(b)):
+		return rune(b), 1, nil
+	case b >= 0x80:
+		return st.offsets[st.window] + rune(b-0x80), 1, nil
+	case b >= scsuSQ0 && b < scsuSQ0+8:
+		if err := need(2); err != nil {
+			return 0, len(src), err
+		}
+		n, c := b-scsuSQ0, src[1]
+		if c < 0x80 {
+			return scsuStaticWindows[n] + rune(c), 2, nil
+		}
+		return st.offsets[n] + rune(c-0x80), 2, nil
+	case b == scsuSDX:
+		if err := need(3); err != nil {
+			return 0, len(src), err
+		}
+		st.defineExtended(src[1], src[2])
+		return noRune, 3, nil
+	case b == scsuSQU:
+		if err := need(3); err != nil {
+			return 0, len(src), err
+		}
+		return rune(src[1])<<8 | rune(src[2]), 3, nil
+	case b == scsuSCU:
+		st.unicode = true
+		return noRune, 1, nil
+	case b >= scsuSC0 && b < scsuSC0+8:
+		st.window = int(b - scsuSC0)
+		return noRune, 1, nil
+	case b >= scsuSD0 && b < scsuSD0+8:
+		if err := need(2); err != nil {
+			return 0, len(src), err
+		}
+		offset, ok := scsuOffset(src[1])
+		if !ok {
+			return 0, 2, ErrInvalidSequence
+		}
+		st.window = int(b - scsuSD0)
+		st.offsets[st.window] = offset
+		return noRune, 2, nil
+	default:
+		// 0x0C is reserved.
+		return 0, 1, ErrInvalidSequence
+	}
+}
+
+var (
+	_ BulkEncoder = &SCSUEncoder{}
+	_ Flusher     = &SCSUEncoder{}
+)
+
+// SCSUEncoder writes SCSU. Use GetEncoder to get one.
+//
+// Characters are held back until the encoder has seen the next few, so Flush
+// must be called at the end of the text.
+type SCSUEncoder struct {
+	state scsuState
+
+	// lastUsed holds when each dynamic window was last used, counted in
+	// characters, so the least recently used one can be redefined.
+	lastUsed [8]int
+	clock    int
+
+	// pending holds the characters that haven't been encoded yet, at
+	// most scsuLookahead of them.
+	pending []rune
+}
+
+// scsuLookahead is the number of characters the encoder considers at once:
+// the one being encoded and the ones after it.
+const scsuLookahead = 4
+
+// Encode satisfies the Encoder interface for SCSU.
+func (e *SCSUEncoder) Encode(w io.Writer, r rune) error {
+	if err := checkRune(r); err != nil {
+		return &EncodeError{Encoding: "SCSU", Rune: r, Err: err}
+	}
+
+	e.pending = append(e.pending, r)
+	if len(e.pending) < scsuLookahead {
+		return nil
+	}
+
+	buf := make([]byte, scsuMaxBytes)
+	n := e.encodeNext(buf)
+	_, err := w.Write(buf[:n])
+	return err
+}
+
+// BulkEncoder satisfies the BulkEncoder interface for SCSU.
+func (e *SCSUEncoder) BulkEncoder() BulkEncoder {
+	return e
+}
+
+// EncodeRunes satisfies the BulkEncoder interface for SCSU.
+func (e *SCSUEncoder) EncodeRunes(dst []byte, src []rune) (nDst, nSrc int, err error) {
+	for ; nSrc < len(src); nSrc++ {
+		r := src[nSrc]
+		if err := checkRune(r); err != nil {
+			return nDst, nSrc, &EncodeError{Encoding: "SCSU", Rune: r, Err: err}
+		}
+		if len(e.pending) == scsuLookahead-1 && len(dst)-nDst < scsuMaxBytes {
+			return nDst, nSrc, ErrShortDst
+		}
+
+		e.pending = append(e.pending, r)
+		if len(e.pending) == scsuLookahead {
+			nDst += e.encodeNext(dst[nDst:])
+		}
+	}
+	return nDst, nSrc, nil
+}
+
+// Flush writes the characters that were held back. It satisfies the Flusher
+// interface.
+func (e *SCSUEncoder) Flush(w io.Writer) error {
+	buf := make([]byte, len(e.pending)*scsuMaxBytes)
+	n := 0
+	for len(e.pending) > 0 {
+		n += e.encodeNext(buf[n:])
+	}
+	*e = SCSUEncoder{state: newSCSUState(), pending: e.pending}
+
+	if n == 0 {
+		return nil
+	}
+	_, err := w.Write(buf[:n])
+	return err
+}
+
+// encodeNext encodes the first pending character to buf, which must have room
+// for scsuMaxBytes, and returns the number of bytes written.
+func (e *SCSUEncoder) encodeNext(buf []byte) int {
+	r, ahead := e.pending[0], e.pending[1:]
+	e.clock++
+
+	var n int
+	if e.state.unicode {
+		n = e.encodeUnicode(buf, r, ahead)
+	} else {
+		n = e.encodeSingleByte(buf, r, ahead)
+	}
+
+	copy(e.pending, ahead)
+	e.pending = e.pending[:len(ahead)]
+	return n
+}
+
+// encodeSingleByte encodes r in single-byte mode, possibly changing modes.
+func (e *SCSUEncoder) encodeSingleByte(buf []byte, r rune, ahead []rune) int {
+	st := &e.state
+	if scsuDirect(r) {
+		buf[0] = byte(r)
+		return 1
+	}
+
+	if w := e.findWindow(r); w >= 0 {
+		e.lastUsed[w] = e.clock
+		offset := st.offsets[w]
+		if w == st.window {
+			buf[0] = byte(r - offset + 0x80)
+			return 1
+		}
+
+		// Change windows if the next character is in the same one,
+		// otherwise just quote this one.
+		if len(ahead) > 0 && scsuWindow(offset, ahead[0]) {
+			st.window = w
+			buf[0] = scsuSC0 + byte(w)
+		} else {
+			buf[0] = scsuSQ0 + byte(w)
+		}
+		buf[1] = byte(r - offset + 0x80)
+		return 2
+	}
+
+	for w, offset := range scsuStaticWindows {
+		if scsuWindow(offset, r) {
+			buf[0] = scsuSQ0 + byte(w)
+			buf[1] = byte(r - offset)
+			return 2
+		}
+	}
+
+	// A new window costs two bytes, or three above U+FFFF, so it's
+	// only worth it for more than one character.
+	if offset, ok := scsuNewOffset(r); ok && (r > 0xffff || e.runLength(offset, ahead) > 1) {
+		w := e.leastRecent()
+		n := e.define(buf, w, offset, scsuSD0, scsuSDX)
+		buf[n] = byte(r - offset + 0x80)
+		return n + 1
+	}
+
+	// Switch to Unicode mode if quoting would cost as much, counting the
+	// switch back unless the text ends first.
+	k := 1
+	for k <= len(ahead) && e.needsUnicode(ahead[k-1]) {
+		k++
+	}
+	switchCost := 1 + 2*k
+	if k <= len(ahead) || len(ahead) == scsuLookahead-1 {
+		switchCost++
+	}
+	if switchCost <= 3*k {
+		st.unicode = true
+		buf[0] = scsuSCU
+		return 1 + e.encodeUnicode(buf[1:], r, ahead)
+	}
+
+	buf[0] = scsuSQU
+	buf[1] = byte(r >> 8)
+	buf[2] = byte(r)
+	return 3
+}
+
+// encodeUnicode encodes r in Unicode mode, possibly changing to single-byte
+// mode if what follows would be shorter there.
+func (e *SCSUEncoder) encodeUnicode(buf []byte, r rune, ahead []rune) int {
+	st := &e.state
+
+	// Find the window r would use in single-byte mode, and what it
+	// would cost to change to it.
+	w, offset, tagCost := e.findWindow(r), rune(0), 1
+	switch {
+	case scsuDirect(r):
+		w, offset = st.window, st.offsets[st.window]
+	case w >= 0:
+		offset = st.offsets[w]
+	default:
+		var ok bool
+		if offset, ok = scsuNewOffset(r); ok {
+			w, tagCost = e.leastRecent(), 2
+			if offset > 0xffff {
+				tagCost = 3
+			}
+		}
+	}
+
+	if w >= 0 {
+		// Compare the run of characters that would be single bytes
+		// in that window with writing them in UTF-16, counting the
+		// change back unless the text ends first.
+		k := 1 + e.runLength(offset, ahead)
+		stayCost := scsuUnicodeLen(r)
+		for _, c := range ahead[:k-1] {
+			stayCost += scsuUnicodeLen(c)
+		}
+		switchCost := tagCost + k
+		if k <= len(ahead) || len(ahead) == scsuLookahead-1 {
+			switchCost++
+		}
+
+		if switchCost < stayCost {
+			st.unicode = false
+			n := 1
+			if tagCost == 1 {
+				st.window = w
+				buf[0] = scsuUC0 + byte(w)
+			} else {
+				n = e.define(buf, w, offset, scsuUD0, scsuUDX)
+			}
+			e.lastUsed[w] = e.clock
+			return n + e.encodeSingleByte(buf[n:], r, ahead)
+		}
+	}
+
+	if r > 0xffff {
+		r -= 0x10000
+		high, low := utf16HighSurrogate|r>>10, utf16LowSurrogate|r&0x3ff
+		buf[0], buf[1] = byte(high>>8), byte(high)
+		buf[2], buf[3] = byte(low>>8), byte(low)
+		return 4
+	}
+
+	// Code units that start with a tag byte have to be quoted.
+	n := 0
+	if hi := byte(r >> 8); hi >= scsuUC0 && hi <= scsuUR {
+		buf[0] = scsuUQU
+		n = 1
+	}
+	buf[n] = byte(r >> 8)
+	buf[n+1] = byte(r)
+	return n + 2
+}
+
+// scsuUnicodeLen returns roughly how many bytes r takes in Unicode mode.
+func scsuUnicodeLen(r rune) int {
+	if r > 0xffff {
+		return 4
+	}
+	return 2
+}
+
+// define writes the tag that moves window w to offset and changes to it, using
+// defineTag below U+10000 and extendedTag above. It returns the number of
+// bytes written.
+func (e *SCSUEncoder) define(buf []byte, w int, offset rune, defineTag, extendedTag byte) int {
+	st := &e.state
+	st.window = w
+	st.offsets[w] = offset
+	e.lastUsed[w] = e.clock
+
+	if offset > 0xffff {
+		v := (offset - 0x10000) >> 7
+		buf[0] = extendedTag
+		buf[1] = byte(w<<5) | byte(v>>8)
+		buf[2] = byte(v)
+		return 3
+	}
+
+	buf[0] = defineTag + byte(w)
+	for i, fixed := range scsuFixedOffsets {
+		if offset == fixed {
+			buf[1] = 0xf9 + byte(i)
+			return 2
+		}
+	}
+	if offset >= 0xe000 {
+		buf[1] = byte((offset - 0xac00) >> 7)
+	} else {
+		buf[1] = byte(offset >> 7)
+	}
+	return 2
+}
+
+// findWindow returns the dynamic window r is in, preferring the current one,
+// or -1.
+func (e *SCSUEncoder) findWindow(r rune) int {
+	st := &e.state
+	if scsuWindow(st.offsets[st.window], r) {
+		return st.window
+	}
+	for w, offset := range st.offsets {
+		if scsuWindow(offset, r) {
+			return w
+		}
+	}
+	return -1
+}
+
+// leastRecent returns the dynamic window that was used least recently.
+func (e *SCSUEncoder) leastRecent() int {
+	lru := len(e.lastUsed) - 1
+	for w := lru - 1; w >= 0; w-- {
+		if e.lastUsed[w] < e.lastUsed[lru] {
+			lru = w
+		}
+	}
+	return lru
+}
+
+// runLength returns how many characters at the start of ahead could be
+// written in single-byte mode with the window at offset.
+func (e *SCSUEncoder) runLength(offset rune, ahead []rune) int {
+	for i, c := range ahead {
+		if !scsuDirect(c) && !scsuWindow(offset, c) {
+			return i
+		}
+	}
+	return len(ahead)
+}
+
+// needsUnicode reports whether r can only be quoted in single-byte mode.
+func (e *SCSUEncoder) needsUnicode(r rune) bool {
+	if scsuDirect(r) || e.findWindow(r) >= 0 {
+		return false
+	}
+	for _, offset := range scsuStaticWindows {
+		if scsuWindow(offset, r) {
+			return false
+		}
+	}
+	_, ok := scsuNewOffset(r)
+	return !ok
+}
+
+// scsuNewOffset returns the offset of a window that could be defined for r.
+// Only the small scripts below U+3400, and characters from U+E000 up, can
+// have one.
+func scsuNewOffset(r rune) (rune, bool) {
+	for _, offset := range scsuFixedOffsets {
+		if scsuWindow(offset, r) {
+			return offset, true
+		}
+	}
+	if r < 0x80 || (r >= 0x3400 && r < 0xe000) {
+		return 0, false
+	}
+	return r &^ 0x7f, true
+}
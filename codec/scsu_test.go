@@ -0,0 +1,248 @@
+package codec
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSCSU(t *testing.T) {
+	cases := []struct {
+		text    string
+		encoded []byte
+	}{
+		{
+			// From UTS #6
+			text:    "Öl fließt",
+			encoded: []byte{0xd6, 0x6c, 0x20, 0x66, 0x6c, 0x69, 0x65, 0xdf, 0x74},
+		},
+		{
+			// From UTS #6
+			text:    "Москва",
+			encoded: []byte{0x12, 0x9c, 0xbe, 0xc1, 0xba, 0xb2, 0xb0},
+		},
+		{
+			text:    "中文",
+			encoded: []byte{0x0f, 0x4e, 0x2d, 0x65, 0x87},
+		},
+		{
+			text:    "😀😁",
+			encoded: []byte{0x0b, 0xe1, 0xec, 0x80, 0x81},
+		},
+		{
+			text:    "aé€",
+			encoded: []byte{'a', 0xe9, 0x06, 0x2c},
+		},
+	}
+
+	for _, c := range cases {
+		actual := &bytes.Buffer{}
+		err := Recode(bytes.NewReader(c.encoded), actual, GetDecoder("SCSU"), NewUTF8Encoder())
+		if err != nil {
+			t.Errorf("% x: decode error: %v", c.encoded, err)
+		} else if actual.String() != c.text {
+			t.Errorf("% x: got %q, want %q", c.encoded, actual.String(), c.text)
+		}
+
+		actual.Reset()
+		err = Recode(strings.NewReader(c.text), actual, NewUTF8Decoder(), GetEncoder("SCSU"))
+		if err != nil {
+			t.Errorf("%q: encode error: %v", c.text, err)
+		} else if !bytes.Equal(actual.Bytes(), c.encoded) {
+			t.Errorf("%q: got % x, want % x", c.text, actual.Bytes(), c.encoded)
+		}
+	}
+}
+
+func TestSCSUDecode(t *testing.T) {
+	cases := []struct {
+		in       []byte
+		expected string
+	}{
+		{
+			// Quoted from a static and a dynamic window
+			in:       []byte{0x01, 0x41, 0x02, 0xa9, 'b'},
+			expected: "Aéb",
+		},
+		{
+			// Unicode mode and back
+			in:       []byte{0x0f, 0x4e, 0x2d, 0xe0, 'a', 0xe9},
+			expected: "中aé",
+		},
+		{
+			// A byte that looks like a tag is quoted in Unicode mode
+			in:       []byte{0x0f, 0xf0, 0xe0, 0x00},
+			expected: "",
+		},
+		{
+			// A surrogate pair in two quotes
+			in:       []byte{0x0e, 0xd8, 0x3d, 0x0e, 0xde, 0x00},
+			expected: "😀",
+		},
+		{
+			// A tag between the surrogates
+			in:       []byte{0x0f, 0xd8, 0x3d, 0xe1, 0x0f, 0xde, 0x00},
+			expected: "😀",
+		},
+		{
+			// Define window 1 at U+0100, change to window 0 and back
+			in:       []byte{0x19, 0x02, 0x81, 0x10, 0x81, 0x11, 0x81},
+			expected: "ā\u0081ā",
+		},
+		{
+			// Reserved tags
+			in:       []byte{0x0c, 'a', 0x0f, 0xf2},
+			expected: "�a�",
+		},
+		{
+			// Invalid window offset
+			in:       []byte{0x18, 0x00, 'a'},
+			expected: "�a",
+		},
+		{
+			// Lone surrogates
+			in:       []byte{0x0e, 0xd8, 0x3d, 'a', 0x0e, 0xde, 0x00},
+			expected: "�a�",
+		},
+		{
+			in:       []byte{0x0f, 0xd8, 0x3d},
+			expected: "�",
+		},
+		{
+			// Truncated
+			in:       []byte{'a', 0x0f, 0x4e},
+			expected: "a�",
+		},
+		{
+			in:       []byte{'a', 0x0b, 0xe1},
+			expected: "a�",
+		},
+	}
+
+	for _, c := range cases {
+		for _, decoder := range []Decoder{GetDecoder("SCSU"), decoderOnly(GetDecoder("SCSU"))} {
+			actual := &bytes.Buffer{}
+			err := Recode(bytes.NewReader(c.in), actual, decoder, NewUTF8Encoder(), WithErrorPolicy(Replace))
+			if err != nil {
+				t.Errorf("% x: recode error: %v", c.in, err)
+			} else if actual.String() != c.expected {
+				t.Errorf("% x: got %q, want %q", c.in, actual.String(), c.expected)
+			}
+		}
+	}
+}
+
+func TestSCSUEncode(t *testing.T) {
+	// Characters are held back until Flush.
+	e := GetEncoder("SCSU")
+	actual := &bytes.Buffer{}
+	e.Encode(actual, 'М')
+	e.Encode(actual, 'о')
+	if err := e.(Flusher).Flush(actual); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(actual.Bytes(), []byte{0x12, 0x9c, 0xbe}) {
+		t.Errorf("got % x, want 12 9c be", actual.Bytes())
+	}
+
+	// And the encoder starts over afterwards, so a single Cyrillic
+	// character is quoted instead of changing windows.
+	actual.Reset()
+	e.Encode(actual, 'М')
+	e.(Flusher).Flush(actual)
+	if !bytes.Equal(actual.Bytes(), []byte{0x03, 0x9c}) {
+		t.Errorf("got % x, want 03 9c", actual.Bytes())
+	}
+
+	for _, r := range []rune{0xd800, 0xdc00, 0x110000} {
+		var ee *EncodeError
+		err := GetEncoder("SCSU").Encode(&bytes.Buffer{}, r)
+		if !errors.As(err, &ee) || ee.Encoding != "SCSU" || ee.Rune != r {
+			t.Errorf("%U: got %v", r, err)
+		}
+	}
+}
+
+// sampleText is a UTF-8 sample from testdata/detect.
+type sampleText struct {
+	lang string
+	text []byte
+}
+
+// sampleTexts reads the UTF-8 samples in testdata/detect, which cover several
+// scripts.
+func sampleTexts(tb testing.TB) []sampleText {
+	files, err := filepath.Glob(filepath.Join("testdata", "detect", "UTF-8", "*.txt"))
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	samples := make([]sampleText, len(files))
+	for i, file := range files {
+		text, err := ioutil.ReadFile(file)
+		if err != nil {
+			tb.Fatal(err)
+		}
+		samples[i] = sampleText{lang: strings.TrimSuffix(filepath.Base(file), ".txt"), text: text}
+	}
+	return samples
+}
+
+// checkSampleRoundTrip checks that encoding gives back the same UTF-8 for each
+// of the samples, with and without the bulk methods.
+func checkSampleRoundTrip(t *testing.T, encoding string) {
+	t.Helper()
+
+	for _, sample := range sampleTexts(t) {
+		var encoded [][]byte
+		for _, encoder := range []Encoder{GetEncoder(encoding), encoderOnly(GetEncoder(encoding))} {
+			out := &bytes.Buffer{}
+			err := Recode(bytes.NewReader(sample.text), out, NewUTF8Decoder(), encoder)
+			if err != nil {
+				t.Fatalf("%s %s: encode error: %v", encoding, sample.lang, err)
+			}
+			encoded = append(encoded, out.Bytes())
+		}
+		if !bytes.Equal(encoded[0], encoded[1]) {
+			t.Errorf("%s %s: EncodeRunes and Encode differ", encoding, sample.lang)
+		}
+
+		for _, decoder := range []Decoder{GetDecoder(encoding), decoderOnly(GetDecoder(encoding))} {
+			actual := &bytes.Buffer{}
+			err := Recode(bytes.NewReader(encoded[0]), actual, decoder, NewUTF8Encoder())
+			if err != nil {
+				t.Errorf("%s %s: decode error: %v", encoding, sample.lang, err)
+			} else if !bytes.Equal(actual.Bytes(), sample.text) {
+				t.Errorf("%s %s: got %q, want %q", encoding, sample.lang, actual.Bytes(), sample.text)
+			}
+		}
+	}
+}
+
+func TestSCSUSamples(t *testing.T) {
+	checkSampleRoundTrip(t, "SCSU")
+}
+
+// BenchmarkCompression encodes the samples in each language and reports the
+// size compared to UTF-8 as "ratio".
+func BenchmarkCompression(b *testing.B) {
+	for _, encoding := range []string{"SCSU", "BOCU-1", "UTF-16BE"} {
+		for _, sample := range sampleTexts(b) {
+			b.Run(encoding+"/"+sample.lang, func(b *testing.B) {
+				b.SetBytes(int64(len(sample.text)))
+				out := &bytes.Buffer{}
+				for i := 0; i < b.N; i++ {
+					out.Reset()
+					err := Recode(bytes.NewReader(sample.text), out, NewUTF8Decoder(), GetEncoder(encoding))
+					if err != nil {
+						b.Fatal(err)
+					}
+				}
+				b.ReportMetric(float64(out.Len())/float64(len(sample.text)), "ratio")
+			})
+		}
+	}
+}
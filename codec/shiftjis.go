@@ -0,0 +1,35 @@
+package codec
+
+//go:generate go run gen_dbcs.go -o shiftjis_tables.go -overrides mappings/OVERRIDES.TXT shiftJIS=mappings/OBSOLETE/EASTASIA/JIS/SHIFTJIS.TXT cp932=mappings/VENDORS/MICSFT/WINDOWS/CP932.TXT
+
+// Shift_JIS encodes JIS X 0201 in single bytes, with ASCII in 0x00-0x7F and
+// half-width katakana in 0xA1-0xDF, and JIS X 0208 in two bytes with a lead
+// byte from 0x81-0x9F or 0xE0-0xEF.
+//
+// windows-31j is Microsoft's version, better known as code page 932. It adds
+// the NEC special characters in row 13 (lead byte 0x87), IBM extensions in
+// 0xFA-0xFC, NEC's copy of the IBM extensions in 0xED-0xEE, and user defined
+// characters in 0xF0-0xF9, which decode to the private use area. It also maps
+// a few JIS characters differently, such as 0x8160 to FULLWIDTH TILDE rather
+// than WAVE DASH.
+//
+// Some characters have more than one code in windows-31j. They're encoded the
+// way Windows does: with the JIS X 0208 code if there is one, then the NEC row
+// 13 code, then the IBM extension code. The NEC copies in 0xED-0xEE are never
+// written.
+func init() {
+	registerDBCS(&dbcs{name: "Shift_JIS", table: &shiftJIS}, []string{
+		// IANA
+		"MS_Kanji", "csShiftJIS",
+		// WHATWG
+		"sjis", "x-sjis",
+	})
+	registerDBCS(&dbcs{name: "windows-31j", table: &cp932, fallbackMin: 0xed, fallbackMax: 0xee}, []string{
+		// IANA
+		"csWindows31J",
+		// WHATWG
+		"ms932",
+		// Windows code page
+		"cp932",
+	})
+}
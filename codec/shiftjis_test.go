@@ -0,0 +1,139 @@
+package codec
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestShiftJIS(t *testing.T) {
+	cases := []struct {
+		encoding string
+		text     string
+		encoded  []byte
+	}{
+		{
+			encoding: "Shift_JIS",
+			text:     "日本語",
+			encoded:  []byte{0x93, 0xfa, 0x96, 0x7b, 0x8c, 0xea},
+		},
+		{
+			// Half-width katakana are single bytes
+			encoding: "sjis",
+			text:     "ﾃｽﾄ｡",
+			encoded:  []byte{0xc3, 0xbd, 0xc4, 0xa1},
+		},
+		{
+			// 0x5C and 0x7E are ASCII
+			encoding: "Shift_JIS",
+			text:     `C:\~`,
+			encoded:  []byte{0x43, 0x3a, 0x5c, 0x7e},
+		},
+		{
+			// First and last lead and trail bytes
+			encoding: "Shift_JIS",
+			text:     "\u3000ー熙",
+			encoded:  []byte{0x81, 0x40, 0x81, 0x5b, 0xea, 0xa4},
+		},
+		{
+			encoding: "Shift_JIS",
+			text:     "〜",
+			encoded:  []byte{0x81, 0x60},
+		},
+		{
+			encoding: "cp932",
+			text:     "～",
+			encoded:  []byte{0x81, 0x60},
+		},
+		{
+			// NEC row 13
+			encoding: "windows-31j",
+			text:     "①㍻",
+			encoded:  []byte{0x87, 0x40, 0x87, 0x7e},
+		},
+		{
+			// IBM extensions, up to the last lead byte
+			encoding: "windows-31j",
+			text:     "ⅰ髙黑",
+			encoded:  []byte{0xfa, 0x40, 0xfb, 0xfc, 0xfc, 0x4b},
+		},
+		{
+			// User defined characters
+			encoding: "windows-31j",
+			text:     "\ue000\ue757",
+			encoded:  []byte{0xf0, 0x40, 0xf9, 0xfc},
+		},
+	}
+
+	for _, c := range cases {
+		actual := &bytes.Buffer{}
+		err := Recode(bytes.NewReader(c.encoded), actual, GetDecoder(c.encoding), NewUTF8Encoder())
+		if err != nil {
+			t.Errorf("%s: decode error: %v", c.encoding, err)
+		} else if actual.String() != c.text {
+			t.Errorf("%s: got %q, want %q", c.encoding, actual.String(), c.text)
+		}
+
+		actual.Reset()
+		err = Recode(strings.NewReader(c.text), actual, NewUTF8Decoder(), GetEncoder(c.encoding))
+		if err != nil {
+			t.Errorf("%s: encode error: %v", c.encoding, err)
+		} else if !bytes.Equal(actual.Bytes(), c.encoded) {
+			t.Errorf("%s: got % x, want % x", c.encoding, actual.Bytes(), c.encoded)
+		}
+	}
+}
+
+// TestCP932Duplicates checks which code windows-31j uses for characters that
+// have more than one.
+func TestCP932Duplicates(t *testing.T) {
+	cases := []struct {
+		char    rune
+		codes   [][]byte
+		encoded []byte
+	}{
+		{
+			// JIS X 0208 before NEC row 13 and the IBM extensions
+			char:    '∵',
+			codes:   [][]byte{{0x81, 0xe6}, {0x87, 0x9a}, {0xfa, 0x5b}},
+			encoded: []byte{0x81, 0xe6},
+		},
+		{
+			char:    '￢',
+			codes:   [][]byte{{0x81, 0xca}, {0xee, 0xf9}, {0xfa, 0x54}},
+			encoded: []byte{0x81, 0xca},
+		},
+		{
+			// NEC row 13 before the IBM extensions
+			char:    'Ⅰ',
+			codes:   [][]byte{{0x87, 0x54}, {0xfa, 0x4a}},
+			encoded: []byte{0x87, 0x54},
+		},
+		{
+			// IBM extensions before NEC's copy of them
+			char:    'ⅰ',
+			codes:   [][]byte{{0xee, 0xef}, {0xfa, 0x40}},
+			encoded: []byte{0xfa, 0x40},
+		},
+		{
+			char:    '纊',
+			codes:   [][]byte{{0xed, 0x40}, {0xfa, 0x5c}},
+			encoded: []byte{0xfa, 0x5c},
+		},
+	}
+
+	for _, c := range cases {
+		for _, code := range c.codes {
+			char, err := GetDecoder("windows-31j").Decode(bytes.NewReader(code))
+			if err != nil || char != c.char {
+				t.Errorf("% x: got %U, %v, want %U", code, char, err, c.char)
+			}
+		}
+
+		actual := &bytes.Buffer{}
+		err := GetEncoder("windows-31j").Encode(actual, c.char)
+		if err != nil || !bytes.Equal(actual.Bytes(), c.encoded) {
+			t.Errorf("%U: got % x, %v, want % x", c.char, actual.Bytes(), err, c.encoded)
+		}
+	}
+}
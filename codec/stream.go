@@ -0,0 +1,108 @@
+package codec
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+)
+
+var _ io.Reader = &Reader{}
+
+// Reader reads characters from an underlying reader with a Decoder and
+// returns them encoded with an Encoder.
+type Reader struct {
+	rc  *recoder
+	buf bytes.Buffer
+	err error
+}
+
+// NewReader returns an io.Reader that reads from r, decoding with decoder and
+// re-encoding with encoder. Errors are handled according to opts, the same as
+// Recode.
+//
+// Characters are only read from r as they're needed, so a character split
+// across multiple reads is handled by the decoder.
+func NewReader(r io.Reader, decoder Decoder, encoder Encoder, opts ...RecodeOption) io.Reader {
+	return &Reader{
+		rc: newRecoder(bufio.NewReader(r), decoder, encoder, opts),
+	}
+}
+
+// Read satisfies the io.Reader interface.
+func (r *Reader) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+
+	for r.buf.Len() < len(p) && r.err == nil {
+		r.err = r.rc.next(&r.buf)
+		if r.err == io.EOF {
+			if err := r.rc.flush(&r.buf); err != nil {
+				r.err = err
+			}
+		}
+	}
+
+	if r.buf.Len() > 0 {
+		return r.buf.Read(p)
+	}
+	return 0, r.err
+}
+
+var _ io.WriteCloser = &Writer{}
+
+// Writer accepts bytes in one encoding and writes them to an underlying writer
+// in another.
+type Writer struct {
+	pw   *io.PipeWriter
+	done chan error
+}
+
+// NewWriter returns an io.WriteCloser that decodes the bytes written to it
+// with decoder, then writes them to w with encoder. Errors are handled
+// according to opts, the same as Recode.
+//
+// Bytes may be written in any size of chunk, a character split across two
+// calls to Write will be decoded once the rest of it arrives. Output is
+// buffered, so Close must be called to flush it. Close also reports an error
+// if the input ended with a partial character.
+func NewWriter(w io.Writer, decoder Decoder, encoder Encoder, opts ...RecodeOption) io.WriteCloser {
+	pr, pw := io.Pipe()
+
+	done := make(chan error, 1)
+	go func() {
+		err := Recode(bufio.NewReader(pr), w, decoder, encoder, opts...)
+
+		// Unblock any pending writes if Recode stopped early.
+		if err != nil {
+			pr.CloseWithError(err)
+		} else {
+			pr.Close()
+		}
+
+		done <- err
+	}()
+
+	return &Writer{
+		pw:   pw,
+		done: done,
+	}
+}
+
+// Write satisfies the io.Writer interface.
+func (w *Writer) Write(p []byte) (int, error) {
+	return w.pw.Write(p)
+}
+
+// Close flushes any buffered output and returns the first error encountered
+// while recoding. Close does not close the underlying writer.
+func (w *Writer) Close() error {
+	if w.done == nil {
+		return nil
+	}
+
+	w.pw.Close()
+	err := <-w.done
+	w.done = nil
+	return err
+}
@@ -0,0 +1,137 @@
+package codec
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"testing"
+	"testing/iotest"
+)
+
+func TestReader(t *testing.T) {
+	cases := []struct {
+		in       []byte
+		decoder  Decoder
+		encoder  Encoder
+		expected []byte
+	}{
+		{
+			in:       []byte("Down the 🐇 hole"),
+			decoder:  NewUTF8Decoder(),
+			encoder:  NewUTF16BEEncoder(),
+			expected: []byte{0xfe, 0xff, 0x00, 0x44, 0x00, 0x6f, 0x00, 0x77, 0x00, 0x6e, 0x00, 0x20, 0x00, 0x74, 0x00, 0x68, 0x00, 0x65, 0x00, 0x20, 0xd8, 0x3d, 0xdc, 0x07, 0x00, 0x20, 0x00, 0x68, 0x00, 0x6f, 0x00, 0x6c, 0x00, 0x65},
+		},
+		{
+			in:       []byte{0x3d, 0xd8, 0x07, 0xdc, 0x20, 0x22},
+			decoder:  NewUTF16LEDecoder(),
+			encoder:  NewUTF8Encoder(),
+			expected: []byte("🐇∠"),
+		},
+	}
+
+	for _, c := range cases {
+		// OneByteReader on both ends splits every multi-byte character
+		// across reads.
+		r := NewReader(iotest.OneByteReader(bytes.NewReader(c.in)), c.decoder, c.encoder)
+		actual, err := ioutil.ReadAll(iotest.OneByteReader(r))
+		if err != nil {
+			t.Errorf("read error: %v", err)
+			continue
+		}
+
+		if !bytes.Equal(actual, c.expected) {
+			t.Errorf("got %v, want %v", actual, c.expected)
+		}
+	}
+}
+
+func TestReaderError(t *testing.T) {
+	r := NewReader(bytes.NewReader([]byte("x🌎")), NewUTF8Decoder(), NewASCIIEncoder())
+	actual, err := ioutil.ReadAll(r)
+	if err == nil {
+		t.Fatalf("got nil error, want encoding error")
+	}
+
+	if string(actual) != "x" {
+		t.Errorf("got %q, want %q", actual, "x")
+	}
+}
+
+func TestWriter(t *testing.T) {
+	cases := []struct {
+		in       []byte
+		decoder  Decoder
+		encoder  Encoder
+		expected []byte
+	}{
+		{
+			in:       []byte("∠؉₡🌎"),
+			decoder:  NewUTF8Decoder(),
+			encoder:  NewUTF32BEEncoder(),
+			expected: []byte{0x00, 0x00, 0x22, 0x20, 0x00, 0x00, 0x06, 0x09, 0x00, 0x00, 0x20, 0xA1, 0x00, 0x01, 0xF3, 0x0E},
+		},
+		{
+			in:       []byte{0xd8, 0x3d, 0xdc, 0x07, 0x00, 0x48},
+			decoder:  NewUTF16BEDecoder(),
+			encoder:  NewUTF8Encoder(),
+			expected: []byte("🐇H"),
+		},
+	}
+
+	for _, c := range cases {
+		actual := &bytes.Buffer{}
+		w := NewWriter(actual, c.decoder, c.encoder)
+
+		// Write one byte at a time so every multi-byte character is
+		// split across calls to Write.
+		for i := range c.in {
+			_, err := w.Write(c.in[i : i+1])
+			if err != nil {
+				t.Fatalf("write error: %v", err)
+			}
+		}
+
+		err := w.Close()
+		if err != nil {
+			t.Errorf("close error: %v", err)
+			continue
+		}
+
+		if !bytes.Equal(actual.Bytes(), c.expected) {
+			t.Errorf("got %v, want %v", actual.Bytes(), c.expected)
+		}
+	}
+}
+
+func TestWriterCopy(t *testing.T) {
+	in := []byte{0xff, 0xfe, 0x52, 0x00, 0x61, 0x00, 0x62, 0x00, 0x62, 0x00, 0x69, 0x00, 0x74, 0x00}
+
+	actual := &bytes.Buffer{}
+	w := NewWriter(actual, NewUTF16Decoder(), NewUTF8Encoder())
+	_, err := io.Copy(w, bytes.NewReader(in))
+	if err != nil {
+		t.Fatalf("copy error: %v", err)
+	}
+
+	err = w.Close()
+	if err != nil {
+		t.Fatalf("close error: %v", err)
+	}
+
+	if actual.String() != "Rabbit" {
+		t.Errorf("got %q, want %q", actual.String(), "Rabbit")
+	}
+}
+
+func TestWriterPartialCharacter(t *testing.T) {
+	w := NewWriter(ioutil.Discard, NewUTF8Decoder(), NewUTF8Encoder())
+	_, err := w.Write([]byte{'x', 0xf0, 0x9f})
+	if err != nil {
+		t.Fatalf("write error: %v", err)
+	}
+
+	err = w.Close()
+	if err == nil {
+		t.Errorf("got nil error, want error for truncated character")
+	}
+}
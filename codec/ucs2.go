@@ -1,140 +1,370 @@
 package codec
 
 import (
+	"encoding/binary"
 	"errors"
 	"io"
 )
 
 func init() {
-	registerCodec("UCS-2", NewUCS2Decoder, NewUCS2Encoder)
-	registerCodec("UCS-2BE", NewUCS2BEDecoder, NewUCS2BEEncoder)
-	registerCodec("UCS-2LE", NewUCS2LEDecoder, NewUCS2LEEncoder)
+	registerCodec(Codec{
+		Name: "UCS-2",
+		Aliases: []string{
+			// IANA
+			"ISO-10646-UCS-2", "csUnicode",
+		},
+		NewDecoder: NewUCS2Decoder,
+		NewEncoder: NewUCS2Encoder,
+		Info:       Info{MaxRune: 0xffff, MinBytes: 2, MaxBytes: 2, ReadsBOM: true},
+	})
+	registerCodec(Codec{
+		Name:       "UCS-2BE",
+		NewDecoder: NewUCS2BEDecoder,
+		NewEncoder: NewUCS2BEEncoder,
+		Info:       Info{MaxRune: 0xffff, MinBytes: 2, MaxBytes: 2, ReadsBOM: true, WritesBOM: true},
+	})
+	registerCodec(Codec{
+		Name:       "UCS-2LE",
+		NewDecoder: NewUCS2LEDecoder,
+		NewEncoder: NewUCS2LEEncoder,
+		Info:       Info{MaxRune: 0xffff, MinBytes: 2, MaxBytes: 2, ReadsBOM: true, WritesBOM: true},
+	})
 }
 
+var _ BulkDecoder = &UCS2Decoder{}
+
 // UCS2Decoder reads UCS-2 characters. UCS-2 is a character encoding where each
 // code point takes exactly 2 bytes. It can only encode characters up to
 // U+FFFF.
 type UCS2Decoder struct {
+	input
+	name      string
 	byteOrder byteOrder
+	bom       BOMPolicy
+	started   bool
+
+	// loneSurrogates makes the byte order guess allow surrogates that
+	// aren't part of a pair. UTF16Decoder sets it.
+	loneSurrogates bool
 }
 
 // NewUCS2Decoder returns a UCS-2 decoder.
 //
 // If the encoded text begins with a byte order mark (U+FEFF) that will
-// determine the endianness used. Otherwise, it defaults to little-endian.
-func NewUCS2Decoder() Decoder {
-	return &UCS2Decoder{}
+// determine the endianness used. Otherwise, the decoder reads ahead up to 512
+// bytes and guesses the byte order from them. Use ByteOrder to find out which
+// was chosen.
+func NewUCS2Decoder(opts ...Options) Decoder {
+	return &UCS2Decoder{
+		name: "UCS-2",
+		bom:  mergeOptions(opts).BOM,
+	}
 }
 
 // NewUCS2LEDecoder returns a UCS-2 decoder with a little-endian byte order.
-func NewUCS2LEDecoder() Decoder {
+//
+// A little-endian byte order mark at the start of the input is removed unless
+// opts say otherwise.
+func NewUCS2LEDecoder(opts ...Options) Decoder {
 	return &UCS2Decoder{
+		name:      "UCS-2LE",
 		byteOrder: littleEndian,
+		bom:       mergeOptions(opts).BOM,
 	}
 }
 
 // NewUCS2BEDecoder returns a UCS-2 decoder with a big-endian byte order.
-func NewUCS2BEDecoder() Decoder {
+//
+// A big-endian byte order mark at the start of the input is removed unless
+// opts say otherwise.
+func NewUCS2BEDecoder(opts ...Options) Decoder {
 	return &UCS2Decoder{
+		name:      "UCS-2BE",
 		byteOrder: bigEndian,
+		bom:       mergeOptions(opts).BOM,
 	}
 }
 
 // Decode satifies the Decoder interface for UCS-2.
 func (d *UCS2Decoder) Decode(r io.Reader) (rune, error) {
-	var buf = make([]byte, 2)
-	_, err := io.ReadFull(r, buf)
+	char, _, err := d.decodeUnit(r)
+	return char, err
+}
+
+// decodeUnit reads one 16-bit code unit, returning it along with the bytes it
+// was read from.
+func (d *UCS2Decoder) decodeUnit(r io.Reader) (rune, []byte, error) {
+	buf, err := d.readUnit(r)
 	if err != nil {
-		return 0, err
+		return 0, nil, err
 	}
 
+	if !d.started {
+		d.started = true
+		buf, err = d.start(r, buf)
+		if err != nil {
+			return 0, nil, err
+		}
+	}
+
+	switch d.byteOrder {
+	case bigEndian:
+		return (rune(buf[0]) << 8) | rune(buf[1]), buf, nil
+	case littleEndian:
+		return (rune(buf[1]) << 8) | rune(buf[0]), buf, nil
+	default:
+		return 0, nil, errors.New("unknown byte order")
+	}
+}
+
+// start handles a byte order mark in buf, the first code unit of the input,
+// and settles the byte order. It returns the first code unit to decode.
+func (d *UCS2Decoder) start(r io.Reader, buf []byte) ([]byte, error) {
+	order := d.bomOrder(buf)
+	if d.byteOrder == unknownByteOrder {
+		if order == unknownByteOrder {
+			d.unread(buf)
+			return d.detectByteOrder(r)
+		}
+		d.byteOrder = order
+	}
+
+	if !d.skipBOM(order) {
+		return buf, nil
+	}
+	return d.readUnit(r)
+}
+
+// bomOrder returns the byte order of a byte order mark at the start of buf, or
+// unknownByteOrder if there isn't one or byte order marks are being ignored.
+func (d *UCS2Decoder) bomOrder(buf []byte) byteOrder {
+	switch {
+	case d.bom == BOMNever:
+		return unknownByteOrder
+	case buf[0] == 0xfe && buf[1] == 0xff:
+		return bigEndian
+	case buf[0] == 0xff && buf[1] == 0xfe:
+		return littleEndian
+	default:
+		return unknownByteOrder
+	}
+}
+
+// skipBOM reports whether a byte order mark found by bomOrder should be
+// removed. A byte order mark for the other byte order is U+FFFE, which is left
+// for the caller to deal with.
+func (d *UCS2Decoder) skipBOM(order byteOrder) bool {
+	return order != unknownByteOrder && order == d.byteOrder && d.bom != BOMPreserve
+}
+
+// startBytes is start for DecodeBytes. It returns the number of bytes to skip
+// for a byte order mark.
+func (d *UCS2Decoder) startBytes(src []byte, atEOF bool) (int, error) {
+	if len(src) < 2 {
+		if atEOF {
+			// Leave it to the caller to report the truncated
+			// input.
+			return 0, nil
+		}
+		return 0, ErrShortSrc
+	}
+
+	order := d.bomOrder(src)
 	if d.byteOrder == unknownByteOrder {
-		if buf[0] == 0xfe && buf[1] == 0xff {
-			d.byteOrder = bigEndian
-			_, err = io.ReadFull(r, buf)
-		} else if buf[0] == 0xff && buf[1] == 0xfe {
-			d.byteOrder = littleEndian
-			_, err = io.ReadFull(r, buf)
-		} else {
-			d.byteOrder = littleEndian
+		if order == unknownByteOrder {
+			if len(src) < byteOrderWindow && !atEOF {
+				return 0, ErrShortSrc
+			}
+			window := src
+			if len(window) > byteOrderWindow {
+				window = window[:byteOrderWindow]
+			}
+			order = guessUTF16ByteOrder(window, len(window) == byteOrderWindow, d.loneSurrogates)
+			d.byteOrder = order
+			d.started = true
+			return 0, nil
 		}
+		d.byteOrder = order
+	}
+	d.started = true
 
+	if d.skipBOM(order) {
+		return 2, nil
+	}
+	return 0, nil
+}
+
+// unit returns the code unit at the start of buf.
+func (d *UCS2Decoder) unit(buf []byte) rune {
+	if d.byteOrder == bigEndian {
+		return rune(buf[0])<<8 | rune(buf[1])
+	}
+	return rune(buf[1])<<8 | rune(buf[0])
+}
+
+// BulkDecoder satisfies the BulkDecoder interface for UCS-2.
+func (d *UCS2Decoder) BulkDecoder() BulkDecoder {
+	return d
+}
+
+// DecodeBytes satisfies the BulkDecoder interface for UCS-2.
+func (d *UCS2Decoder) DecodeBytes(dst []rune, src []byte, atEOF bool) (nDst, nSrc int, err error) {
+	if !d.started {
+		nSrc, err = d.startBytes(src, atEOF)
 		if err != nil {
-			return 0, err
+			return 0, 0, err
 		}
 	}
 
+	for ; nSrc+2 <= len(src); nSrc += 2 {
+		if nDst == len(dst) {
+			return nDst, nSrc, ErrShortDst
+		}
+		dst[nDst] = d.unit(src[nSrc:])
+		nDst++
+	}
+
+	if nSrc < len(src) {
+		if !atEOF {
+			return nDst, nSrc, ErrShortSrc
+		}
+		return nDst, nSrc, &DecodeError{Encoding: d.name, Bytes: copyBytes(src[nSrc:]), Err: ErrTruncated}
+	}
+	return nDst, nSrc, nil
+}
+
+// detectByteOrder reads ahead to guess the byte order of input without a byte
+// order mark, then returns the first code unit.
+func (d *UCS2Decoder) detectByteOrder(r io.Reader) ([]byte, error) {
+	window := make([]byte, byteOrderWindow)
+	n, err := d.readFull(r, window)
+	d.unread(window[:n])
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return nil, err
+	}
+
+	d.byteOrder = guessUTF16ByteOrder(window[:n], n == len(window), d.loneSurrogates)
+	return d.readUnit(r)
+}
+
+// ByteOrder returns the byte order the decoder is using. For a decoder that
+// reads the byte order from the input it returns nil until the first call to
+// Decode.
+func (d *UCS2Decoder) ByteOrder() binary.ByteOrder {
 	switch d.byteOrder {
 	case bigEndian:
-		return (rune(buf[0]) << 8) | rune(buf[1]), nil
+		return binary.BigEndian
 	case littleEndian:
-		return (rune(buf[1]) << 8) | rune(buf[0]), nil
+		return binary.LittleEndian
 	default:
-		return 0, errors.New("unknown byte order")
+		return nil
 	}
 }
 
+// readUnit reads the two bytes of a code unit.
+func (d *UCS2Decoder) readUnit(r io.Reader) ([]byte, error) {
+	var buf = make([]byte, 2)
+	n, err := d.readFull(r, buf)
+	if err == io.ErrUnexpectedEOF {
+		return nil, &DecodeError{Encoding: d.name, Bytes: buf[:n], Err: ErrTruncated}
+	}
+	return buf, err
+}
+
+var _ BulkEncoder = &UCS2Encoder{}
+
 // UCS2Encoder encodes unicode code points using exactly two bytes.
 // It can only encode characters up to U+FFFF.
 type UCS2Encoder struct {
+	name      string
 	byteOrder byteOrder
-	writeBOM  bool
+	bom       bomEncoder
 }
 
 // NewUCS2Encoder returns a UCS-2 encoder with a little-endian byte order.
 //
 // This is identical to NewUCS2LEEncoder except this one does not write a byte
-// order mark.
-func NewUCS2Encoder() Encoder {
+// order mark unless opts ask for one.
+func NewUCS2Encoder(opts ...Options) Encoder {
 	return &UCS2Encoder{
+		name:      "UCS-2",
 		byteOrder: littleEndian,
-		writeBOM:  false,
+		bom:       newBOMEncoder(mergeOptions(opts).BOM, false),
 	}
 }
 
 // NewUCS2LEEncoder returns a UCS-2 encoder with a little-endian byte order.
 //
-// It will write a byte order mark with the first character.
-func NewUCS2LEEncoder() Encoder {
+// It will write a byte order mark with the first character unless opts say
+// otherwise.
+func NewUCS2LEEncoder(opts ...Options) Encoder {
 	return &UCS2Encoder{
+		name:      "UCS-2LE",
 		byteOrder: littleEndian,
-		writeBOM:  true,
+		bom:       newBOMEncoder(mergeOptions(opts).BOM, true),
 	}
 }
 
 // NewUCS2BEEncoder returns a UCS-2 encoder with a big-endian byte order.
 //
-// It will write a byte order mark with the first character.
-func NewUCS2BEEncoder() Encoder {
+// It will write a byte order mark with the first character unless opts say
+// otherwise.
+func NewUCS2BEEncoder(opts ...Options) Encoder {
 	return &UCS2Encoder{
+		name:      "UCS-2BE",
 		byteOrder: bigEndian,
-		writeBOM:  true,
+		bom:       newBOMEncoder(mergeOptions(opts).BOM, true),
 	}
 }
 
 // Encode satifies the Encoder interface for UCS-2.
 func (d *UCS2Encoder) Encode(w io.Writer, r rune) error {
-	if r > 0xffff {
-		return errors.New("character out of range")
+	if r < 0 || r > 0xffff {
+		return &EncodeError{Encoding: d.name, Rune: r, Err: ErrOutOfRange}
 	}
 
-	buf := make([]byte, 2)
+	buf := make([]byte, 4)
+	n := d.encodeUnit(buf, r)
+	_, err := w.Write(buf[:n])
+	return err
+}
+
+// BulkEncoder satisfies the BulkEncoder interface for UCS-2.
+func (d *UCS2Encoder) BulkEncoder() BulkEncoder {
+	return d
+}
 
-	if d.writeBOM {
-		d.writeBOM = false
-		if d.byteOrder == bigEndian {
-			buf[0] = 0xfe
-			buf[1] = 0xff
-		} else {
-			buf[1] = 0xfe
-			buf[0] = 0xff
+// EncodeRunes satisfies the BulkEncoder interface for UCS-2.
+func (d *UCS2Encoder) EncodeRunes(dst []byte, src []rune) (nDst, nSrc int, err error) {
+	for ; nSrc < len(src); nSrc++ {
+		r := src[nSrc]
+		if r < 0 || r > 0xffff {
+			return nDst, nSrc, &EncodeError{Encoding: d.name, Rune: r, Err: ErrOutOfRange}
 		}
-		_, err := w.Write(buf)
-		if err != nil {
-			return err
+		if len(dst)-nDst < 4 {
+			return nDst, nSrc, ErrShortDst
 		}
+		nDst += d.encodeUnit(dst[nDst:], r)
 	}
+	return nDst, nSrc, nil
+}
 
+// encodeUnit writes the code unit r to buf, preceded by a byte order mark if
+// one's needed, and returns the number of bytes written. buf must have room
+// for 4 bytes.
+func (d *UCS2Encoder) encodeUnit(buf []byte, r rune) int {
+	var n int
+	writeBOM, skip := d.bom.start(r)
+	if writeBOM {
+		n += d.put(buf, bomRune)
+	}
+	if skip {
+		return n
+	}
+	return n + d.put(buf[n:], r)
+}
+
+func (d *UCS2Encoder) put(buf []byte, r rune) int {
 	if d.byteOrder == bigEndian {
 		buf[0] = byte(r >> 8)
 		buf[1] = byte(r)
@@ -142,6 +372,5 @@ func (d *UCS2Encoder) Encode(w io.Writer, r rune) error {
 		buf[0] = byte(r)
 		buf[1] = byte(r >> 8)
 	}
-	_, err := w.Write(buf)
-	return err
+	return 2
 }
@@ -2,6 +2,7 @@ package codec
 
 import (
 	"bytes"
+	"encoding/binary"
 	"testing"
 )
 
@@ -31,6 +32,11 @@ func TestUCS2Decoder(t *testing.T) {
 			in:       []byte{0xfe, 0xff, 0x00, 0x48, 0x00, 0x6F, 0x00, 0x6C, 0x00, 0x65},
 			expected: "Hole",
 		},
+		{
+			decoder:  NewUCS2Decoder(),
+			in:       []byte{0x00, 0x48, 0x00, 0x6F, 0x00, 0x6C, 0x00, 0x65},
+			expected: "Hole",
+		},
 		{
 			decoder:  NewUCS2LEDecoder(),
 			in:       []byte{0x20, 0x22},
@@ -114,3 +120,52 @@ func TestUCS2Encoder(t *testing.T) {
 		}
 	}
 }
+
+func TestUCS2DecoderByteOrder(t *testing.T) {
+	cases := []struct {
+		in       []byte
+		expected binary.ByteOrder
+	}{
+		{
+			// Little-endian by default.
+			in:       []byte{0x20, 0x22},
+			expected: binary.LittleEndian,
+		},
+		{
+			in:       []byte{0xfe, 0xff, 0x20, 0x22},
+			expected: binary.BigEndian,
+		},
+		{
+			// "Все люди"
+			in:       []byte{0x04, 0x12, 0x04, 0x41, 0x04, 0x35, 0x00, 0x20, 0x04, 0x3b, 0x04, 0x4e, 0x04, 0x34, 0x04, 0x38},
+			expected: binary.BigEndian,
+		},
+		{
+			// "すべての人間は"
+			in:       []byte{0x30, 0x59, 0x30, 0x79, 0x30, 0x66, 0x30, 0x6e, 0x4e, 0xba, 0x95, 0x93, 0x30, 0x6f},
+			expected: binary.BigEndian,
+		},
+		{
+			// "すべての人間は"
+			in:       []byte{0x59, 0x30, 0x79, 0x30, 0x66, 0x30, 0x6e, 0x30, 0xba, 0x4e, 0x93, 0x95, 0x6f, 0x30},
+			expected: binary.LittleEndian,
+		},
+	}
+
+	for _, c := range cases {
+		d := NewUCS2Decoder().(*UCS2Decoder)
+		if d.ByteOrder() != nil {
+			t.Errorf("got %v before decoding, want nil", d.ByteOrder())
+		}
+
+		_, err := d.Decode(bytes.NewReader(c.in))
+		if err != nil {
+			t.Errorf("decode error: %v", err)
+			continue
+		}
+
+		if d.ByteOrder() != c.expected {
+			t.Errorf("% x: got %v, want %v", c.in, d.ByteOrder(), c.expected)
+		}
+	}
+}
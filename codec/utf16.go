@@ -1,138 +1,358 @@
 package codec
 
 import (
+	"encoding/binary"
 	"errors"
 	"io"
 )
 
 func init() {
-	registerCodec("UTF-16", NewUTF16Decoder, NewUTF16Encoder)
-	registerCodec("UTF-16BE", NewUTF16BEDecoder, NewUTF16BEEncoder)
-	registerCodec("UTF-16LE", NewUTF16LEDecoder, NewUTF16LEEncoder)
+	registerCodec(Codec{
+		Name: "UTF-16",
+		Aliases: []string{
+			// IANA
+			"csUTF16",
+		},
+		NewDecoder: NewUTF16Decoder,
+		NewEncoder: NewUTF16Encoder,
+		Info:       Info{MaxRune: 0x10ffff, MinBytes: 2, MaxBytes: 4, ReadsBOM: true},
+	})
+	registerCodec(Codec{
+		Name: "UTF-16BE",
+		Aliases: []string{
+			// IANA
+			"csUTF16BE",
+			// WHATWG and .NET
+			"unicodeFFFE",
+			// Java
+			"UnicodeBigUnmarked",
+			// Windows code page
+			"cp1201",
+		},
+		NewDecoder: NewUTF16BEDecoder,
+		NewEncoder: NewUTF16BEEncoder,
+		Info:       Info{MaxRune: 0x10ffff, MinBytes: 2, MaxBytes: 4, ReadsBOM: true, WritesBOM: true},
+	})
+	registerCodec(Codec{
+		Name: "UTF-16LE",
+		Aliases: []string{
+			// IANA
+			"csUTF16LE",
+			// WHATWG
+			"unicode", "unicodeFEFF",
+			// Java
+			"UnicodeLittleUnmarked",
+			// Windows code page
+			"cp1200",
+		},
+		NewDecoder: NewUTF16LEDecoder,
+		NewEncoder: NewUTF16LEEncoder,
+		Info:       Info{MaxRune: 0x10ffff, MinBytes: 2, MaxBytes: 4, ReadsBOM: true, WritesBOM: true},
+	})
 }
 
+var _ BulkDecoder = &UTF16Decoder{}
+
 // UTF16Decoder reads UTF-16 characters. UTF-16 is identical to UCS-2 for
 // characters U+FFFF and below. Characters above U+FFFF are encoded in two
 // 16-bit words, called surrogate pairs.
 type UTF16Decoder struct {
-	ucs2 Decoder
+	name string
+	ucs2 *UCS2Decoder
+
+	// loneSurrogates is set from Options.LoneSurrogates.
+	loneSurrogates bool
 }
 
 // NewUTF16Decoder returns a UTF-16 decoder.
 //
 // If the encoded text begins with a byte order mark (U+FEFF) that will
-// determine the endianness used. Otherwise, it defaults to little-endian.
-func NewUTF16Decoder() Decoder {
+// determine the endianness used. Otherwise, the decoder reads ahead up to 512
+// bytes and guesses the byte order from them. Use ByteOrder to find out which
+// was chosen.
+func NewUTF16Decoder(opts ...Options) Decoder {
+	ucs2 := NewUCS2Decoder(opts...).(*UCS2Decoder)
+	ucs2.loneSurrogates = mergeOptions(opts).LoneSurrogates
 	return &UTF16Decoder{
-		ucs2: NewUCS2Decoder(),
+		name:           "UTF-16",
+		ucs2:           ucs2,
+		loneSurrogates: ucs2.loneSurrogates,
 	}
 }
 
 // NewUTF16LEDecoder returns a UTF-16 decoder with a little-endian byte order.
-func NewUTF16LEDecoder() Decoder {
+//
+// A little-endian byte order mark at the start of the input is removed unless
+// opts say otherwise.
+func NewUTF16LEDecoder(opts ...Options) Decoder {
 	return &UTF16Decoder{
-		ucs2: NewUCS2LEDecoder(),
+		name:           "UTF-16LE",
+		ucs2:           NewUCS2LEDecoder(opts...).(*UCS2Decoder),
+		loneSurrogates: mergeOptions(opts).LoneSurrogates,
 	}
 }
 
 // NewUTF16BEDecoder returns a UTF-16 decoder with a big-endian byte order.
-func NewUTF16BEDecoder() Decoder {
+//
+// A big-endian byte order mark at the start of the input is removed unless
+// opts say otherwise.
+func NewUTF16BEDecoder(opts ...Options) Decoder {
 	return &UTF16Decoder{
-		ucs2: NewUCS2BEDecoder(),
+		name:           "UTF-16BE",
+		ucs2:           NewUCS2BEDecoder(opts...).(*UCS2Decoder),
+		loneSurrogates: mergeOptions(opts).LoneSurrogates,
 	}
 }
 
-const (
-	// Mask the six high bits of a 16 bit number
-	utf16SurrogateMask = 0x3f << 10
-	utf16HighSurrogate = 0xd800
-	utf16LowSurrogate  = 0xdc00
-)
-
 // Decode reads one UTF-16 encoded character from the reader.
 func (d *UTF16Decoder) Decode(r io.Reader) (rune, error) {
-	w1, err := d.ucs2.Decode(r)
+	w1, b1, err := d.ucs2.decodeUnit(r)
 	if err != nil {
+		var de *DecodeError
+		if errors.As(err, &de) {
+			de.Encoding = d.name
+		}
 		return 0, err
 	}
 
-	// The high six bits of UTF-16 surrogate pairs are 0xd800
-	if w1&utf16SurrogateMask != utf16HighSurrogate {
+	switch w1 & utf16SurrogateMask {
+	case utf16HighSurrogate:
+		// The first half of a surrogate pair, keep going.
+	case utf16LowSurrogate:
+		return d.loneSurrogate(w1, b1)
+	default:
 		// Character under 0x10000 that's not a surrogate, just return.
 		return w1, nil
 	}
 
-	w2, err := d.ucs2.Decode(r)
+	w2, b2, err := d.ucs2.decodeUnit(r)
 	if err != nil {
+		if err == io.EOF {
+			if d.loneSurrogates {
+				return w1, nil
+			}
+			return 0, &DecodeError{Encoding: d.name, Bytes: b1, Err: ErrTruncated}
+		}
+
+		var de *DecodeError
+		if errors.As(err, &de) {
+			if d.loneSurrogates {
+				// Leave the odd byte to be reported by the
+				// next call.
+				d.ucs2.unread(de.Bytes)
+				return w1, nil
+			}
+			return 0, &DecodeError{Encoding: d.name, Bytes: append(b1, de.Bytes...), Err: ErrTruncated}
+		}
 		return 0, err
 	}
 
 	if w2&utf16SurrogateMask != utf16LowSurrogate {
-		return 0, errors.New("invalid UTF-16 surrogate pair")
+		// The second word may be a valid character on its own, so
+		// leave it for the next call.
+		d.ucs2.unread(b2)
+		return d.loneSurrogate(w1, b1)
 	}
 
 	u := rune(w1&0x3ff) << 10
 	u |= rune(w2 & 0x3ff)
-	u |= 0x10000
+	u += 0x10000
 
 	return u, nil
 }
 
+// loneSurrogate returns the surrogate w, which was read from b, as a character
+// if lone surrogates are allowed, otherwise as an error.
+func (d *UTF16Decoder) loneSurrogate(w rune, b []byte) (rune, error) {
+	if d.loneSurrogates {
+		return w, nil
+	}
+	return 0, &DecodeError{Encoding: d.name, Bytes: b, Err: ErrLoneSurrogate}
+}
+
+// BulkDecoder satisfies the BulkDecoder interface for UTF-16.
+func (d *UTF16Decoder) BulkDecoder() BulkDecoder {
+	return d
+}
+
+// DecodeBytes satisfies the BulkDecoder interface for UTF-16.
+func (d *UTF16Decoder) DecodeBytes(dst []rune, src []byte, atEOF bool) (nDst, nSrc int, err error) {
+	if !d.ucs2.started {
+		nSrc, err = d.ucs2.startBytes(src, atEOF)
+		if err != nil {
+			return 0, 0, err
+		}
+	}
+
+	for nSrc+2 <= len(src) {
+		if nDst == len(dst) {
+			return nDst, nSrc, ErrShortDst
+		}
+
+		w1 := d.ucs2.unit(src[nSrc:])
+		switch w1 & utf16SurrogateMask {
+		case utf16HighSurrogate:
+			if nSrc+4 > len(src) && !atEOF {
+				return nDst, nSrc, ErrShortSrc
+			}
+
+			var w2 rune
+			if nSrc+4 <= len(src) {
+				w2 = d.ucs2.unit(src[nSrc+2:])
+			}
+			if w2&utf16SurrogateMask == utf16LowSurrogate {
+				dst[nDst] = 0x10000 + (w1&0x3ff)<<10 | w2&0x3ff
+				nSrc += 4
+				break
+			}
+
+			switch {
+			case d.loneSurrogates:
+				dst[nDst] = w1
+				nSrc += 2
+			case nSrc+4 > len(src):
+				return nDst, nSrc, &DecodeError{Encoding: d.name, Bytes: copyBytes(src[nSrc:]), Err: ErrTruncated}
+			default:
+				return nDst, nSrc, &DecodeError{Encoding: d.name, Bytes: copyBytes(src[nSrc : nSrc+2]), Err: ErrLoneSurrogate}
+			}
+		case utf16LowSurrogate:
+			if !d.loneSurrogates {
+				return nDst, nSrc, &DecodeError{Encoding: d.name, Bytes: copyBytes(src[nSrc : nSrc+2]), Err: ErrLoneSurrogate}
+			}
+			dst[nDst] = w1
+			nSrc += 2
+		default:
+			dst[nDst] = w1
+			nSrc += 2
+		}
+		nDst++
+	}
+
+	if nSrc < len(src) {
+		if !atEOF {
+			return nDst, nSrc, ErrShortSrc
+		}
+		return nDst, nSrc, &DecodeError{Encoding: d.name, Bytes: copyBytes(src[nSrc:]), Err: ErrTruncated}
+	}
+	return nDst, nSrc, nil
+}
+
+// Buffered returns the number of bytes that have been read but not decoded.
+func (d *UTF16Decoder) Buffered() int {
+	return d.ucs2.Buffered()
+}
+
+// ByteOrder returns the byte order the decoder is using. For a decoder that
+// reads the byte order from the input it returns nil until the first call to
+// Decode.
+func (d *UTF16Decoder) ByteOrder() binary.ByteOrder {
+	return d.ucs2.ByteOrder()
+}
+
+var _ BulkEncoder = &UTF16Encoder{}
+
 // UTF16Encoder encodes unicode code points using exactly two bytes.
 // It can only encode characters up to U+FFFF.
 type UTF16Encoder struct {
-	ucs2 Encoder
+	name string
+	ucs2 *UCS2Encoder
+
+	// loneSurrogates is set from Options.LoneSurrogates.
+	loneSurrogates bool
 }
 
 // NewUTF16Encoder returns a UCS-2 encoder with a little-endian byte order.
 //
 // This is identical to NewUTF16LEEncoder except this one does not write a byte
-// order mark.
-func NewUTF16Encoder() Encoder {
+// order mark unless opts ask for one.
+func NewUTF16Encoder(opts ...Options) Encoder {
 	return &UTF16Encoder{
-		ucs2: NewUCS2Encoder(),
+		name:           "UTF-16",
+		ucs2:           NewUCS2Encoder(opts...).(*UCS2Encoder),
+		loneSurrogates: mergeOptions(opts).LoneSurrogates,
 	}
 }
 
 // NewUTF16LEEncoder returns a UTF-16 encoder with a little-endian byte order.
 //
-// It will write a byte order mark with the first character.
-func NewUTF16LEEncoder() Encoder {
+// It will write a byte order mark with the first character unless opts say
+// otherwise.
+func NewUTF16LEEncoder(opts ...Options) Encoder {
 	return &UTF16Encoder{
-		ucs2: NewUCS2LEEncoder(),
+		name:           "UTF-16LE",
+		ucs2:           NewUCS2LEEncoder(opts...).(*UCS2Encoder),
+		loneSurrogates: mergeOptions(opts).LoneSurrogates,
 	}
 }
 
 // NewUTF16BEEncoder returns a UTF-16 encoder with a big-endian byte order.
 //
-// It will write a byte order mark with the first character.
-func NewUTF16BEEncoder() Encoder {
+// It will write a byte order mark with the first character unless opts say
+// otherwise.
+func NewUTF16BEEncoder(opts ...Options) Encoder {
 	return &UTF16Encoder{
-		ucs2: NewUCS2BEEncoder(),
+		name:           "UTF-16BE",
+		ucs2:           NewUCS2BEEncoder(opts...).(*UCS2Encoder),
+		loneSurrogates: mergeOptions(opts).LoneSurrogates,
 	}
 }
 
 // Encode writes one UTF-16 encoded character to the writer.
 func (d *UTF16Encoder) Encode(w io.Writer, r rune) error {
-	if r < 0x10000 {
-		return d.ucs2.Encode(w, r)
+	if err := d.checkRune(r); err != nil {
+		return &EncodeError{Encoding: d.name, Rune: r, Err: err}
 	}
 
-	if r > 0x10ffff {
-		return errors.New("character out of range")
+	buf := make([]byte, 6)
+	n := d.encodeChar(buf, r)
+	_, err := w.Write(buf[:n])
+	return err
+}
+
+// BulkEncoder satisfies the BulkEncoder interface for UTF-16.
+func (d *UTF16Encoder) BulkEncoder() BulkEncoder {
+	return d
+}
+
+// EncodeRunes satisfies the BulkEncoder interface for UTF-16.
+func (d *UTF16Encoder) EncodeRunes(dst []byte, src []rune) (nDst, nSrc int, err error) {
+	for ; nSrc < len(src); nSrc++ {
+		r := src[nSrc]
+		if err := d.checkRune(r); err != nil {
+			return nDst, nSrc, &EncodeError{Encoding: d.name, Rune: r, Err: err}
+		}
+		if len(dst)-nDst < 6 {
+			return nDst, nSrc, ErrShortDst
+		}
+		nDst += d.encodeChar(dst[nDst:], r)
+	}
+	return nDst, nSrc, nil
+}
+
+// checkRune works like the checkRune function, except that it allows lone
+// surrogates if the encoder was created with Options.LoneSurrogates.
+func (d *UTF16Encoder) checkRune(r rune) error {
+	err := checkRune(r)
+	if err == ErrLoneSurrogate && d.loneSurrogates {
+		return nil
+	}
+	return err
+}
+
+// encodeChar writes r to buf and returns the number of bytes written. buf must
+// have room for 6 bytes, a byte order mark and a surrogate pair.
+func (d *UTF16Encoder) encodeChar(buf []byte, r rune) int {
+	if r < 0x10000 {
+		return d.ucs2.encodeUnit(buf, r)
 	}
 
 	// Split the character into two words. Subtract 0x10000, the largest
 	// possible code point will be 20 bits. The first ten bits go in the
 	// first word, the second ten bits go in the second word.
-	r ^= 0x10000
+	r -= 0x10000
 	r1 := utf16HighSurrogate | (r >> 10)
 	r2 := utf16LowSurrogate | (r & 0x3ff)
 
-	err := d.ucs2.Encode(w, r1)
-	if err != nil {
-		return err
-	}
-
-	return d.ucs2.Encode(w, r2)
+	n := d.ucs2.encodeUnit(buf, r1)
+	return n + d.ucs2.encodeUnit(buf[n:], r2)
 }
@@ -31,6 +31,17 @@ func TestUTF16Decoder(t *testing.T) {
 			in:       []byte{0xfe, 0xff, 0x00, 0x48, 0x00, 0x6F, 0x00, 0x6C, 0x00, 0x65},
 			expected: "Hole",
 		},
+		{
+			decoder:  NewUTF16Decoder(),
+			in:       []byte{0x00, 0x48, 0x00, 0x6F, 0x00, 0x6C, 0x00, 0x65},
+			expected: "Hole",
+		},
+		{
+			// Only valid as big-endian.
+			decoder:  NewUTF16Decoder(),
+			in:       []byte{0xd8, 0x3d, 0xdc, 0x07, 0xd8, 0x3d, 0xdc, 0x07},
+			expected: "🐇🐇",
+		},
 		{
 			decoder:  NewUTF16LEDecoder(),
 			in:       []byte{0x20, 0x22},
@@ -51,6 +62,11 @@ func TestUTF16Decoder(t *testing.T) {
 			in:       []byte{0xd8, 0x3d, 0xdc, 0x07},
 			expected: "🐇",
 		},
+		{
+			decoder:  NewUTF16BEDecoder(),
+			in:       []byte{0xd8, 0x40, 0xdc, 0x00, 0xdb, 0xff, 0xdf, 0xfd},
+			expected: "\U00020000\U0010fffd",
+		},
 	}
 
 	encoder := NewUTF8Encoder()
@@ -116,6 +132,11 @@ func TestUTF16Encoder(t *testing.T) {
 			in:       "🐇",
 			expected: []byte{0xfe, 0xff, 0xd8, 0x3d, 0xdc, 0x07},
 		},
+		{
+			encoder:  NewUTF16BEEncoder(),
+			in:       "\U00020000\U0010fffd",
+			expected: []byte{0xfe, 0xff, 0xd8, 0x40, 0xdc, 0x00, 0xdb, 0xff, 0xdf, 0xfd},
+		},
 	}
 
 	decoder := NewUTF8Decoder()
@@ -134,3 +155,85 @@ func TestUTF16Encoder(t *testing.T) {
 		}
 	}
 }
+
+// TestUTF16Supplementary checks characters past plane 1 one at a time, since
+// Recode uses DecodeBytes and EncodeRunes.
+func TestUTF16Supplementary(t *testing.T) {
+	chars := []rune{0x10000, 0x1f407, 0x20000, 0x10fffd}
+	encoded := []byte{
+		0xd8, 0x00, 0xdc, 0x00,
+		0xd8, 0x3d, 0xdc, 0x07,
+		0xd8, 0x40, 0xdc, 0x00,
+		0xdb, 0xff, 0xdf, 0xfd,
+	}
+
+	decoder := NewUTF16BEDecoder()
+	r := bytes.NewReader(encoded)
+	for _, expected := range chars {
+		actual, err := decoder.Decode(r)
+		if err != nil {
+			t.Fatalf("decode error: %v", err)
+		}
+		if actual != expected {
+			t.Errorf("decoded %U, want %U", actual, expected)
+		}
+	}
+
+	encoder := NewUTF16BEEncoder(Options{BOM: BOMNever})
+	actual := &bytes.Buffer{}
+	for _, char := range chars {
+		err := encoder.Encode(actual, char)
+		if err != nil {
+			t.Fatalf("encode error: %v", err)
+		}
+	}
+	if !bytes.Equal(actual.Bytes(), encoded) {
+		t.Errorf("encoded % x, want % x", actual.Bytes(), encoded)
+	}
+}
+
+func TestUTF16DecoderInvalid(t *testing.T) {
+	cases := []struct {
+		decoder  Decoder
+		in       []byte
+		expected string
+	}{
+		{
+			// High surrogate followed by a regular character
+			decoder:  NewUTF16BEDecoder(),
+			in:       []byte{0xd8, 0x3d, 0x00, 0x48},
+			expected: "�H",
+		},
+		{
+			// Low surrogate on its own
+			decoder:  NewUTF16LEDecoder(),
+			in:       []byte{0x07, 0xdc, 0x48, 0x00},
+			expected: "�H",
+		},
+		{
+			// High surrogate followed by a complete pair
+			decoder:  NewUTF16LEDecoder(),
+			in:       []byte{0x3d, 0xd8, 0x3d, 0xd8, 0x07, 0xdc},
+			expected: "�🐇",
+		},
+		{
+			// Odd number of bytes
+			decoder:  NewUTF16LEDecoder(),
+			in:       []byte{0x48, 0x00, 0x69},
+			expected: "H�",
+		},
+	}
+
+	for _, c := range cases {
+		actual := &bytes.Buffer{}
+		err := Recode(bytes.NewReader(c.in), actual, c.decoder, NewUTF8Encoder(), WithErrorPolicy(Replace))
+		if err != nil {
+			t.Errorf("recode error: %v", err)
+			continue
+		}
+
+		if actual.String() != c.expected {
+			t.Errorf("got %q, want %q", actual.String(), c.expected)
+		}
+	}
+}
@@ -6,19 +6,54 @@ import (
 )
 
 func init() {
-	registerCodec("UTF-32", NewUTF32Decoder, NewUTF32Encoder)
-	registerCodec("UTF-32BE", NewUTF32BEDecoder, NewUTF32BEEncoder)
-	registerCodec("UTF-32LE", NewUTF32LEDecoder, NewUTF32LEEncoder)
-
-	registerCodec("UCS-4", NewUTF32Decoder, NewUTF32Encoder)
-	registerCodec("UCS-4BE", NewUTF32BEDecoder, NewUTF32BEEncoder)
-	registerCodec("UCS-4LE", NewUTF32LEDecoder, NewUTF32LEEncoder)
+	registerCodec(Codec{
+		Name: "UTF-32",
+		Aliases: []string{
+			// IANA
+			"csUTF32", "ISO-10646-UCS-4", "csUCS4",
+			"UCS-4",
+		},
+		NewDecoder: NewUTF32Decoder,
+		NewEncoder: NewUTF32Encoder,
+		Info:       Info{MaxRune: 0x10ffff, MinBytes: 4, MaxBytes: 4, ReadsBOM: true},
+	})
+	registerCodec(Codec{
+		Name: "UTF-32BE",
+		Aliases: []string{
+			// IANA
+			"csUTF32BE",
+			"UCS-4BE",
+			// Windows code page
+			"cp12001",
+		},
+		NewDecoder: NewUTF32BEDecoder,
+		NewEncoder: NewUTF32BEEncoder,
+		Info:       Info{MaxRune: 0x10ffff, MinBytes: 4, MaxBytes: 4, ReadsBOM: true},
+	})
+	registerCodec(Codec{
+		Name: "UTF-32LE",
+		Aliases: []string{
+			// IANA
+			"csUTF32LE",
+			"UCS-4LE",
+			// Windows code page
+			"cp12000",
+		},
+		NewDecoder: NewUTF32LEDecoder,
+		NewEncoder: NewUTF32LEEncoder,
+		Info:       Info{MaxRune: 0x10ffff, MinBytes: 4, MaxBytes: 4, ReadsBOM: true},
+	})
 }
 
+var _ BulkDecoder = &UTF32Decoder{}
+
 // UTF32Decoder reads UTF-32 encoded Unicode characters. UTF-32 is a character
 // encoding where each code point takes exactly 4 bytes.
 type UTF32Decoder struct {
+	name      string
 	byteOrder byteOrder
+	bom       BOMPolicy
+	started   bool
 }
 
 // NewUTF32Decoder returns a UTF-32 decoder.
@@ -26,46 +61,82 @@ type UTF32Decoder struct {
 // If the encoded text begins with a byte order mark (U+FEFF) that will
 // determine the endianness used. Otherwise it will derive the byte order by
 // looking for the 0-byte in the first code point.
-func NewUTF32Decoder() Decoder {
-	return &UTF32Decoder{}
+func NewUTF32Decoder(opts ...Options) Decoder {
+	return &UTF32Decoder{
+		name: "UTF-32",
+		bom:  mergeOptions(opts).BOM,
+	}
 }
 
 // NewUTF32LEDecoder returns a UTF-32 decoder with a little-endian byte order.
-func NewUTF32LEDecoder() Decoder {
+//
+// A little-endian byte order mark at the start of the input is removed unless
+// opts say otherwise.
+func NewUTF32LEDecoder(opts ...Options) Decoder {
 	return &UTF32Decoder{
+		name:      "UTF-32LE",
 		byteOrder: littleEndian,
+		bom:       mergeOptions(opts).BOM,
 	}
 }
 
 // NewUTF32BEDecoder returns a UTF-32 decoder with a big-endian byte order.
-func NewUTF32BEDecoder() Decoder {
+//
+// A big-endian byte order mark at the start of the input is removed unless
+// opts say otherwise.
+func NewUTF32BEDecoder(opts ...Options) Decoder {
 	return &UTF32Decoder{
+		name:      "UTF-32BE",
 		byteOrder: bigEndian,
+		bom:       mergeOptions(opts).BOM,
 	}
 }
 
 // Decode satifies the Decoder interface for UTF-32.
 func (d *UTF32Decoder) Decode(r io.Reader) (rune, error) {
-	var buf = make([]byte, 4)
-	_, err := io.ReadFull(r, buf)
+	buf, err := d.readChar(r)
 	if err != nil {
 		return 0, err
 	}
 
+	if !d.started && d.start(buf) {
+		buf, err = d.readChar(r)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return d.decodeChar(buf)
+}
+
+// start handles a byte order mark in buf, the first four bytes of the input.
+// It reports whether buf should be skipped.
+func (d *UTF32Decoder) start(buf []byte) bool {
+	d.started = true
+
+	order := unknownByteOrder
+	if d.bom != BOMNever {
+		if buf[0] == 0 && buf[1] == 0 && buf[2] == 0xfe && buf[3] == 0xff {
+			order = bigEndian
+		} else if buf[0] == 0xff && buf[1] == 0xfe && buf[2] == 0 && buf[3] == 0 {
+			order = littleEndian
+		}
+	}
+
+	if d.byteOrder == unknownByteOrder {
+		d.byteOrder = order
+	}
+	return order != unknownByteOrder && order == d.byteOrder && d.bom != BOMPreserve
+}
+
+// decodeChar decodes the four bytes in buf.
+func (d *UTF32Decoder) decodeChar(buf []byte) (rune, error) {
 	if d.byteOrder == unknownByteOrder {
-		// Check for a BE or LE byte order mark.
-		//
 		// UTF-32 sometimes doesn't need a BOM, because the most
 		// significant byte is always zero (max assigned Unicode code
 		// point is 0x10ffff which never takes four bytes), so check
 		// which end is zero if there's no BOM.
-		if buf[0] == 0xfe && buf[1] == 0xff && buf[2] == 0 && buf[3] == 0 {
-			d.byteOrder = bigEndian
-			_, err = io.ReadFull(r, buf)
-		} else if buf[0] == 0xff && buf[1] == 0xfe && buf[2] == 0 && buf[3] == 0 {
-			d.byteOrder = littleEndian
-			_, err = io.ReadFull(r, buf)
-		} else if buf[0] == 0 && buf[3] == 0 {
+		if buf[0] == 0 && buf[3] == 0 {
 			// Checking for a zero byte doesn't work when _both_
 			// end bytes are zero. Output a replacement character,
 			// and we'll probably get it right on the next code
@@ -76,56 +147,158 @@ func (d *UTF32Decoder) Decode(r io.Reader) (rune, error) {
 		} else if buf[0] == 0 {
 			d.byteOrder = bigEndian
 		} else {
-			return 0, errors.New("invalid UTF-32 character")
-		}
-
-		if err != nil {
-			return 0, err
+			return 0, &DecodeError{Encoding: d.name, Bytes: copyBytes(buf), Err: ErrOutOfRange}
 		}
 	}
 
+	var char rune
 	switch d.byteOrder {
 	case bigEndian:
-		return (rune(buf[0]) << 24) | (rune(buf[1]) << 16) | (rune(buf[2]) << 8) | (rune(buf[3])), nil
+		char = (rune(buf[0]) << 24) | (rune(buf[1]) << 16) | (rune(buf[2]) << 8) | (rune(buf[3]))
 	case littleEndian:
-		return (rune(buf[3]) << 24) | (rune(buf[2]) << 16) | (rune(buf[1]) << 8) | (rune(buf[0])), nil
+		char = (rune(buf[3]) << 24) | (rune(buf[2]) << 16) | (rune(buf[1]) << 8) | (rune(buf[0]))
 	default:
 		return 0, errors.New("unknown byte order")
 	}
+
+	if err := checkRune(char); err != nil {
+		return 0, &DecodeError{Encoding: d.name, Bytes: copyBytes(buf), Err: err}
+	}
+	return char, nil
+}
+
+// BulkDecoder satisfies the BulkDecoder interface for UTF-32.
+func (d *UTF32Decoder) BulkDecoder() BulkDecoder {
+	return d
 }
 
+// DecodeBytes satisfies the BulkDecoder interface for UTF-32.
+func (d *UTF32Decoder) DecodeBytes(dst []rune, src []byte, atEOF bool) (nDst, nSrc int, err error) {
+	if !d.started && len(src) >= 4 && d.start(src) {
+		nSrc = 4
+	}
+
+	for ; nSrc+4 <= len(src); nSrc += 4 {
+		if nDst == len(dst) {
+			return nDst, nSrc, ErrShortDst
+		}
+
+		char, err := d.decodeChar(src[nSrc : nSrc+4])
+		if err != nil {
+			return nDst, nSrc, err
+		}
+		dst[nDst] = char
+		nDst++
+	}
+
+	if nSrc < len(src) {
+		if !atEOF {
+			return nDst, nSrc, ErrShortSrc
+		}
+		return nDst, nSrc, &DecodeError{Encoding: d.name, Bytes: copyBytes(src[nSrc:]), Err: ErrTruncated}
+	}
+	return nDst, nSrc, nil
+}
+
+// readChar reads the four bytes of a character.
+func (d *UTF32Decoder) readChar(r io.Reader) ([]byte, error) {
+	var buf = make([]byte, 4)
+	n, err := io.ReadFull(r, buf)
+	if err == io.ErrUnexpectedEOF {
+		return nil, &DecodeError{Encoding: d.name, Bytes: buf[:n], Err: ErrTruncated}
+	}
+	return buf, err
+}
+
+var _ BulkEncoder = &UTF32Encoder{}
+
 // UTF32Encoder encodes unicode code points using exactly four bytes.
 type UTF32Encoder struct {
+	name      string
 	byteOrder byteOrder
+	bom       bomEncoder
 }
 
 // NewUTF32Encoder returns a UTF-32 encoder with a little-endian byte order.
 //
 // This is identical to NewUTF32LEEncoder.
-func NewUTF32Encoder() Encoder {
+func NewUTF32Encoder(opts ...Options) Encoder {
 	return &UTF32Encoder{
+		name:      "UTF-32",
 		byteOrder: littleEndian,
+		bom:       newBOMEncoder(mergeOptions(opts).BOM, false),
 	}
 }
 
 // NewUTF32LEEncoder returns a UTF-32 encoder with a little-endian byte order.
-func NewUTF32LEEncoder() Encoder {
+//
+// It doesn't write a byte order mark unless opts ask for one.
+func NewUTF32LEEncoder(opts ...Options) Encoder {
 	return &UTF32Encoder{
+		name:      "UTF-32LE",
 		byteOrder: littleEndian,
+		bom:       newBOMEncoder(mergeOptions(opts).BOM, false),
 	}
 }
 
 // NewUTF32BEEncoder returns a UTF-32 encoder with a big-endian byte order.
-func NewUTF32BEEncoder() Encoder {
+//
+// It doesn't write a byte order mark unless opts ask for one.
+func NewUTF32BEEncoder(opts ...Options) Encoder {
 	return &UTF32Encoder{
+		name:      "UTF-32BE",
 		byteOrder: bigEndian,
+		bom:       newBOMEncoder(mergeOptions(opts).BOM, false),
 	}
 }
 
 // Encode satifies the Encoder interface for UTF-32.
 func (d *UTF32Encoder) Encode(w io.Writer, r rune) error {
-	buf := make([]byte, 4)
+	if err := checkRune(r); err != nil {
+		return &EncodeError{Encoding: d.name, Rune: r, Err: err}
+	}
+
+	buf := make([]byte, 8)
+	n := d.encodeChar(buf, r)
+	_, err := w.Write(buf[:n])
+	return err
+}
 
+// BulkEncoder satisfies the BulkEncoder interface for UTF-32.
+func (d *UTF32Encoder) BulkEncoder() BulkEncoder {
+	return d
+}
+
+// EncodeRunes satisfies the BulkEncoder interface for UTF-32.
+func (d *UTF32Encoder) EncodeRunes(dst []byte, src []rune) (nDst, nSrc int, err error) {
+	for ; nSrc < len(src); nSrc++ {
+		r := src[nSrc]
+		if err := checkRune(r); err != nil {
+			return nDst, nSrc, &EncodeError{Encoding: d.name, Rune: r, Err: err}
+		}
+		if len(dst)-nDst < 8 {
+			return nDst, nSrc, ErrShortDst
+		}
+		nDst += d.encodeChar(dst[nDst:], r)
+	}
+	return nDst, nSrc, nil
+}
+
+// encodeChar writes r to buf, preceded by a byte order mark if one's needed,
+// and returns the number of bytes written. buf must have room for 8 bytes.
+func (d *UTF32Encoder) encodeChar(buf []byte, r rune) int {
+	var n int
+	writeBOM, skip := d.bom.start(r)
+	if writeBOM {
+		n += d.put(buf, bomRune)
+	}
+	if skip {
+		return n
+	}
+	return n + d.put(buf[n:], r)
+}
+
+func (d *UTF32Encoder) put(buf []byte, r rune) int {
 	if d.byteOrder == bigEndian {
 		buf[0] = byte(r >> 24)
 		buf[1] = byte(r >> 16)
@@ -137,6 +310,5 @@ func (d *UTF32Encoder) Encode(w io.Writer, r rune) error {
 		buf[2] = byte(r >> 16)
 		buf[3] = byte(r >> 24)
 	}
-	_, err := w.Write(buf)
-	return err
+	return 4
 }
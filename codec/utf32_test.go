@@ -53,6 +53,15 @@ func TestUTF32Decoder(t *testing.T) {
 			},
 			expected: "Hole",
 		},
+		{
+			decoder: NewUTF32Decoder(),
+			in: []byte{
+				0x00, 0x00, 0xfe, 0xff,
+				0x00, 0x00, 0x00, 0x48,
+				0x00, 0x00, 0x00, 0x6F,
+			},
+			expected: "Ho",
+		},
 		{
 			decoder: NewUTF32LEDecoder(),
 			in: []byte{
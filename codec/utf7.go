@@ -0,0 +1,395 @@
+package codec
+
+import (
+	"io"
+	"strings"
+)
+
+// UTF-7 (RFC 2152) encodes Unicode in 7-bit ASCII for email. Most ASCII
+// characters stand for themselves, and everything else is written in UTF-16,
+// encoded in base64 between a '+' and a '-'. "+-" is a plain '+'. The '-' can
+// be left out when the next character isn't a base64 digit or '-', and the
+// decoder accepts that, but the encoder always writes it at the end of the
+// text, so it needs to be flushed.
+//
+// UTF-7-IMAP is the variant used for IMAP mailbox names (RFC 3501). It starts
+// base64 with '&' rather than '+', uses ',' in place of '/', and writes
+// printable ASCII, and nothing else, as itself. "&-" is a plain '&', and the
+// encoder ends every base64 run with '-'.
+//
+// Both decoders ignore the bits left over at the end of a base64 run, even if
+// they aren't zero.
+func init() {
+	registerCodec(Codec{
+		Name: "UTF-7",
+		Aliases: []string{
+			// IANA
+			"csUTF7",
+			// .NET
+			"unicode-1-1-utf-7", "unicode-2-0-utf-7", "x-unicode-1-1-utf-7", "x-unicode-2-0-utf-7",
+			// Windows code page
+			"cp65000",
+		},
+		NewDecoder: func(...Options) Decoder {
+			return &UTF7Decoder{utf7: utf7Std}
+		},
+		NewEncoder: func(...Options) Encoder {
+			return &UTF7Encoder{utf7: utf7Std}
+		},
+		Info: Info{MaxRune: 0x10ffff, MinBytes: 1, MaxBytes: utf7MaxBytes},
+	})
+	registerCodec(Codec{
+		Name: "UTF-7-IMAP",
+		Aliases: []string{
+			// IANA
+			"csUTF7IMAP",
+			"IMAP-UTF-7", "modified-UTF-7",
+		},
+		NewDecoder: func(...Options) Decoder {
+			return &UTF7Decoder{utf7: utf7IMAP}
+		},
+		NewEncoder: func(...Options) Encoder {
+			return &UTF7Encoder{utf7: utf7IMAP}
+		},
+		Info: Info{MaxRune: 0x10ffff, MinBytes: 1, MaxBytes: utf7MaxBytes},
+	})
+}
+
+// utf7 is a variant of UTF-7.
+type utf7 struct {
+	name string
+
+	// shift starts a base64 run.
+	shift byte
+
+	// alphabet holds the 64 base64 digits.
+	alphabet string
+
+	// imap is set for the IMAP variant, which has stricter rules for
+	// what's written as ASCII and always ends a base64 run with '-'.
+	imap bool
+}
+
+var (
+	utf7Std = &utf7{
+		name:     "UTF-7",
+		shift:    '+',
+		alphabet: "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/",
+	}
+	utf7IMAP = &utf7{
+		name:     "UTF-7-IMAP",
+		shift:    '&',
+		alphabet: "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+,",
+		imap:     true,
+	}
+)
+
+// utf7MaxBytes is the most a UTF7Encoder writes for a character: the shift
+// and six base64 digits for a surrogate pair.
+const utf7MaxBytes = 7
+
+// digit returns the value of the base64 digit b, or -1 if b isn't one.
+func (u *utf7) digit(b byte) int {
+	return strings.IndexByte(u.alphabet, b)
+}
+
+// direct reports whether the encoder writes r as itself.
+func (u *utf7) direct(r rune) bool {
+	if u.imap {
+		return r >= 0x20 && r < 0x7f
+	}
+	// Everything but '\' and '~', which RFC 2152 leaves out because they
+	// aren't the same in every national variant of ASCII.
+	switch {
+	case r == '\t' || r == '\n' || r == '\r':
+		return true
+	case r < 0x20 || r >= 0x7f:
+		return false
+	default:
+		return r != '\\' && r != '~'
+	}
+}
+
+var _ BulkDecoder = &UTF7Decoder{}
+
+// UTF7Decoder reads UTF-7 or UTF-7-IMAP. Use GetDecoder to get one.
+type UTF7Decoder struct {
+	utf7 *utf7
+	in   input
+
+	// base64 is true inside a base64 run. bits holds the nbits bits
+	// left over from the last base64 digit used, which belong to the next
+	// character.
+	base64 bool
+	bits   uint32
+	nbits  uint
+}
+
+// Decode satisfies the Decoder interface for UTF-7.
+func (d *UTF7Decoder) Decode(r io.Reader) (rune, error) {
+	return d.in.decodeRune(r, d.utf7.name, d.decode)
+}
+
+// BulkDecoder satisfies the BulkDecoder interface for UTF-7.
+func (d *UTF7Decoder) BulkDecoder() BulkDecoder {
+	return d
+}
+
+// DecodeBytes satisfies the BulkDecoder interface for UTF-7.
+func (d *UTF7Decoder) DecodeBytes(dst []rune, src []byte, atEOF bool) (nDst, nSrc int, err error) {
+	return decodeBytes(dst, src, atEOF, d.utf7.name, d.decode)
+}
+
+// Buffered returns the number of bytes that have been read but not decoded.
+func (d *UTF7Decoder) Buffered() int {
+	return d.in.Buffered()
+}
+
+// decode is the decodeFunc for UTF-7.
+func (d *UTF7Decoder) decode(src []byte, atEOF bool) (rune, int, error) {
+	if d.base64 {
+		return d.decodeBase64(src, atEOF)
+	}
+
+	b := src[0]
+	switch {
+	case b == d.utf7.shift:
+	case b >= 0x80:
+		return 0, 1, ErrInvalidSequence
+	case d.utf7.imap && (b < 0x20 || b == 0x7f):
+		return 0, 1, ErrInvalidSequence
+	default:
+		return rune(b), 1, nil
+	}
+
+	if len(src) < 2 {
+		if !atEOF {
+			return 0, 0, ErrShortSrc
+		}
+		return 0, 1, ErrTruncated
+	}
+	switch {
+	case src[1] == '-':
+		return rune(b), 2, nil
+	case d.utf7.digit(src[1]) < 0:
+		return 0, 1, ErrInvalidSequence
+	}
+
+	d.base64 = true
+	d.bits, d.nbits = 0, 0
+	return noRune, 1, nil
+}
+
+// decodeBase64 decodes a character from a base64 run. A character ends part
+// way through a digit, so the state is only updated when one is complete.
+func (d *UTF7Decoder) decodeBase64(src []byte, atEOF bool) (rune, int, error) {
+	bits, nbits := d.bits, d.nbits
+
+	// When a high surrogate is found the next unit is needed too. If it's
+	// not a low surrogate the high surrogate is an error on its own, and
+	// decoding picks up from where it ended.
+	var high rune
+	var highN int
+	var highBits uint32
+	var highNBits uint
+	loneHigh := func() (rune, int, error) {
+		d.bits, d.nbits = highBits, highNBits
+		return 0, highN, ErrLoneSurrogate
+	}
+
+	for i, b := range src {
+		v := d.utf7.digit(b)
+		if v < 0 {
+			switch {
+			case high != 0:
+				return loneHigh()
+			case nbits >= 6:
+				// There's at least one digit since the last
+				// character, but not enough for another.
+				d.bits, d.nbits = 0, 0
+				return 0, i, ErrTruncated
+			}
+
+			d.base64 = false
+			d.bits, d.nbits = 0, 0
+			if b == '-' {
+				return noRune, i + 1, nil
+			}
+			return d.decode(src[i:], atEOF)
+		}
+
+		bits = bits<<6 | uint32(v)
+		nbits += 6
+		if nbits < 16 {
+			continue
+		}
+
+		nbits -= 16
+		unit := rune(bits >> nbits)
+		bits &= 1<<nbits - 1
+
+		switch unit & utf16SurrogateMask {
+		case utf16HighSurrogate:
+			if high != 0 {
+				return loneHigh()
+			}
+			high, highN, highBits, highNBits = unit, i+1, bits, nbits
+			continue
+		case utf16LowSurrogate:
+			d.bits, d.nbits = bits, nbits
+			if high == 0 {
+				return 0, i + 1, ErrLoneSurrogate
+			}
+			return 0x10000 + (high&0x3ff)<<10 | unit&0x3ff, i + 1, nil
+		}
+
+		if high != 0 {
+			return loneHigh()
+		}
+		d.bits, d.nbits = bits, nbits
+		return unit, i + 1, nil
+	}
+
+	switch {
+	case !atEOF:
+		return 0, 0, ErrShortSrc
+	case high != 0:
+		return loneHigh()
+	default:
+		d.base64 = false
+		d.bits, d.nbits = 0, 0
+		return 0, len(src), ErrTruncated
+	}
+}
+
+var (
+	_ BulkEncoder = &UTF7Encoder{}
+	_ Flusher     = &UTF7Encoder{}
+)
+
+// UTF7Encoder writes UTF-7 or UTF-7-IMAP. Use GetEncoder to get one.
+//
+// Base64 runs are left open until a character is written as ASCII, so Flush
+// must be called at the end of the text to close the last one.
+type UTF7Encoder struct {
+	utf7 *utf7
+
+	// base64 is true inside a base64 run. bits holds the nbits bits that
+	// haven't made up a whole digit yet.
+	base64 bool
+	bits   uint32
+	nbits  uint
+}
+
+// Encode satisfies the Encoder interface for UTF-7.
+func (e *UTF7Encoder) Encode(w io.Writer, r rune) error {
+	buf := make([]byte, utf7MaxBytes)
+	n, err := e.encode(buf, r)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(buf[:n])
+	return err
+}
+
+// BulkEncoder satisfies the BulkEncoder interface for UTF-7.
+func (e *UTF7Encoder) BulkEncoder() BulkEncoder {
+	return e
+}
+
+// EncodeRunes satisfies the BulkEncoder interface for UTF-7.
+func (e *UTF7Encoder) EncodeRunes(dst []byte, src []rune) (nDst, nSrc int, err error) {
+	for ; nSrc < len(src); nSrc++ {
+		if len(dst)-nDst < utf7MaxBytes {
+			return nDst, nSrc, ErrShortDst
+		}
+		n, err := e.encode(dst[nDst:], src[nSrc])
+		if err != nil {
+			return nDst, nSrc, err
+		}
+		nDst += n
+	}
+	return nDst, nSrc, nil
+}
+
+// Flush closes a base64 run that's still open. It satisfies the Flusher
+// interface.
+func (e *UTF7Encoder) Flush(w io.Writer) error {
+	if !e.base64 {
+		return nil
+	}
+
+	buf := make([]byte, 2)
+	n := e.close(buf)
+	buf[n] = '-'
+	_, err := w.Write(buf[:n+1])
+	return err
+}
+
+// close ends a base64 run, writing the last digit if there is one to buf, and
+// returns the number of bytes written.
+func (e *UTF7Encoder) close(buf []byte) int {
+	n := 0
+	if e.nbits > 0 {
+		buf[0] = e.utf7.alphabet[e.bits<<(6-e.nbits)&0x3f]
+		n = 1
+	}
+	e.base64 = false
+	e.bits, e.nbits = 0, 0
+	return n
+}
+
+// encode writes r to buf, which must have room for utf7MaxBytes, and returns
+// the number of bytes written. If r can't be encoded it returns an error
+// without changing state.
+func (e *UTF7Encoder) encode(buf []byte, r rune) (int, error) {
+	if err := checkRune(r); err != nil {
+		return 0, &EncodeError{Encoding: e.utf7.name, Rune: r, Err: err}
+	}
+
+	n := 0
+	if e.utf7.direct(r) {
+		b := byte(r)
+		if e.base64 {
+			n = e.close(buf)
+			if e.utf7.imap || b == '-' || e.utf7.digit(b) >= 0 {
+				buf[n] = '-'
+				n++
+			}
+		}
+
+		buf[n] = b
+		n++
+		if b == e.utf7.shift {
+			buf[n] = '-'
+			n++
+		}
+		return n, nil
+	}
+
+	if !e.base64 {
+		buf[0] = e.utf7.shift
+		n = 1
+		e.base64 = true
+	}
+
+	units := [2]rune{r}
+	count := 1
+	if r >= 0x10000 {
+		r -= 0x10000
+		units = [2]rune{utf16HighSurrogate | r>>10, utf16LowSurrogate | r&0x3ff}
+		count = 2
+	}
+	for _, unit := range units[:count] {
+		e.bits = e.bits<<16 | uint32(unit)
+		e.nbits += 16
+		for e.nbits >= 6 {
+			e.nbits -= 6
+			buf[n] = e.utf7.alphabet[e.bits>>e.nbits&0x3f]
+			n++
+		}
+		e.bits &= 1<<e.nbits - 1
+	}
+	return n, nil
+}
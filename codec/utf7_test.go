@@ -0,0 +1,198 @@
+package codec
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestUTF7(t *testing.T) {
+	cases := []struct {
+		encoding string
+		text     string
+		encoded  string
+	}{
+		{
+			encoding: "UTF-7",
+			text:     "Hi Mom -☺-!",
+			encoded:  "Hi Mom -+Jjo--!",
+		},
+		{
+			// The '-' is left out before a character that isn't a
+			// base64 digit
+			encoding: "UTF-7",
+			text:     "A≢Α.",
+			encoded:  "A+ImIDkQ.",
+		},
+		{
+			encoding: "UTF-7",
+			text:     "日本語",
+			encoded:  "+ZeVnLIqe-",
+		},
+		{
+			encoding: "UTF-7",
+			text:     "1 + 1 = 2",
+			encoded:  "1 +- 1 = 2",
+		},
+		{
+			encoding: "UTF-7",
+			text:     "a~b\\\t\r\n",
+			encoded:  "a+AH4-b+AFw\t\r\n",
+		},
+		{
+			encoding: "UTF-7",
+			text:     "😀x€1",
+			encoded:  "+2D3eAA-x+IKw-1",
+		},
+		{
+			encoding: "UTF-7",
+			text:     "\U00020000x\U0010fffd",
+			encoded:  "+2EDcAA-x+2//f/Q-",
+		},
+		{
+			encoding: "UTF-7-IMAP",
+			text:     "~peter/mail/台北/日本語",
+			encoded:  "~peter/mail/&U,BTFw-/&ZeVnLIqe-",
+		},
+		{
+			encoding: "UTF-7-IMAP",
+			text:     "Tom & Jerry\n",
+			encoded:  "Tom &- Jerry&AAo-",
+		},
+		{
+			encoding: "UTF-7-IMAP",
+			text:     "Ünï.",
+			encoded:  "&ANw-n&AO8-.",
+		},
+	}
+
+	for _, c := range cases {
+		actual := &bytes.Buffer{}
+		err := Recode(strings.NewReader(c.encoded), actual, GetDecoder(c.encoding), NewUTF8Encoder())
+		if err != nil {
+			t.Errorf("%s %q: decode error: %v", c.encoding, c.encoded, err)
+		} else if actual.String() != c.text {
+			t.Errorf("%s %q: got %q, want %q", c.encoding, c.encoded, actual.String(), c.text)
+		}
+
+		actual.Reset()
+		err = Recode(strings.NewReader(c.text), actual, NewUTF8Decoder(), GetEncoder(c.encoding))
+		if err != nil {
+			t.Errorf("%s %q: encode error: %v", c.encoding, c.text, err)
+		} else if actual.String() != c.encoded {
+			t.Errorf("%s %q: got %q, want %q", c.encoding, c.text, actual.String(), c.encoded)
+		}
+	}
+}
+
+func TestUTF7Decode(t *testing.T) {
+	cases := []struct {
+		encoding string
+		in       string
+		expected string
+	}{
+		{
+			// The run can end at the end of the text
+			encoding: "UTF-7",
+			in:       "+AGE",
+			expected: "a",
+		},
+		{
+			// Left over bits are ignored
+			encoding: "UTF-7",
+			in:       "+AGF-",
+			expected: "a",
+		},
+		{
+			encoding: "UTF-7",
+			in:       "+AGEA-b",
+			expected: "a�b",
+		},
+		{
+			encoding: "UTF-7",
+			in:       "+!",
+			expected: "�!",
+		},
+		{
+			encoding: "UTF-7",
+			in:       "a+",
+			expected: "a�",
+		},
+		{
+			encoding: "UTF-7",
+			in:       "a\x80b+AGE\x80",
+			expected: "a�ba�",
+		},
+		{
+			// Lone surrogates
+			encoding: "UTF-7",
+			in:       "+2D0-x+3gA-",
+			expected: "�x�",
+		},
+		{
+			encoding: "UTF-7",
+			in:       "+2D0AYQ-",
+			expected: "�a",
+		},
+		{
+			encoding: "UTF-7",
+			in:       "+2D3YPd4A-",
+			expected: "�😀",
+		},
+		{
+			encoding: "UTF-7-IMAP",
+			in:       "&U,BTFw-&-+",
+			expected: "台北&+",
+		},
+		{
+			encoding: "UTF-7-IMAP",
+			in:       "a\tb",
+			expected: "a�b",
+		},
+	}
+
+	for _, c := range cases {
+		actual := &bytes.Buffer{}
+		err := Recode(strings.NewReader(c.in), actual, GetDecoder(c.encoding), NewUTF8Encoder(), WithErrorPolicy(Replace))
+		if err != nil {
+			t.Errorf("%s %q: recode error: %v", c.encoding, c.in, err)
+		} else if actual.String() != c.expected {
+			t.Errorf("%s %q: got %q, want %q", c.encoding, c.in, actual.String(), c.expected)
+		}
+	}
+
+	var de *DecodeError
+	_, err := GetDecoder("UTF-7").Decode(strings.NewReader("+2D0-"))
+	if !errors.As(err, &de) || de.Err != ErrLoneSurrogate || string(de.Bytes) != "2D0" {
+		t.Errorf("got %v, want %v", err, ErrLoneSurrogate)
+	}
+}
+
+func TestUTF7Encode(t *testing.T) {
+	for _, r := range []rune{0xd800, 0x110000} {
+		var ee *EncodeError
+		err := GetEncoder("UTF-7").Encode(&bytes.Buffer{}, r)
+		if !errors.As(err, &ee) || ee.Encoding != "UTF-7" || ee.Rune != r {
+			t.Errorf("%U: got %v", r, err)
+		}
+	}
+
+	e := GetEncoder("UTF-7")
+	actual := &bytes.Buffer{}
+	e.Encode(actual, 'é')
+	if actual.String() != "+AO" {
+		t.Errorf("got %q before flushing", actual.String())
+	}
+	e.(Flusher).Flush(actual)
+	if actual.String() != "+AOk-" {
+		t.Errorf("got %q", actual.String())
+	}
+
+	// After flushing a new run is started.
+	e.Encode(actual, 'é')
+	e.(Flusher).Flush(actual)
+	if actual.String() != "+AOk-+AOk-" {
+		t.Errorf("got %q after flushing", actual.String())
+	}
+}
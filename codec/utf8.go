@@ -1,36 +1,70 @@
 package codec
 
 import (
-	"errors"
+	"bytes"
 	"io"
 )
 
 func init() {
-	registerCodec("UTF-8", NewUTF8Decoder, NewUTF8Encoder)
+	registerCodec(Codec{
+		Name: "UTF-8",
+		Aliases: []string{
+			// IANA
+			"csUTF8",
+			// WHATWG
+			"unicode-1-1-utf-8", "unicode11utf8", "unicode20utf8", "x-unicode20utf8",
+			// Windows code page
+			"cp65001",
+		},
+		NewDecoder: NewUTF8Decoder,
+		NewEncoder: NewUTF8Encoder,
+		Info:       Info{MaxRune: 0x10ffff, MinBytes: 1, MaxBytes: 4, ReadsBOM: true},
+	})
 }
 
-var _ Decoder = &UTF8Decoder{}
+var _ BulkDecoder = &UTF8Decoder{}
 
 // UTF8Decoder implements Decoder for UTF-8.
 type UTF8Decoder struct {
+	input
+	bom     BOMPolicy
+	started bool
 }
 
 // NewUTF8Decoder creates a new instance of UTF8Decoder
-func NewUTF8Decoder() Decoder {
-	return &UTF8Decoder{}
+//
+// UTF-8 has no byte order, but a byte order mark (EF BB BF) is sometimes used
+// to mark text as UTF-8. It's removed unless opts say otherwise.
+func NewUTF8Decoder(opts ...Options) Decoder {
+	return &UTF8Decoder{
+		bom: mergeOptions(opts).BOM,
+	}
 }
 
 // Decode satifies the Decoder interface for UTF-8.
 func (d *UTF8Decoder) Decode(r io.Reader) (rune, error) {
+	if d.started {
+		return d.decode(r)
+	}
+	d.started = true
+
+	char, err := d.decode(r)
+	if err == nil && char == bomRune && (d.bom == BOMAuto || d.bom == BOMAlways) {
+		return d.decode(r)
+	}
+	return char, err
+}
+
+func (d *UTF8Decoder) decode(r io.Reader) (rune, error) {
 	buf := make([]byte, 1, 4)
-	_, err := io.ReadFull(r, buf)
+	_, err := d.readFull(r, buf)
 	if err != nil {
 		return 0, err
 	}
 
 	l := utf8Len(buf[0])
 	if l <= 0 || l > 4 {
-		return 0, errors.New("invalid character")
+		return 0, &DecodeError{Encoding: "UTF-8", Bytes: buf, Err: ErrInvalidSequence}
 	}
 	if l == 1 {
 		return rune(buf[0]), nil
@@ -41,16 +75,24 @@ func (d *UTF8Decoder) Decode(r io.Reader) (rune, error) {
 	// 4 byte character, use the low 3 bits.
 	char := rune(buf[0]) & (0x7f >> l)
 
-	buf = buf[:l]
-	_, err = io.ReadFull(r, buf[1:])
-	if err != nil {
-		return 0, err
-	}
+	// Read the continuation bytes one at a time, so that if one of them
+	// is invalid it can be pushed back as the start of the next character.
+	for i := 1; i < l; i++ {
+		buf = buf[:i+1]
+		_, err = d.readFull(r, buf[i:])
+		if err != nil {
+			if err == io.EOF {
+				return 0, &DecodeError{Encoding: "UTF-8", Bytes: buf[:i], Err: ErrTruncated}
+			}
+			return 0, err
+		}
+
+		b := buf[i]
 
-	for _, b := range buf[1:] {
 		// Make sure the two high bits are 1 and 0 respectively.
 		if b>>6 != 2 {
-			return 0, errors.New("invalid character")
+			d.unread(buf[i:])
+			return 0, &DecodeError{Encoding: "UTF-8", Bytes: buf[:i], Err: ErrInvalidSequence}
 		}
 
 		// There are 6 bits of the code point in this byte. So shift
@@ -61,9 +103,110 @@ func (d *UTF8Decoder) Decode(r io.Reader) (rune, error) {
 		char |= rune(b & (0xff >> 2))
 	}
 
+	// Every code point has exactly one encoding, the shortest one.
+	if char < utf8Min[l] {
+		return 0, &DecodeError{Encoding: "UTF-8", Bytes: buf, Err: ErrInvalidSequence}
+	}
+
+	if err := checkRune(char); err != nil {
+		return 0, &DecodeError{Encoding: "UTF-8", Bytes: buf, Err: err}
+	}
+
 	return char, nil
 }
 
+// utf8BOM is the UTF-8 encoding of U+FEFF.
+var utf8BOM = []byte{0xef, 0xbb, 0xbf}
+
+// BulkDecoder satisfies the BulkDecoder interface for UTF-8.
+func (d *UTF8Decoder) BulkDecoder() BulkDecoder {
+	return d
+}
+
+// DecodeBytes satisfies the BulkDecoder interface for UTF-8.
+func (d *UTF8Decoder) DecodeBytes(dst []rune, src []byte, atEOF bool) (nDst, nSrc int, err error) {
+	if !d.started {
+		if len(src) < len(utf8BOM) && !atEOF && bytes.HasPrefix(utf8BOM, src) {
+			return 0, 0, ErrShortSrc
+		}
+		d.started = true
+
+		if (d.bom == BOMAuto || d.bom == BOMAlways) && bytes.HasPrefix(src, utf8BOM) {
+			nSrc = len(utf8BOM)
+		}
+	}
+
+	for nSrc < len(src) {
+		if nDst == len(dst) {
+			return nDst, nSrc, ErrShortDst
+		}
+
+		b := src[nSrc]
+		if b < 0x80 {
+			dst[nDst] = rune(b)
+			nDst++
+			nSrc++
+			continue
+		}
+
+		char, n, err := utf8Char(src[nSrc:], atEOF)
+		if err == nil {
+			err = checkRune(char)
+		}
+		if err == ErrShortSrc {
+			return nDst, nSrc, err
+		}
+		if err != nil {
+			return nDst, nSrc, &DecodeError{Encoding: "UTF-8", Bytes: copyBytes(src[nSrc : nSrc+n]), Err: err}
+		}
+
+		dst[nDst] = char
+		nDst++
+		nSrc += n
+	}
+	return nDst, nSrc, nil
+}
+
+// utf8Char decodes the UTF-8 sequence at the start of src, which isn't empty,
+// and returns the code point and its length. It rejects overlong sequences but
+// not surrogates or code points above U+10FFFF, which the caller has to check.
+// Otherwise it works like a decodeFunc.
+func utf8Char(src []byte, atEOF bool) (rune, int, error) {
+	b := src[0]
+	l := utf8Len(b)
+	switch l {
+	case 0:
+		return 0, 1, ErrInvalidSequence
+	case 1:
+		return rune(b), 1, nil
+	}
+
+	char := rune(b) & (0x7f >> l)
+	for i := 1; i < l; i++ {
+		if i == len(src) {
+			if !atEOF {
+				return 0, 0, ErrShortSrc
+			}
+			return 0, i, ErrTruncated
+		}
+
+		c := src[i]
+		if c>>6 != 2 {
+			return 0, i, ErrInvalidSequence
+		}
+		char = char<<6 | rune(c&0x3f)
+	}
+
+	if char < utf8Min[l] {
+		return 0, l, ErrInvalidSequence
+	}
+	return char, l, nil
+}
+
+// utf8Min holds the smallest code point that needs each length of UTF-8
+// sequence. Anything smaller is an overlong encoding.
+var utf8Min = [5]rune{0, 0, 0x80, 0x800, 0x10000}
+
 // utf8Len returns the number total bytes used for the UTF-8 character based on the information in the first byte.
 func utf8Len(b byte) int {
 	// Under 128 is ASCII
@@ -71,11 +214,15 @@ func utf8Len(b byte) int {
 		return 1
 	}
 
-	// 0b10xxxxxx is invalid
+	// 0b10xxxxxx is a continuation byte, which can't start a character.
+	if b < 0xc0 {
+		return 0
+	}
+
 	// 0b110xxxxx is 2 bytes
 	// 0b1110xxxx is 3 bytes
 	// 0b11110xxx is 4 bytes
-	for i := 1; i <= 4; i++ {
+	for i := 2; i <= 4; i++ {
 		var m byte = 0x80 >> i
 		if b&m == 0 {
 			return i
@@ -85,47 +232,103 @@ func utf8Len(b byte) int {
 	return 0
 }
 
-var _ Encoder = &UTF8Encoder{}
+var _ BulkEncoder = &UTF8Encoder{}
 
 // UTF8Encoder implements Encoder for UTF-8.
 type UTF8Encoder struct {
+	bom bomEncoder
 }
 
 // NewUTF8Encoder creates a new instance of UTF8Encoder
-func NewUTF8Encoder() Encoder {
-	return &UTF8Encoder{}
+//
+// It doesn't write a byte order mark unless opts ask for one.
+func NewUTF8Encoder(opts ...Options) Encoder {
+	return &UTF8Encoder{
+		bom: newBOMEncoder(mergeOptions(opts).BOM, false),
+	}
 }
 
 // Encode satifies the Decoder interface for UTF-8.
-func (*UTF8Encoder) Encode(w io.Writer, r rune) error {
-	buf := make([]byte, 0, 4)
+func (e *UTF8Encoder) Encode(w io.Writer, r rune) error {
+	if err := checkRune(r); err != nil {
+		return &EncodeError{Encoding: "UTF-8", Rune: r, Err: err}
+	}
+
+	buf := make([]byte, 7)
+	n := e.encodeChar(buf, r)
+	_, err := w.Write(buf[:n])
+	return err
+}
+
+// BulkEncoder satisfies the BulkEncoder interface for UTF-8.
+func (e *UTF8Encoder) BulkEncoder() BulkEncoder {
+	return e
+}
+
+// EncodeRunes satisfies the BulkEncoder interface for UTF-8.
+func (e *UTF8Encoder) EncodeRunes(dst []byte, src []rune) (nDst, nSrc int, err error) {
+	for ; nSrc < len(src); nSrc++ {
+		r := src[nSrc]
+		if r >= 0 && r < 0x80 && e.bom.started {
+			if nDst == len(dst) {
+				return nDst, nSrc, ErrShortDst
+			}
+			dst[nDst] = byte(r)
+			nDst++
+			continue
+		}
+
+		if err := checkRune(r); err != nil {
+			return nDst, nSrc, &EncodeError{Encoding: "UTF-8", Rune: r, Err: err}
+		}
+		if len(dst)-nDst < 7 {
+			return nDst, nSrc, ErrShortDst
+		}
+		nDst += e.encodeChar(dst[nDst:], r)
+	}
+	return nDst, nSrc, nil
+}
+
+// encodeChar writes r to buf, preceded by a byte order mark if one's needed,
+// and returns the number of bytes written. buf must have room for 7 bytes.
+func (e *UTF8Encoder) encodeChar(buf []byte, r rune) int {
+	var n int
+	writeBOM, skip := e.bom.start(r)
+	if writeBOM {
+		n += copy(buf, utf8BOM)
+	}
+	if skip {
+		return n
+	}
+
+	return n + putUTF8(buf[n:], r)
+}
+
+// putUTF8 writes the UTF-8 sequence for r to buf, which must have room for 4
+// bytes, and returns its length. It doesn't check r, so surrogates are written
+// as three byte sequences.
+func putUTF8(buf []byte, r rune) int {
 	switch {
-	case r < 0:
-		return errors.New("invalid character")
 	case r < 0x80:
-		buf = append(buf, byte(r))
+		buf[0] = byte(r)
+		return 1
 	case r < 0x800:
 		// 11 bits available, 5 bits in the first byte
-		buf = buf[:2]
 		buf[0] = 0x80 | 0x40 | byte(r>>6)
 		buf[1] = 0x80 | byte(r&0x3f)
-	case r < 0xffff:
+		return 2
+	case r < 0x10000:
 		// 16 bits available, 4 in the first byte
-		buf = buf[:3]
 		buf[0] = 0x80 | 0x40 | 0x20 | byte(r>>12)
 		buf[1] = 0x80 | byte(r>>6&0x3f)
 		buf[2] = 0x80 | byte(r&0x3f)
-	case r < 0x10ffff:
+		return 3
+	default:
 		// 21 bits available, 3 in the first byte
-		buf = buf[:4]
 		buf[0] = 0x80 | 0x40 | 0x20 | 0x10 | byte(r>>18)
 		buf[1] = 0x80 | byte(r>>12&0x3f)
 		buf[2] = 0x80 | byte(r>>6&0x3f)
 		buf[3] = 0x80 | byte(r&0x3f)
-	default:
-		return errors.New("invalid character")
+		return 4
 	}
-
-	_, err := w.Write(buf)
-	return err
 }
@@ -26,6 +26,11 @@ func TestUTF8Decode(t *testing.T) {
 			buf: []byte{0xf0, 0x9f, 0x8c, 0x8e, 0x0a},
 			xp:  '🌎',
 		},
+		{
+			// Not a surrogate, even though the low bits look like one
+			buf: []byte{0xf0, 0x9d, 0xa0, 0x80},
+			xp:  0x1d800,
+		},
 	}
 
 	decoder := GetDecoder("UTF-8")
@@ -65,6 +70,10 @@ func TestUTF8Encoder(t *testing.T) {
 			r:        '🌎',
 			expected: []byte{0xf0, 0x9f, 0x8c, 0x8e},
 		},
+		{
+			r:        0x1d800,
+			expected: []byte{0xf0, 0x9d, 0xa0, 0x80},
+		},
 	}
 
 	encoder := GetEncoder("UTF-8")
@@ -82,3 +91,49 @@ func TestUTF8Encoder(t *testing.T) {
 		}
 	}
 }
+
+func TestUTF8DecodeInvalid(t *testing.T) {
+	cases := []struct {
+		in       []byte
+		expected string
+	}{
+		{
+			// Continuation byte without a lead byte
+			in:       []byte{'a', 0x82, 'b'},
+			expected: "a�b",
+		},
+		{
+			// Lead byte followed by ASCII
+			in:       []byte{0xe2, 'x', 'y'},
+			expected: "�xy",
+		},
+		{
+			// Two continuation bytes missing before a valid character
+			in:       []byte{0xf0, 0x9f, 0xe2, 0x82, 0xa1},
+			expected: "�₡",
+		},
+		{
+			// Overlong encoding of '/'
+			in:       []byte{0xc0, 0xaf, '/'},
+			expected: "�/",
+		},
+		{
+			// Truncated at the end
+			in:       []byte{'z', 0xf0, 0x9f, 0x8c},
+			expected: "z�",
+		},
+	}
+
+	for _, c := range cases {
+		actual := &bytes.Buffer{}
+		err := Recode(bytes.NewReader(c.in), actual, NewUTF8Decoder(), NewUTF8Encoder(), WithErrorPolicy(Replace))
+		if err != nil {
+			t.Errorf("recode error: %v", err)
+			continue
+		}
+
+		if actual.String() != c.expected {
+			t.Errorf("got %q, want %q", actual.String(), c.expected)
+		}
+	}
+}
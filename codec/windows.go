@@ -0,0 +1,90 @@
+package codec
+
+//go:generate go run gen_charmap.go -o windows_tables.go -overrides mappings/OVERRIDES.TXT windows874=mappings/VENDORS/MICSFT/WINDOWS/CP874.TXT windows1250=mappings/VENDORS/MICSFT/WINDOWS/CP1250.TXT windows1251=mappings/VENDORS/MICSFT/WINDOWS/CP1251.TXT windows1252=mappings/VENDORS/MICSFT/WINDOWS/CP1252.TXT windows1253=mappings/VENDORS/MICSFT/WINDOWS/CP1253.TXT windows1254=mappings/VENDORS/MICSFT/WINDOWS/CP1254.TXT windows1255=mappings/VENDORS/MICSFT/WINDOWS/CP1255.TXT windows1256=mappings/VENDORS/MICSFT/WINDOWS/CP1256.TXT windows1257=mappings/VENDORS/MICSFT/WINDOWS/CP1257.TXT windows1258=mappings/VENDORS/MICSFT/WINDOWS/CP1258.TXT
+
+// The Windows code pages are the single-byte "ANSI" encodings used by Windows.
+// Most extend the matching ISO-8859 encoding with punctuation in 0x80-0x9F.
+// Bytes that the code page doesn't define are invalid, rather than being
+// mapped to C1 control codes as Windows does.
+func init() {
+	registerCharmap("windows-874", []string{
+		// IANA
+		"cswindows874",
+		// WHATWG
+		"dos-874",
+		// Windows code page
+		"cp874",
+	}, &windows874)
+	registerCharmap("windows-1250", []string{
+		// IANA
+		"cswindows1250",
+		// WHATWG
+		"x-cp1250",
+		// Windows code page
+		"cp1250",
+	}, &windows1250)
+	registerCharmap("windows-1251", []string{
+		// IANA
+		"cswindows1251",
+		// WHATWG
+		"x-cp1251",
+		// Windows code page
+		"cp1251",
+	}, &windows1251)
+	registerCharmap("windows-1252", []string{
+		// IANA
+		"cswindows1252",
+		// WHATWG
+		"x-cp1252",
+		// Windows code page
+		"cp1252",
+	}, &windows1252)
+	registerCharmap("windows-1253", []string{
+		// IANA
+		"cswindows1253",
+		// WHATWG
+		"x-cp1253",
+		// Windows code page
+		"cp1253",
+	}, &windows1253)
+	registerCharmap("windows-1254", []string{
+		// IANA
+		"cswindows1254",
+		// WHATWG
+		"x-cp1254",
+		// Windows code page
+		"cp1254",
+	}, &windows1254)
+	registerCharmap("windows-1255", []string{
+		// IANA
+		"cswindows1255",
+		// WHATWG
+		"x-cp1255",
+		// Windows code page
+		"cp1255",
+	}, &windows1255)
+	registerCharmap("windows-1256", []string{
+		// IANA
+		"cswindows1256",
+		// WHATWG
+		"x-cp1256",
+		// Windows code page
+		"cp1256",
+	}, &windows1256)
+	registerCharmap("windows-1257", []string{
+		// IANA
+		"cswindows1257",
+		// WHATWG
+		"x-cp1257",
+		// Windows code page
+		"cp1257",
+	}, &windows1257)
+	registerCharmap("windows-1258", []string{
+		// IANA
+		"cswindows1258",
+		// WHATWG
+		"x-cp1258",
+		// Windows code page
+		"cp1258",
+	}, &windows1258)
+}
@@ -0,0 +1,202 @@
+package codec
+
+import "io"
+
+// WTF-8 is UTF-8 that can also hold lone surrogates, each as a three byte
+// sequence, so that anything in WTF-16 (UTF-16 that may have unpaired
+// surrogates, like Windows file names and JavaScript strings) can be stored in
+// it and converted back unchanged. A surrogate pair is always written as the
+// four byte sequence of the character, never as two surrogates, so the
+// encoder holds back a high surrogate until it sees the next character.
+//
+// The decoder doesn't reject a high surrogate followed by a low surrogate,
+// which isn't well-formed WTF-8. It returns the two surrogates.
+//
+// WTF-16, WTF-16LE and WTF-16BE are UTF-16 with Options.LoneSurrogates set.
+// They also default to BOMPreserve, so that a byte order mark is only written
+// if the input had one and the text comes back unchanged.
+func init() {
+	registerCodec(Codec{
+		Name: "WTF-8",
+		NewDecoder: func(...Options) Decoder {
+			return &WTF8Decoder{}
+		},
+		NewEncoder: func(...Options) Encoder {
+			return &WTF8Encoder{}
+		},
+		Info: Info{MaxRune: 0x10ffff, MinBytes: 1, MaxBytes: 4},
+	})
+
+	registerCodec(Codec{
+		Name:       "WTF-16",
+		NewDecoder: wtf16Decoder("WTF-16", NewUTF16Decoder),
+		NewEncoder: wtf16Encoder("WTF-16", NewUTF16Encoder),
+		Info:       Info{MaxRune: 0x10ffff, MinBytes: 2, MaxBytes: 4},
+	})
+	registerCodec(Codec{
+		Name:       "WTF-16BE",
+		NewDecoder: wtf16Decoder("WTF-16BE", NewUTF16BEDecoder),
+		NewEncoder: wtf16Encoder("WTF-16BE", NewUTF16BEEncoder),
+		Info:       Info{MaxRune: 0x10ffff, MinBytes: 2, MaxBytes: 4},
+	})
+	registerCodec(Codec{
+		Name:       "WTF-16LE",
+		NewDecoder: wtf16Decoder("WTF-16LE", NewUTF16LEDecoder),
+		NewEncoder: wtf16Encoder("WTF-16LE", NewUTF16LEEncoder),
+		Info:       Info{MaxRune: 0x10ffff, MinBytes: 2, MaxBytes: 4},
+	})
+}
+
+// wtf16Options are the defaults for the WTF-16 encodings.
+var wtf16Options = Options{BOM: BOMPreserve, LoneSurrogates: true}
+
+// wtf16Decoder wraps a UTF-16 decoder constructor to use wtf16Options and
+// report errors under name.
+func wtf16Decoder(name string, newDecoder func(...Options) Decoder) func(...Options) Decoder {
+	return func(opts ...Options) Decoder {
+		d := newDecoder(append([]Options{wtf16Options}, opts...)...).(*UTF16Decoder)
+		d.name = name
+		return d
+	}
+}
+
+// wtf16Encoder wraps a UTF-16 encoder constructor to use wtf16Options and
+// report errors under name.
+func wtf16Encoder(name string, newEncoder func(...Options) Encoder) func(...Options) Encoder {
+	return func(opts ...Options) Encoder {
+		e := newEncoder(append([]Options{wtf16Options}, opts...)...).(*UTF16Encoder)
+		e.name = name
+		return e
+	}
+}
+
+var _ BulkDecoder = &WTF8Decoder{}
+
+// WTF8Decoder reads WTF-8. Use GetDecoder to get one.
+type WTF8Decoder struct {
+	in input
+}
+
+// Decode satisfies the Decoder interface for WTF-8.
+func (d *WTF8Decoder) Decode(r io.Reader) (rune, error) {
+	return d.in.decodeRune(r, "WTF-8", decodeWTF8)
+}
+
+// BulkDecoder satisfies the BulkDecoder interface for WTF-8.
+func (d *WTF8Decoder) BulkDecoder() BulkDecoder {
+	return d
+}
+
+// DecodeBytes satisfies the BulkDecoder interface for WTF-8.
+func (d *WTF8Decoder) DecodeBytes(dst []rune, src []byte, atEOF bool) (nDst, nSrc int, err error) {
+	return decodeBytes(dst, src, atEOF, "WTF-8", decodeWTF8)
+}
+
+// Buffered returns the number of bytes that have been read but not decoded.
+func (d *WTF8Decoder) Buffered() int {
+	return d.in.Buffered()
+}
+
+// decodeWTF8 is the decodeFunc for WTF-8.
+func decodeWTF8(src []byte, atEOF bool) (rune, int, error) {
+	char, n, err := utf8Char(src, atEOF)
+	if err == nil && char > 0x10ffff {
+		err = ErrOutOfRange
+	}
+	return char, n, err
+}
+
+var (
+	_ BulkEncoder = &WTF8Encoder{}
+	_ Flusher     = &WTF8Encoder{}
+)
+
+// WTF8Encoder writes WTF-8. Use GetEncoder to get one.
+type WTF8Encoder struct {
+	// high is a high surrogate that's being held back to see whether a
+	// low surrogate follows, or 0.
+	high rune
+}
+
+// wtf8MaxBytes is the most a WTF8Encoder writes at once: a high surrogate
+// that was held back followed by a four byte character.
+const wtf8MaxBytes = 7
+
+// Encode satisfies the Encoder interface for WTF-8.
+func (e *WTF8Encoder) Encode(w io.Writer, r rune) error {
+	buf := make([]byte, wtf8MaxBytes)
+	n, err := e.encode(buf, r)
+	if n > 0 {
+		if _, werr := w.Write(buf[:n]); werr != nil {
+			return werr
+		}
+	}
+	return err
+}
+
+// BulkEncoder satisfies the BulkEncoder interface for WTF-8.
+func (e *WTF8Encoder) BulkEncoder() BulkEncoder {
+	return e
+}
+
+// EncodeRunes satisfies the BulkEncoder interface for WTF-8.
+func (e *WTF8Encoder) EncodeRunes(dst []byte, src []rune) (nDst, nSrc int, err error) {
+	for ; nSrc < len(src); nSrc++ {
+		r := src[nSrc]
+		if r >= 0 && r < 0x80 && e.high == 0 {
+			if nDst == len(dst) {
+				return nDst, nSrc, ErrShortDst
+			}
+			dst[nDst] = byte(r)
+			nDst++
+			continue
+		}
+
+		if len(dst)-nDst < wtf8MaxBytes {
+			return nDst, nSrc, ErrShortDst
+		}
+		n, err := e.encode(dst[nDst:], r)
+		nDst += n
+		if err != nil {
+			return nDst, nSrc, err
+		}
+	}
+	return nDst, nSrc, nil
+}
+
+// Flush writes a high surrogate that was held back.
+func (e *WTF8Encoder) Flush(w io.Writer) error {
+	if e.high == 0 {
+		return nil
+	}
+
+	buf := make([]byte, 3)
+	n := putUTF8(buf, e.high)
+	e.high = 0
+	_, err := w.Write(buf[:n])
+	return err
+}
+
+// encode writes r, after any high surrogate that was held back, to buf, which
+// must have room for wtf8MaxBytes. It returns the number of bytes written,
+// which may be more than 0 even if r can't be encoded.
+func (e *WTF8Encoder) encode(buf []byte, r rune) (int, error) {
+	n := 0
+	if e.high != 0 {
+		high := e.high
+		e.high = 0
+		if r >= utf16LowSurrogate && r <= 0xdfff {
+			return putUTF8(buf, 0x10000+(high&0x3ff)<<10|r&0x3ff), nil
+		}
+		n = putUTF8(buf, high)
+	}
+
+	if err := checkRune(r); err == ErrOutOfRange {
+		return n, &EncodeError{Encoding: "WTF-8", Rune: r, Err: err}
+	}
+	if r >= utf16HighSurrogate && r < utf16LowSurrogate {
+		e.high = r
+		return n, nil
+	}
+	return n + putUTF8(buf[n:], r), nil
+}
@@ -0,0 +1,211 @@
+package codec
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestWTF8(t *testing.T) {
+	cases := []struct {
+		runes   []rune
+		encoded []byte
+	}{
+		{
+			runes:   []rune("a€😀"),
+			encoded: []byte{'a', 0xe2, 0x82, 0xac, 0xf0, 0x9f, 0x98, 0x80},
+		},
+		{
+			runes:   []rune{0xd83d, 'a', 0xde00},
+			encoded: []byte{0xed, 0xa0, 0xbd, 'a', 0xed, 0xb8, 0x80},
+		},
+		{
+			// Ends with a high surrogate
+			runes:   []rune{'a', 0xdbff},
+			encoded: []byte{'a', 0xed, 0xaf, 0xbf},
+		},
+		{
+			runes:   []rune{0xd800, 0xd800, 0x10000},
+			encoded: []byte{0xed, 0xa0, 0x80, 0xed, 0xa0, 0x80, 0xf0, 0x90, 0x80, 0x80},
+		},
+	}
+
+	for _, c := range cases {
+		d := GetDecoder("WTF-8")
+		r := bytes.NewReader(c.encoded)
+		var runes []rune
+		for {
+			char, err := d.Decode(r)
+			if err != nil {
+				break
+			}
+			runes = append(runes, char)
+		}
+		if !reflect.DeepEqual(runes, c.runes) {
+			t.Errorf("% x: got %U, want %U", c.encoded, runes, c.runes)
+		}
+
+		e := GetEncoder("WTF-8")
+		actual := &bytes.Buffer{}
+		for _, char := range c.runes {
+			if err := e.Encode(actual, char); err != nil {
+				t.Errorf("%U: encode error: %v", char, err)
+			}
+		}
+		e.(Flusher).Flush(actual)
+		if !bytes.Equal(actual.Bytes(), c.encoded) {
+			t.Errorf("%U: got % x, want % x", c.runes, actual.Bytes(), c.encoded)
+		}
+	}
+}
+
+func TestWTF8Encode(t *testing.T) {
+	// A surrogate pair is joined into one character.
+	e := GetEncoder("WTF-8")
+	actual := &bytes.Buffer{}
+	e.Encode(actual, 0xd83d)
+	if actual.Len() != 0 {
+		t.Errorf("got % x before the low surrogate", actual.Bytes())
+	}
+	e.Encode(actual, 0xde00)
+	if !bytes.Equal(actual.Bytes(), []byte{0xf0, 0x9f, 0x98, 0x80}) {
+		t.Errorf("got % x, want f0 9f 98 80", actual.Bytes())
+	}
+
+	actual.Reset()
+	e.Encode(actual, 0xd840)
+	e.Encode(actual, 0xdc00)
+	if !bytes.Equal(actual.Bytes(), []byte{0xf0, 0xa0, 0x80, 0x80}) {
+		t.Errorf("got % x, want f0 a0 80 80", actual.Bytes())
+	}
+
+	// A high surrogate that was held back is written before the error.
+	actual.Reset()
+	e.Encode(actual, 0xd83d)
+	var ee *EncodeError
+	err := e.Encode(actual, 0x110000)
+	if !errors.As(err, &ee) || ee.Err != ErrOutOfRange || ee.Encoding != "WTF-8" {
+		t.Errorf("got %v, want %v", err, ErrOutOfRange)
+	}
+	if !bytes.Equal(actual.Bytes(), []byte{0xed, 0xa0, 0xbd}) {
+		t.Errorf("got % x, want ed a0 bd", actual.Bytes())
+	}
+}
+
+func TestWTF16RoundTrip(t *testing.T) {
+	cases := []struct {
+		encoding string
+		in       []byte
+	}{
+		{
+			encoding: "WTF-16LE",
+			in:       []byte{0xff, 0xfe, 'a', 0, 0x3d, 0xd8, 'b', 0, 0x00, 0xde, 0x3d, 0xd8, 0x00, 0xde, 0x3d, 0xd8},
+		},
+		{
+			encoding: "WTF-16BE",
+			in:       []byte{0xfe, 0xff, 0xdc, 0x00, 0xd8, 0x00, 0xd8, 0x00, 0xdc, 0x00},
+		},
+		{
+			// No byte order mark
+			encoding: "WTF-16LE",
+			in:       []byte{'a', 0, 0x00, 0xd8, 'b', 0, 0x00, 0xdc, 0x3d, 0xd8, 0x00, 0xde},
+		},
+		{
+			encoding: "WTF-16BE",
+			in:       []byte{0, 'a', 0xd8, 0x00, 0, 'b', 0xdc, 0x00, 0xd8, 0x3d, 0xde, 0x00},
+		},
+		{
+			// Big-endian has no surrogates that could be wrong,
+			// but only little-endian has a pair.
+			encoding: "WTF-16",
+			in:       []byte{'a', 0, 0x00, 0xd8, 'b', 0, 0x00, 0xdc, 0x3d, 0xd8, 0x00, 0xde},
+		},
+		{
+			encoding: "WTF-16",
+			in:       []byte{0xff, 0xfe, 'a', 0, 0x00, 0xd8},
+		},
+	}
+
+	for _, c := range cases {
+		for _, decoder := range []Decoder{GetDecoder(c.encoding), decoderOnly(GetDecoder(c.encoding))} {
+			wtf8 := &bytes.Buffer{}
+			err := Recode(bytes.NewReader(c.in), wtf8, decoder, GetEncoder("WTF-8"))
+			if err != nil {
+				t.Errorf("%s % x: error: %v", c.encoding, c.in, err)
+				continue
+			}
+
+			actual := &bytes.Buffer{}
+			err = Recode(wtf8, actual, GetDecoder("WTF-8"), GetEncoder(c.encoding))
+			if err != nil {
+				t.Errorf("%s % x: error: %v", c.encoding, c.in, err)
+			} else if !bytes.Equal(actual.Bytes(), c.in) {
+				t.Errorf("%s % x: got % x", c.encoding, c.in, actual.Bytes())
+			}
+		}
+	}
+
+	// UTF-16 still rejects them.
+	err := Recode(bytes.NewReader([]byte{0x3d, 0xd8, 'a', 0}), &bytes.Buffer{}, GetDecoder("UTF-16LE"), GetEncoder("WTF-8"))
+	if !errors.Is(err, ErrLoneSurrogate) {
+		t.Errorf("UTF-16LE: got %v, want %v", err, ErrLoneSurrogate)
+	}
+	err = GetEncoder("UTF-16LE").Encode(&bytes.Buffer{}, 0xd83d)
+	if !errors.Is(err, ErrLoneSurrogate) {
+		t.Errorf("UTF-16LE: got %v, want %v", err, ErrLoneSurrogate)
+	}
+
+	// Unless they're allowed.
+	d := NewUTF16LEDecoder(Options{LoneSurrogates: true})
+	char, err := d.Decode(bytes.NewReader([]byte{0x3d, 0xd8, 'a'}))
+	if char != 0xd83d || err != nil {
+		t.Errorf("LoneSurrogates: got %U, %v", char, err)
+	}
+
+	// Errors have the WTF-16 name.
+	var de *DecodeError
+	err = Recode(bytes.NewReader([]byte{'a', 0, 'b'}), &bytes.Buffer{}, GetDecoder("WTF-16LE"), GetEncoder("WTF-8"))
+	if !errors.As(err, &de) || de.Encoding != "WTF-16LE" {
+		t.Errorf("WTF-16LE: got %v, want a WTF-16LE DecodeError", err)
+	}
+	var ee *EncodeError
+	err = GetEncoder("WTF-16BE").Encode(&bytes.Buffer{}, 0x110000)
+	if !errors.As(err, &ee) || ee.Encoding != "WTF-16BE" {
+		t.Errorf("WTF-16BE: got %v, want a WTF-16BE EncodeError", err)
+	}
+}
+
+// TestLoneSurrogatesOption checks that only the encodings listed in the
+// Options documentation use LoneSurrogates.
+func TestLoneSurrogatesOption(t *testing.T) {
+	expected := []string{"UTF-16", "UTF-16BE", "UTF-16LE"}
+	allow := Options{LoneSurrogates: true}
+
+	var actual []string
+	for _, name := range Names() {
+		c := Lookup(name)
+		differs := false
+		if c.NewDecoder != nil {
+			in := []byte{0xdc, 0xdc}
+			r1, err1 := c.NewDecoder().Decode(bytes.NewReader(in))
+			r2, err2 := c.NewDecoder(allow).Decode(bytes.NewReader(in))
+			differs = r1 != r2 || (err1 == nil) != (err2 == nil)
+		}
+		if c.NewEncoder != nil {
+			err1 := c.NewEncoder().Encode(&bytes.Buffer{}, 0xdcdc)
+			err2 := c.NewEncoder(allow).Encode(&bytes.Buffer{}, 0xdcdc)
+			differs = differs || (err1 == nil) != (err2 == nil)
+		}
+		if differs {
+			actual = append(actual, name)
+		}
+	}
+
+	sort.Strings(actual)
+	if strings.Join(actual, " ") != strings.Join(expected, " ") {
+		t.Errorf("got %v, want %v", actual, expected)
+	}
+}
@@ -2,21 +2,66 @@ package main
 
 import (
 	"bufio"
+	"bytes"
 	"flag"
 	"fmt"
 	"io"
 	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
 
 	"github.com/pboyd/unirecode/codec"
 )
 
 func main() {
-	var decoderName, encoderName, output string
-	flag.StringVar(&decoderName, "d", "", "decoder name")
+	var decoderName, encoderName, output, onError string
+	var list, addBOM, stripBOM, ebcdicNL bool
+	flag.StringVar(&decoderName, "d", "", "decoder name, or auto to detect it")
 	flag.StringVar(&encoderName, "e", "", "encoder name")
 	flag.StringVar(&output, "o", "", "output file name")
+	flag.StringVar(&onError, "on-error", "strict", "how to handle invalid characters: strict, replace, skip, escape or xml")
+	flag.BoolVar(&list, "l", false, "list encodings")
+	flag.BoolVar(&addBOM, "add-bom", false, "always write a byte order mark")
+	flag.BoolVar(&stripBOM, "strip-bom", false, "never write a byte order mark")
+	flag.BoolVar(&ebcdicNL, "ebcdic-nl", false, "EBCDIC lines end with NL (0x15) rather than LF (0x25)")
 	flag.Parse()
 
+	if list || (flag.NArg() == 1 && flag.Arg(0) == "list" && decoderName == "" && encoderName == "") {
+		listCodecs(os.Stdout)
+		return
+	}
+
+	policy, err := codec.ParseErrorPolicy(onError)
+	if err != nil {
+		fmt.Printf("%s: %v\n", os.Args[0], err)
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	if addBOM && stripBOM {
+		fmt.Printf("%s: -add-bom and -strip-bom can't be used together\n", os.Args[0])
+		os.Exit(1)
+	}
+
+	var decoderOpts, encoderOpts codec.Options
+	if ebcdicNL {
+		decoderOpts.Newline = codec.EBCDICNextLine
+		encoderOpts.Newline = codec.EBCDICNextLine
+	}
+
+	// The encoder option covers encoders that write their own byte order
+	// mark, the Recode option covers the rest.
+	recodeOpts := []codec.RecodeOption{codec.WithErrorPolicy(policy)}
+	if addBOM {
+		encoderOpts.BOM = codec.BOMAlways
+		recodeOpts = append(recodeOpts, codec.WithBOMPolicy(codec.BOMAlways))
+	}
+	if stripBOM {
+		encoderOpts.BOM = codec.BOMNever
+		recodeOpts = append(recodeOpts, codec.WithBOMPolicy(codec.BOMNever))
+	}
+
 	if decoderName == "" || encoderName == "" {
 		if decoderName == "" {
 			fmt.Printf("%s: no decoder\n", os.Args[0])
@@ -30,10 +75,15 @@ func main() {
 		os.Exit(1)
 	}
 
-	decoder := codec.GetDecoder(decoderName)
-	encoder := codec.GetEncoder(encoderName)
-	if decoder == nil || encoder == nil {
-		if decoder == nil {
+	auto := strings.EqualFold(decoderName, "auto")
+
+	var decoder codec.Decoder
+	if !auto {
+		decoder = codec.GetDecoder(decoderName, decoderOpts)
+	}
+	encoder := codec.GetEncoder(encoderName, encoderOpts)
+	if (decoder == nil && !auto) || encoder == nil {
+		if decoder == nil && !auto {
 			fmt.Printf("%s: no decoder named %s\n", os.Args[0], decoderName)
 		}
 		if encoder == nil {
@@ -64,19 +114,105 @@ func main() {
 		defer outFH.Close()
 	}
 
-	br := bufio.NewReader(inFH)
+	var in io.Reader = inFH
+	if auto {
+		in, decoder, err = detect(inFH, decoderOpts)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", os.Args[0], err)
+			os.Exit(1)
+		}
+	}
+
+	br := bufio.NewReader(in)
 	bw := bufio.NewWriter(outFH)
-	defer bw.Flush()
 
-	for {
-		char, err := decoder.Decode(br)
-		if err != nil {
-			if err != io.EOF {
-				fmt.Fprintf(os.Stderr, "error decoding character: %v", err)
-			}
-			break
+	err = codec.Recode(br, bw, decoder, encoder, recodeOpts...)
+	bw.Flush()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", os.Args[0], err)
+		os.Exit(1)
+	}
+}
+
+// detect guesses the encoding of r and returns a decoder for it, created with
+// opts. Detection consumes some of r, so it also returns a reader with the full
+// input.
+func detect(r io.Reader, opts codec.Options) (io.Reader, codec.Decoder, error) {
+	sample := &bytes.Buffer{}
+	name, confidence, err := codec.Detect(io.TeeReader(r, sample))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if confidence < 0.5 {
+		fmt.Fprintf(os.Stderr, "%s: guessing input is %s (confidence %.2f)\n", os.Args[0], name, confidence)
+	}
+
+	return io.MultiReader(sample, r), codec.GetDecoder(name, opts), nil
+}
+
+// listCodecs writes a table describing every registered codec to w.
+func listCodecs(w io.Writer) {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	defer tw.Flush()
+
+	fmt.Fprintln(tw, "NAME\tDECODE\tENCODE\tREPERTOIRE\tBYTES\tBOM\tALIASES")
+	for _, name := range codec.Names() {
+		c := codec.Lookup(name)
+		if c == nil {
+			continue
 		}
 
-		encoder.Encode(bw, char)
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			c.Name,
+			yesNo(c.NewDecoder != nil),
+			yesNo(c.NewEncoder != nil),
+			repertoire(c.Info),
+			byteRange(c.Info),
+			bomUsage(c.Info),
+			strings.Join(c.Aliases, ", "),
+		)
+	}
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
+
+func repertoire(info codec.Info) string {
+	switch {
+	case info.Repertoire != "":
+		return info.Repertoire
+	case info.MaxRune > 0:
+		return fmt.Sprintf("U+0000-%U", info.MaxRune)
+	default:
+		return "?"
+	}
+}
+
+func byteRange(info codec.Info) string {
+	switch {
+	case info.MaxBytes == 0:
+		return "?"
+	case info.MinBytes == info.MaxBytes:
+		return strconv.Itoa(info.MinBytes)
+	default:
+		return fmt.Sprintf("%d-%d", info.MinBytes, info.MaxBytes)
+	}
+}
+
+func bomUsage(info codec.Info) string {
+	switch {
+	case info.ReadsBOM && info.WritesBOM:
+		return "read, write"
+	case info.ReadsBOM:
+		return "read"
+	case info.WritesBOM:
+		return "write"
+	default:
+		return "-"
 	}
 }
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"testing"
+)
+
+// TestMain runs the command instead of the tests when the test binary is
+// started by runCommand.
+func TestMain(m *testing.M) {
+	if os.Getenv("UNIRECODE_TEST_MAIN") == "1" {
+		os.Args = append([]string{"ur"}, os.Args[1:]...)
+		main()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+func runCommand(t *testing.T, input []byte, args ...string) []byte {
+	t.Helper()
+
+	cmd := exec.Command(os.Args[0], args...)
+	cmd.Env = append(os.Environ(), "UNIRECODE_TEST_MAIN=1")
+	cmd.Stdin = bytes.NewReader(input)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("ur %v: %v: %s", args, err, stderr.Bytes())
+	}
+	return out
+}
+
+func TestBOMFlags(t *testing.T) {
+	cases := []struct {
+		name     string
+		args     []string
+		input    []byte
+		expected []byte
+	}{
+		{
+			name:     "add-bom GB18030",
+			args:     []string{"-d", "UTF-8", "-e", "GB18030", "--add-bom"},
+			input:    []byte("ab"),
+			expected: []byte{0x84, 0x31, 0x95, 0x33, 'a', 'b'},
+		},
+		{
+			name:     "add-bom UTF-16BE",
+			args:     []string{"-d", "UTF-8", "-e", "UTF-16BE", "--add-bom"},
+			input:    []byte("ab"),
+			expected: []byte{0xfe, 0xff, 0x00, 'a', 0x00, 'b'},
+		},
+		{
+			// The decoder removes the first byte order mark, the
+			// second is left in the text.
+			name:     "strip-bom GB18030",
+			args:     []string{"-d", "UTF-16LE", "-e", "GB18030", "--strip-bom"},
+			input:    []byte{0xff, 0xfe, 0xff, 0xfe, 'a', 0x00},
+			expected: []byte{'a'},
+		},
+		{
+			name:     "strip-bom UTF-8",
+			args:     []string{"-d", "UTF-8", "-e", "UTF-8", "--strip-bom"},
+			input:    []byte("\ufeffab"),
+			expected: []byte("ab"),
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			actual := runCommand(t, c.input, c.args...)
+			if !bytes.Equal(actual, c.expected) {
+				t.Errorf("got % x, want % x", actual, c.expected)
+			}
+		})
+	}
+}
+
+func TestDetect(t *testing.T) {
+	// More than codec.Detect reads, so the rest has to follow what it
+	// looked at.
+	input := bytes.Repeat([]byte("h\xe9llo w\xf6rld\n"), 10000)
+	expected := bytes.Repeat([]byte("héllo wörld\n"), 10000)
+
+	actual := runCommand(t, input, "-d", "auto", "-e", "UTF-8")
+	if !bytes.Equal(actual, expected) {
+		t.Errorf("got %d bytes, want %d", len(actual), len(expected))
+	}
+}